@@ -0,0 +1,102 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha2
+
+import (
+	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// ApplicationSpec defines the desired state of Application, it's the services of an Appfile
+type ApplicationSpec struct {
+	// Services are the services of the Appfile keyed by service name, each service has the same
+	// format as in Appfile, e.g. type, image and traits.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Services map[string]runtime.RawExtension `json:"services"`
+}
+
+// ApplicationPhase is a label for the condition of an Application at the current time
+type ApplicationPhase string
+
+const (
+	// ApplicationRendering means the Application is being rendered into Components and AppConfig
+	ApplicationRendering ApplicationPhase = "rendering"
+	// ApplicationRunning means the Components and AppConfig of the Application are applied
+	ApplicationRunning ApplicationPhase = "running"
+	// ApplicationFailed means the Application can't be rendered or applied, see conditions for the reason
+	ApplicationFailed ApplicationPhase = "failed"
+)
+
+// ServiceStatus is the observed state of a service of Application
+type ServiceStatus struct {
+	// Name of the service, it's also the name of the Component
+	Name string `json:"name"`
+
+	// Workload is the workload created for the service
+	Workload *runtimev1alpha1.TypedReference `json:"workload,omitempty"`
+
+	// Traits are the traits attached to the workload of the service
+	Traits []runtimev1alpha1.TypedReference `json:"traits,omitempty"`
+
+	// HealthStatus is the health of the workload reported by the HealthScope, e.g. HEALTHY, UNHEALTHY and UNKNOWN
+	HealthStatus string `json:"healthStatus,omitempty"`
+
+	// Message describes the status of the service
+	Message string `json:"message,omitempty"`
+}
+
+// ApplicationStatus defines the observed state of Application
+type ApplicationStatus struct {
+	runtimev1alpha1.ConditionedStatus `json:",inline"`
+
+	// Phase of the Application
+	Phase ApplicationPhase `json:"phase,omitempty"`
+
+	// Services are the status of each service
+	Services []ServiceStatus `json:"services,omitempty"`
+
+	// ObservedGeneration is the generation of the Application rendered last time
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+// Application is the Schema for the Application API, vela-core renders it like an Appfile
+// into Components, ApplicationConfiguration and scopes by definitions in the cluster.
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:categories={oam},shortName=app
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="PHASE",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="AGE",type=date,JSONPath=".metadata.creationTimestamp"
+type Application struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ApplicationSpec   `json:"spec,omitempty"`
+	Status ApplicationStatus `json:"status,omitempty"`
+}
+
+// ApplicationList contains a list of Application
+// +kubebuilder:object:root=true
+type ApplicationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Application `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Application{}, &ApplicationList{})
+}
@@ -0,0 +1,104 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha2
+
+import (
+	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GitSourceSpec defines the git repository which Appfiles are synced from
+type GitSourceSpec struct {
+	// Repository is the URL of the git repository, e.g. https://github.com/foo/bar.git, file:///path/to/repo.git
+	// or a local path.
+	Repository string `json:"repository"`
+
+	// Branch to sync, default to master
+	// +optional
+	Branch string `json:"branch,omitempty"`
+
+	// Path of the dir with Appfiles in the repository, default to the root dir.
+	// Every YAML or JSON file in the dir is an Appfile.
+	// +optional
+	Path string `json:"path,omitempty"`
+
+	// Env is the name of the env which Appfiles are applied into, the env must be in the namespace of the GitSource.
+	// Appfiles are applied into the namespace of the GitSource if it's not specified
+	// +optional
+	Env string `json:"env,omitempty"`
+
+	// Interval of polling the repository for new commits, default to 1m
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+}
+
+// SyncedApplication is the Application synced from an Appfile in the repository
+type SyncedApplication struct {
+	// Name of the Application
+	Name string `json:"name,omitempty"`
+
+	// File is the path of the Appfile in the repository
+	File string `json:"file"`
+
+	// Error is why the Appfile can't be applied, it's empty if the Appfile is applied
+	Error string `json:"error,omitempty"`
+}
+
+// GitSourceStatus defines the observed state of GitSource
+type GitSourceStatus struct {
+	runtimev1alpha1.ConditionedStatus `json:",inline"`
+
+	// Commit is the SHA of the commit applied last time
+	Commit string `json:"commit,omitempty"`
+
+	// LastSyncTime is the last time the repository is synced
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+
+	// Applications are synced from Appfiles of the commit
+	Applications []SyncedApplication `json:"applications,omitempty"`
+
+	// ObservedGeneration is the generation of the GitSource synced last time
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+// GitSource is the Schema for the GitSource API, vela-core polls the git repository and applies
+// Appfiles in it as Applications, Applications whose Appfiles are removed from the repository are deleted.
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:categories={oam}
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="REPOSITORY",type=string,JSONPath=`.spec.repository`
+// +kubebuilder:printcolumn:name="COMMIT",type=string,JSONPath=`.status.commit`
+// +kubebuilder:printcolumn:name="AGE",type=date,JSONPath=".metadata.creationTimestamp"
+type GitSource struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   GitSourceSpec   `json:"spec,omitempty"`
+	Status GitSourceStatus `json:"status,omitempty"`
+}
+
+// GitSourceList contains a list of GitSource
+// +kubebuilder:object:root=true
+type GitSourceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []GitSource `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&GitSource{}, &GitSourceList{})
+}
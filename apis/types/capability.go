@@ -51,8 +51,18 @@ type Capability struct {
 	Status         string      `json:"status,omitempty"`
 	Description    string      `json:"description,omitempty"`
 
+	// Health is a CUE expression evaluated against the live object as `context.output`, the object is healthy if it's true
+	Health string `json:"health,omitempty"`
+	// Message is a CUE string evaluated against the live object as `context.output`, it describes the object's status
+	Message string `json:"message,omitempty"`
+
 	// trait only
 	AppliesTo []string `json:"appliesTo,omitempty"`
+	// ConflictsWith are names of traits which can't be applied to a service along with this trait
+	ConflictsWith []string `json:"conflictsWith,omitempty"`
+
+	// scope only
+	WorkloadRefsPath string `json:"workloadRefsPath,omitempty"`
 
 	// Plugin Source
 	Source  *Source       `json:"source,omitempty"`
@@ -33,10 +33,72 @@ type EnvMeta struct {
 	Namespace string `json:"namespace"`
 	Email     string `json:"email,omitempty"`
 	Domain    string `json:"domain,omitempty"`
+	// HostPattern generates hosts of routes without an explicit host, it defaults to {service}.{app}.{domain}
+	HostPattern string `json:"hostPattern,omitempty"`
+	// ConfigStore is the backend of `vela config` in this env, one of local (default), encrypted and kubernetes
+	ConfigStore string `json:"configStore,omitempty"`
+	// KubeConfig and KubeContext bind the env to a cluster, they default to the kubeconfig vela runs with.
+	// They are only kept in the local env cache, never in the env stored in the cluster.
+	KubeConfig  string `json:"kubeConfig,omitempty"`
+	KubeContext string `json:"kubeContext,omitempty"`
+	// Cluster refers to a cluster inline, it overrides the cluster of the kubeconfig context while credentials
+	// still come from the context. Like KubeConfig and KubeContext, it's only kept in the local env cache.
+	Cluster *ClusterRef `json:"cluster,omitempty"`
+	// RouteProvider is the ingress controller serving routes of the env, e.g. nginx (default) or contour
+	RouteProvider string `json:"routeProvider,omitempty"`
+	// IssuerConfig configures the certificate issuer for routes of the env
+	IssuerConfig *IssuerConfig `json:"issuerConfig,omitempty"`
+	// Quota is the hard limits of ResourceQuota in the env namespace, e.g. cpu: 4, memory: 8Gi, pods: 20
+	Quota map[string]string `json:"quota,omitempty"`
+	// Limits is the LimitRange of containers in the env namespace
+	Limits *LimitConfig `json:"limits,omitempty"`
+	// Isolation is the network isolation of the env namespace, one of open (default), namespace-only and deny-all
+	Isolation string `json:"isolation,omitempty"`
+	// IngressNamespaceSelector selects the namespace of the ingress controller, which namespace-only isolation
+	// still allows traffic from, it defaults to the namespace of ingress-nginx
+	IngressNamespaceSelector map[string]string `json:"ingressNamespaceSelector,omitempty"`
 
 	// Below are not arguments, should be auto-generated
-	Issuer  string `json:"issuer"`
-	Current string `json:"current,omitempty"`
+	Issuer string `json:"issuer"`
+	// IssuerType is Issuer or ClusterIssuer, routes refer to the issuer by it
+	IssuerType string `json:"issuerType,omitempty"`
+	Current    string `json:"current,omitempty"`
+}
+
+// IssuerConfig configures the cert-manager issuer of an env
+type IssuerConfig struct {
+	// Kind is one of acme (default), acme-staging, ca and selfsigned
+	Kind string `json:"kind,omitempty"`
+	// IngressClass is the class of ingress solving ACME HTTP01 challenges, it defaults to the route provider
+	IngressClass string `json:"ingressClass,omitempty"`
+	// DNS01 solves ACME challenges by DNS records instead of HTTP01
+	DNS01 *DNS01Solver `json:"dns01,omitempty"`
+	// CASecret is the Secret holding the CA key pair which the ca issuer signs certificates with
+	CASecret string `json:"caSecret,omitempty"`
+	// ClusterIssuer refers to an existing ClusterIssuer, no issuer is created for the env if it's set
+	ClusterIssuer string `json:"clusterIssuer,omitempty"`
+}
+
+// LimitConfig is the default and max resources of each container, e.g. cpu: 500m, memory: 512Mi
+type LimitConfig struct {
+	Default        map[string]string `json:"default,omitempty"`
+	DefaultRequest map[string]string `json:"defaultRequest,omitempty"`
+	Max            map[string]string `json:"max,omitempty"`
+}
+
+// DNS01Solver is a DNS provider of ACME DNS01 challenges, its API token is read from a Secret in the env namespace
+type DNS01Solver struct {
+	// Provider is one of cloudflare and digitalocean
+	Provider   string `json:"provider"`
+	SecretName string `json:"secretName"`
+	SecretKey  string `json:"secretKey"`
+}
+
+// ClusterRef refers to the API server of a cluster, it never holds credentials
+type ClusterRef struct {
+	Server                   string `json:"server"`
+	CertificateAuthorityData []byte `json:"certificateAuthorityData,omitempty"`
+	InsecureSkipTLSVerify    bool   `json:"insecureSkipTLSVerify,omitempty"`
 }
 
 const (
@@ -141,7 +141,10 @@ func main() {
 			setupLog.Error(err, "unable to setup oam runtime webhook")
 			os.Exit(1)
 		}
-		velawebhook.Register(mgr)
+		if err = velawebhook.Register(mgr); err != nil {
+			setupLog.Error(err, "unable to setup vela webhook")
+			os.Exit(1)
+		}
 		if err := waitWebhookSecretVolume(certDir, waitSecretTimeout, waitSecretInterval); err != nil {
 			setupLog.Error(err, "unable to get webhook secret")
 			os.Exit(1)
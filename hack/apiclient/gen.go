@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/oam-dev/kubevela/pkg/server"
+)
+
+// clientFile is relative to the Makefile where this is invoked.
+const clientFile = "pkg/server/apiclient/zz_generated.client.go"
+
+func main() {
+	gin.SetMode(gin.ReleaseMode)
+	source, err := Generate(server.Operations())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := ioutil.WriteFile(clientFile, source, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// Generate renders methods of apiclient.Client for operations
+func Generate(ops []server.Operation) ([]byte, error) {
+	imports := map[string]string{"context": "context"}
+	var methods bytes.Buffer
+	for _, op := range ops {
+		// raw responses like the OpenAPI document are not for the client
+		if op.Raw {
+			continue
+		}
+		writeMethod(&methods, op, imports)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by hack/apiclient/gen.go. DO NOT EDIT.\n\npackage apiclient\n\nimport (\n")
+	paths := make([]string, 0, len(imports))
+	for p := range imports {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	// standard packages go first, separated from others by a blank line as goimports does
+	sort.SliceStable(paths, func(i, j int) bool {
+		return !strings.Contains(paths[i], ".") && strings.Contains(paths[j], ".")
+	})
+	for i, p := range paths {
+		if i > 0 && !strings.Contains(paths[i-1], ".") && strings.Contains(p, ".") {
+			buf.WriteString("\n")
+		}
+		if alias := imports[p]; alias != p[strings.LastIndex(p, "/")+1:] {
+			fmt.Fprintf(&buf, "\t%s %q\n", alias, p)
+		} else {
+			fmt.Fprintf(&buf, "\t%q\n", p)
+		}
+	}
+	buf.WriteString(")\n")
+	buf.Write(methods.Bytes())
+	return format.Source(buf.Bytes())
+}
+
+func writeMethod(buf *bytes.Buffer, op server.Operation, imports map[string]string) {
+	params := []string{"ctx context.Context"}
+	var pathFormat []string
+	var pathArgs []string
+	for _, seg := range strings.Split(op.Path, "/") {
+		if strings.HasPrefix(seg, ":") {
+			name := strings.TrimPrefix(seg, ":")
+			params = append(params, name+" string")
+			pathArgs = append(pathArgs, fmt.Sprintf("url.PathEscape(%s)", name))
+			seg = "%s"
+		}
+		pathFormat = append(pathFormat, seg)
+	}
+	path := fmt.Sprintf("%q", strings.Join(pathFormat, "/"))
+	if len(pathArgs) != 0 {
+		imports["fmt"] = "fmt"
+		imports["net/url"] = "url"
+		path = fmt.Sprintf("fmt.Sprintf(%s, %s)", path, strings.Join(pathArgs, ", "))
+	}
+	body := "nil"
+	if op.Body != nil {
+		params = append(params, "body "+typeExpr(op.Body, imports))
+		body = "body"
+	}
+	imports["net/http"] = "http"
+	method := "http.Method" + strings.Title(strings.ToLower(op.Method))
+
+	fmt.Fprintf(buf, "\n// %s calls `%s %s`: %s\n", op.ID, op.Method, op.Path, op.Summary)
+	switch {
+	case op.Stream:
+		imports["encoding/json"] = "json"
+		params = append(params, "handler func(event string, data json.RawMessage) error")
+		fmt.Fprintf(buf, "func (c *Client) %s(%s) error {\n", op.ID, strings.Join(params, ", "))
+		fmt.Fprintf(buf, "\treturn c.stream(ctx, %s, handler)\n}\n", path)
+	case op.Response == nil:
+		fmt.Fprintf(buf, "func (c *Client) %s(%s) error {\n", op.ID, strings.Join(params, ", "))
+		fmt.Fprintf(buf, "\treturn c.do(ctx, %s, %s, %s, nil)\n}\n", method, path, body)
+	default:
+		out := typeExpr(op.Response, imports)
+		fmt.Fprintf(buf, "func (c *Client) %s(%s) (%s, error) {\n", op.ID, strings.Join(params, ", "), out)
+		fmt.Fprintf(buf, "\tvar out %s\n", out)
+		fmt.Fprintf(buf, "\terr := c.do(ctx, %s, %s, %s, &out)\n", method, path, body)
+		buf.WriteString("\treturn out, err\n}\n")
+	}
+}
+
+// typeExpr returns the Go expression of type t, packages of named types are added into imports
+func typeExpr(t reflect.Type, imports map[string]string) string {
+	switch {
+	case t.Name() != "" && t.PkgPath() != "":
+		return importAlias(t.PkgPath(), imports) + "." + t.Name()
+	case t.Name() != "":
+		return t.Name()
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return "*" + typeExpr(t.Elem(), imports)
+	case reflect.Slice:
+		return "[]" + typeExpr(t.Elem(), imports)
+	case reflect.Map:
+		return "map[" + typeExpr(t.Key(), imports) + "]" + typeExpr(t.Elem(), imports)
+	default:
+		return t.String()
+	}
+}
+
+// importAlias names the package by its last path element, the parent element is prefixed if names conflict
+func importAlias(pkgPath string, imports map[string]string) string {
+	if alias, ok := imports[pkgPath]; ok {
+		return alias
+	}
+	elems := strings.Split(pkgPath, "/")
+	alias := elems[len(elems)-1]
+	for p, a := range imports {
+		if a == alias && p != pkgPath && len(elems) > 1 {
+			alias = strings.Replace(elems[len(elems)-2], "-", "", -1) + alias
+			break
+		}
+	}
+	imports[pkgPath] = alias
+	return alias
+}
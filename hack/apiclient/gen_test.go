@@ -0,0 +1,20 @@
+package main
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/oam-dev/kubevela/pkg/server"
+)
+
+func TestGeneratedClientUpToDate(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	source, err := Generate(server.Operations())
+	assert.NoError(t, err)
+	current, err := ioutil.ReadFile("../../" + clientFile)
+	assert.NoError(t, err)
+	assert.Equal(t, string(source), string(current), "run `make api-client` to regenerate the client")
+}
@@ -2,7 +2,28 @@ output: {
 	apiVersion: "standard.oam.dev/v1alpha1"
 	kind:       "MetricsTrait"
 	spec: {
-		scrapeService: parameter
+		scrapeService: {
+			format:  parameter.format
+			path:    parameter.path
+			scheme:  parameter.scheme
+			enabled: parameter.enabled
+
+			if parameter.port != 0 {
+				port: parameter.port
+			}
+			// default to the first container port of the workload
+			if parameter.port == 0 && context["ports"] != _|_ {
+				port: context.ports[0]
+			}
+
+			if parameter["selector"] != _|_ {
+				selector: parameter.selector
+			}
+			// default to labels of pods of the workload
+			if parameter["selector"] == _|_ && context["labels"] != _|_ {
+				selector: context.labels
+			}
+		}
 	}
 }
 parameter: {
@@ -2,22 +2,47 @@ output: {
 	apiVersion: "standard.oam.dev/v1alpha1"
 	kind:       "Route"
 	spec: {
-		host: parameter.domain
+		if parameter.domain != "" {
+			host: parameter.domain
+		}
+		if parameter.domain == "" && context["host"] != _|_ {
+			host: context.host
+		}
 
 		if parameter.issuer != "" {
 			tls: {
 				issuerName: parameter.issuer
+				if parameter.issuerType != "" {
+					type: parameter.issuerType
+				}
+			}
+		}
+		if parameter.issuer == "" && context["issuer"] != _|_ {
+			tls: {
+				issuerName: context.issuer
+				if context["issuerType"] != _|_ {
+					type: context.issuerType
+				}
 			}
 		}
 
+		if parameter.provider != "" {
+			provider: parameter.provider
+		}
+		if parameter.provider == "" && context["routeProvider"] != _|_ {
+			provider: context.routeProvider
+		}
+
 		if parameter["rules"] != _|_ {
 			rules: parameter.rules
 		}
 	}
 }
 parameter: {
-	domain: *"" | string
-	issuer: *"" | string
+	domain:     *"" | string
+	issuer:     *"" | string
+	issuerType: *"" | string
+	provider:   *"" | string
 	rules?: [...{
 		path:          string
 		rewriteTarget: *"" | string
@@ -4,15 +4,30 @@ output: {
 	spec: {
 		parallelism: parameter.count
 		completions: parameter.count
-		template: spec: {
-			containers: [{
-				name:  context.name
-				image: parameter.image
-
-				if parameter["cmd"] != _|_ {
-					command: parameter.cmd
+		template: {
+			// the config the pods ran with
+			if context["configRef"] != _|_ {
+				metadata: annotations: {
+					"config.oam.dev/hash": context.configRef.hash
 				}
-			}]
+			}
+
+			spec: {
+				containers: [{
+					name:  context.name
+					image: parameter.image
+
+					if parameter["cmd"] != _|_ {
+						command: parameter.cmd
+					}
+
+					if context["configRef"] != _|_ {
+						envFrom: [{
+							secretRef: name: context.configRef.name
+						}]
+					}
+				}]
+			}
 		}
 	}
 }
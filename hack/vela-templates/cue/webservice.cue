@@ -7,8 +7,16 @@ output: {
 		}
 
 		template: {
-			metadata: labels: {
-				"app.oam.dev/component": context.name
+			metadata: {
+				labels: {
+					"app.oam.dev/component": context.name
+				}
+				// roll pods when the config changes
+				if context["configRef"] != _|_ {
+					annotations: {
+						"config.oam.dev/hash": context.configRef.hash
+					}
+				}
 			}
 
 			spec: {
@@ -24,8 +32,10 @@ output: {
 						env: parameter.env
 					}
 
-					if context["config"] != _|_ {
-						env: context.config
+					if context["configRef"] != _|_ {
+						envFrom: [{
+							secretRef: name: context.configRef.name
+						}]
 					}
 
 					ports: [{
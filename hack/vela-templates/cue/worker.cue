@@ -7,8 +7,16 @@ output: {
 		}
 
 		template: {
-			metadata: labels: {
-				"app.oam.dev/component": context.name
+			metadata: {
+				labels: {
+					"app.oam.dev/component": context.name
+				}
+				// roll pods when the config changes
+				if context["configRef"] != _|_ {
+					annotations: {
+						"config.oam.dev/hash": context.configRef.hash
+					}
+				}
 			}
 
 			spec: {
@@ -19,6 +27,12 @@ output: {
 					if parameter["cmd"] != _|_ {
 						command: parameter.cmd
 					}
+
+					if context["configRef"] != _|_ {
+						envFrom: [{
+							secretRef: name: context.configRef.name
+						}]
+					}
 				}]
 			}
 		}
@@ -10,11 +10,14 @@ import (
 	"github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
 	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam"
 	"github.com/ghodss/yaml"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
+	"github.com/oam-dev/kubevela/apis/types"
 	"github.com/oam-dev/kubevela/pkg/appfile/template"
 	cmdutil "github.com/oam-dev/kubevela/pkg/commands/util"
+	"github.com/oam-dev/kubevela/pkg/utils/env"
 )
 
 // error msg used in Appfile
@@ -34,6 +37,8 @@ type AppFile struct {
 	Secrets    map[string]string  `json:"secrets,omitempty"`
 
 	configGetter configGetter
+	// localSourcesDisallowed rejects secrets read from files and environment variables of the rendering process
+	localSourcesDisallowed bool
 }
 
 // NewAppFile init an empty AppFile struct
@@ -45,6 +50,19 @@ func NewAppFile() *AppFile {
 	}
 }
 
+// SetEnv sets the env which the Appfile is deployed to, configs and hosts of routes are rendered from it.
+// The current env is used if it's not set.
+func (app *AppFile) SetEnv(envMeta *types.EnvMeta) {
+	app.configGetter = defaultConfigGetter{env: envMeta}
+}
+
+// DisallowLocalSources makes rendering reject `file:` and `env:` secrets. Servers rendering Appfiles sent by users,
+// e.g. the API server and controllers, must set it, otherwise callers could read files and environment variables of
+// the server into Secrets.
+func (app *AppFile) DisallowLocalSources() {
+	app.localSourcesDisallowed = true
+}
+
 // Load will load appfile from default path
 func Load() (*AppFile, error) {
 	return LoadFromFile(DefaultAppfilePath)
@@ -56,21 +74,30 @@ func LoadFromFile(filename string) (*AppFile, error) {
 	if err != nil {
 		return nil, err
 	}
-	af := NewAppFile()
-	err = yaml.Unmarshal(b, af)
+	af, err := LoadFromBytes(b)
 	if err != nil {
 		return nil, err
 	}
+	af.resolveSecretFiles(filepath.Dir(filename))
+	return af, nil
+}
+
+// LoadFromBytes will load the AppFile struct from YAML or JSON data
+func LoadFromBytes(b []byte) (*AppFile, error) {
+	af := NewAppFile()
+	if err := yaml.Unmarshal(b, af); err != nil {
+		return nil, err
+	}
 	return af, nil
 }
 
-// BuildOAM renders Appfile into AppConfig, Components. It also builds images for services if defined.
+// BuildOAM renders Appfile into AppConfig, Components, the default HealthScope and Secrets. It also builds images for services if defined.
 func (app *AppFile) BuildOAM(ns string, io cmdutil.IOStreams, tm template.Manager, slience bool) (
 	[]*v1alpha2.Component, *v1alpha2.ApplicationConfiguration, []oam.Object, error) {
 	return app.buildOAM(ns, io, true, tm, slience)
 }
 
-// RenderOAM renders Appfile into AppConfig, Components.
+// RenderOAM renders Appfile into AppConfig, Components, the default HealthScope and Secrets.
 func (app *AppFile) RenderOAM(ns string, io cmdutil.IOStreams, tm template.Manager, silence bool) (
 	[]*v1alpha2.Component, *v1alpha2.ApplicationConfiguration, []oam.Object, error) {
 	return app.buildOAM(ns, io, false, tm, silence)
@@ -88,7 +115,23 @@ func (app *AppFile) buildOAM(ns string, io cmdutil.IOStreams, buildImage bool, t
 
 	var comps []*v1alpha2.Component
 
-	for sname, svc := range app.GetServices() {
+	secrets, ctxSecrets, err := app.renderSecrets(ns)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	configSecrets := make(map[string]*corev1.Secret)
+	envMeta, err := app.configGetter.GetEnv()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	order, err := app.GetServiceOrder()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	// services are rendered in dependency order, so are Components of the AppConfig
+	for _, sname := range order {
+		svc := app.Services[sname]
 		var image string
 		v, ok := svc["image"]
 		if ok {
@@ -109,7 +152,27 @@ func (app *AppFile) buildOAM(ns string, io cmdutil.IOStreams, buildImage bool, t
 		if !silence {
 			io.Infof("\nRendering configs for service (%s)...\n", sname)
 		}
-		acComp, comp, err := svc.RenderService(tm, sname, ns, app.configGetter)
+		var configSecret *corev1.Secret
+		if cn := svc.GetUserConfigName(); cn != "" {
+			if configSecret = configSecrets[cn]; configSecret == nil {
+				if configSecret, err = app.renderConfigSecret(cn, ns); err != nil {
+					return nil, nil, nil, err
+				}
+				configSecrets[cn] = configSecret
+				secrets = append(secrets, configSecret)
+			}
+		}
+		acComp, comp, err := svc.RenderService(tm, sname, RenderContext{
+			AppName:       app.Name,
+			Namespace:     ns,
+			EnvName:       envMeta.Name,
+			Host:          env.RenderHost(envMeta, app.Name, sname),
+			Issuer:        envMeta.Issuer,
+			IssuerType:    envMeta.IssuerType,
+			RouteProvider: envMeta.RouteProvider,
+			ConfigSecret:  configSecret,
+			Secrets:       ctxSecrets,
+		})
 		if err != nil {
 			return nil, nil, nil, err
 		}
@@ -118,8 +181,14 @@ func (app *AppFile) buildOAM(ns string, io cmdutil.IOStreams, buildImage bool, t
 	}
 
 	addWorkloadTypeLabel(comps, app.Services)
-	health := addHealthScope(appConfig)
-	return comps, appConfig, []oam.Object{health}, nil
+	var objects []oam.Object
+	if health := addHealthScope(appConfig); health != nil {
+		objects = append(objects, health)
+	}
+	for _, secret := range secrets {
+		objects = append(objects, secret)
+	}
+	return comps, appConfig, objects, nil
 }
 
 func addWorkloadTypeLabel(comps []*v1alpha2.Component, services map[string]Service) {
@@ -136,6 +205,8 @@ func addWorkloadTypeLabel(comps []*v1alpha2.Component, services map[string]Servi
 	}
 }
 
+// addHealthScope adds the default HealthScope to components which don't declare a HealthScope in their scopes.
+// It returns nil if no component uses the default HealthScope.
 func addHealthScope(appConfig *v1alpha2.ApplicationConfiguration) *v1alpha2.HealthScope {
 	health := &v1alpha2.HealthScope{
 		TypeMeta: metav1.TypeMeta{
@@ -146,8 +217,11 @@ func addHealthScope(appConfig *v1alpha2.ApplicationConfiguration) *v1alpha2.Heal
 	health.Name = FormatDefaultHealthScopeName(appConfig.Name)
 	health.Namespace = appConfig.Namespace
 	health.Spec.WorkloadReferences = make([]v1alpha1.TypedReference, 0)
+	var used bool
 	for i := range appConfig.Spec.Components {
-		// TODO(wonderflow): Temporarily we add health scope here, should change to use scope framework
+		if hasHealthScope(appConfig.Spec.Components[i].Scopes) {
+			continue
+		}
 		appConfig.Spec.Components[i].Scopes = append(appConfig.Spec.Components[i].Scopes, v1alpha2.ComponentScope{
 			ScopeReference: v1alpha1.TypedReference{
 				APIVersion: v1alpha2.SchemeGroupVersion.String(),
@@ -155,10 +229,23 @@ func addHealthScope(appConfig *v1alpha2.ApplicationConfiguration) *v1alpha2.Heal
 				Name:       health.Name,
 			},
 		})
+		used = true
+	}
+	if !used {
+		return nil
 	}
 	return health
 }
 
+func hasHealthScope(scopes []v1alpha2.ComponentScope) bool {
+	for _, s := range scopes {
+		if s.ScopeReference.Kind == v1alpha2.HealthScopeKind {
+			return true
+		}
+	}
+	return false
+}
+
 // FormatDefaultHealthScopeName will create a default health scope name.
 func FormatDefaultHealthScopeName(appName string) string {
 	return appName + "-default-health"
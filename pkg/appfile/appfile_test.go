@@ -1,13 +1,18 @@
 package appfile
 
 import (
+	"errors"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
 	"github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam"
 	"github.com/ghodss/yaml"
 	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -57,6 +62,15 @@ services:
     config: test
 `
 
+	yamlWithScopes := `name: myapp
+services:
+  express-server:
+    image: oamdev/testapp:v1
+    cmd: ["node", "server.js"]
+    scopes:
+      healthscopes.core.oam.dev: my-health
+`
+
 	templateWebservice := `parameter: #webservice
 #webservice: {
   cmd: [...string]
@@ -292,15 +306,41 @@ outputs: ingress: {
 		},
 	}
 
+	acWithScopes := &v1alpha2.ApplicationConfiguration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "myapp",
+			Namespace: "default",
+		},
+		Spec: v1alpha2.ApplicationConfigurationSpec{
+			Components: []v1alpha2.ApplicationConfigurationComponent{{
+				ComponentName: "express-server",
+				Traits:        []v1alpha2.ComponentTrait{},
+				Scopes: []v1alpha2.ComponentScope{{
+					ScopeReference: v1alpha1.TypedReference{
+						APIVersion: "core.oam.dev/v1alpha2",
+						Kind:       "HealthScope",
+						Name:       "my-health",
+					},
+				}},
+			}},
+		},
+	}
+
 	compWithConfig := comp1.DeepCopy()
 	fakeConfigData2 := []map[string]string{{
 		"name":  "test",
 		"value": "test-value",
 	}}
 	// for deepCopy. Otherwise deepcopy will panic in SetNestedField.
+	// config values are referred from the Secret instead of plaintext
 	fakeConfigData := []interface{}{map[string]interface{}{
-		"name":  "test",
-		"value": "test-value",
+		"name": "test",
+		"valueFrom": map[string]interface{}{
+			"secretKeyRef": map[string]interface{}{
+				"name": "vela-config-default-test",
+				"key":  "test",
+			},
+		},
 	}}
 	if err := unstructured.SetNestedField(
 		compWithConfig.Spec.Workload.Object.(*unstructured.Unstructured).UnstructuredContent(),
@@ -314,10 +354,12 @@ outputs: ingress: {
 		appfileData       string
 		workloadTemplates map[string]string
 		traitTemplates    map[string]string
+		scopes            map[string]*types.CRDInfo
 	}
 	type want struct {
 		components []*v1alpha2.Component
 		appConfig  *v1alpha2.ApplicationConfiguration
+		scopes     int
 		err        error
 	}
 	cases := map[string]struct {
@@ -378,6 +420,33 @@ outputs: ingress: {
 				components: []*v1alpha2.Component{compWithConfig},
 			},
 		},
+		"user defined scopes should replace the default health scope": {
+			args: args{
+				appfileData: yamlWithScopes,
+				workloadTemplates: map[string]string{
+					"webservice": templateWebservice,
+				},
+				scopes: map[string]*types.CRDInfo{
+					"healthscopes.core.oam.dev": {APIVersion: "core.oam.dev/v1alpha2", Kind: "HealthScope"},
+				},
+			},
+			want: want{
+				appConfig:  acWithScopes,
+				components: []*v1alpha2.Component{comp1},
+				scopes:     0,
+			},
+		},
+		"scope not installed should fail": {
+			args: args{
+				appfileData: yamlWithScopes,
+				workloadTemplates: map[string]string{
+					"webservice": templateWebservice,
+				},
+			},
+			want: want{
+				err: errors.New("scope healthscopes.core.oam.dev is not installed"),
+			},
+		},
 	}
 
 	io := cmdutil.IOStreams{In: os.Stdin, Out: os.Stdout, ErrOut: os.Stderr}
@@ -404,14 +473,23 @@ outputs: ingress: {
 					Raw:     v,
 				}
 			}
+			for k, v := range c.args.scopes {
+				tm.Templates[k] = &template.Template{
+					Captype: types.TypeScope,
+					CrdInfo: v,
+				}
+			}
 
-			comps, ac, _, err := app.RenderOAM("default", io, tm, false)
+			comps, ac, scopes, err := app.RenderOAM("default", io, tm, false)
 			if err != nil {
 				assert.Equal(t, c.want.err, err)
 				return
 			}
 
 			assert.Equal(t, ac.ObjectMeta, c.want.appConfig.ObjectMeta)
+			if c.args.scopes != nil {
+				assert.Equal(t, c.want.scopes, len(scopes))
+			}
 
 			for _, cp1 := range c.want.appConfig.Spec.Components {
 				found := false
@@ -486,3 +564,513 @@ func TestAddWorkloadTypeLabel(t *testing.T) {
 		assert.Equal(t, ca.expect, ca.comps, key)
 	}
 }
+
+func TestLoadFromBytes(t *testing.T) {
+	tests := map[string]struct {
+		data   string
+		expect Service
+	}{
+		"yaml": {
+			data: `
+name: myapp
+services:
+  express-server:
+    image: oamdev/testapp:v1
+    cmd: ["node", "server.js"]
+`,
+			expect: Service{"image": "oamdev/testapp:v1", "cmd": []interface{}{"node", "server.js"}},
+		},
+		"json": {
+			data:   `{"name":"myapp","services":{"express-server":{"image":"oamdev/testapp:v1","cmd":["node","server.js"]}}}`,
+			expect: Service{"image": "oamdev/testapp:v1", "cmd": []interface{}{"node", "server.js"}},
+		},
+	}
+	for key, ca := range tests {
+		af, err := LoadFromBytes([]byte(ca.data))
+		assert.NoError(t, err, key)
+		assert.Equal(t, "myapp", af.Name, key)
+		assert.Equal(t, ca.expect, af.Services["express-server"], key)
+	}
+
+	_, err := LoadFromBytes([]byte("name: [myapp"))
+	assert.Error(t, err)
+}
+
+func TestRenderSecrets(t *testing.T) {
+	dir, err := ioutil.TempDir("", "appfile-secrets")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "password"), []byte("s3cr3t"), 0600))
+	assert.NoError(t, os.Setenv("VELA_TEST_TOKEN", "my-token"))
+	defer os.Unsetenv("VELA_TEST_TOKEN")
+
+	appfileData := `name: myapp
+secrets:
+  db: file:` + filepath.Join(dir, "password") + `
+  token: env:VELA_TEST_TOKEN
+  registry: config:registry
+services:
+  express-server:
+    type: withsecret
+    image: oamdev/testapp:v1
+`
+	templateWithSecret := `parameter: #withsecret
+#withsecret: {
+  image: string
+}
+
+output: {
+  apiVersion: "test.oam.dev/v1"
+  kind: "WebService"
+  metadata: name: context.name
+  spec: {
+    image: parameter.image
+    env: [{
+      name: "DB_PASSWORD"
+      valueFrom: secretKeyRef: {
+        name: context.secrets.db.name
+        key: context.secrets.db.keys[0]
+      }
+    }]
+  }
+}
+`
+	app := NewAppFile()
+	app.configGetter = &fakeConfigGetter{Data: []map[string]string{{"name": "username", "value": "admin"}}}
+	assert.NoError(t, yaml.Unmarshal([]byte(appfileData), app))
+	tm := template.NewFakeTemplateManager()
+	tm.Templates["withsecret"] = &template.Template{Captype: types.TypeWorkload, Raw: templateWithSecret}
+
+	io := cmdutil.IOStreams{In: os.Stdin, Out: os.Stdout, ErrOut: os.Stderr}
+	comps, _, objects, err := app.RenderOAM("default", io, tm, true)
+	assert.NoError(t, err)
+
+	workload := comps[0].Spec.Workload.Object.(*unstructured.Unstructured)
+	env, _, _ := unstructured.NestedSlice(workload.Object, "spec", "env")
+	assert.Equal(t, []interface{}{map[string]interface{}{
+		"name": "DB_PASSWORD",
+		"valueFrom": map[string]interface{}{
+			"secretKeyRef": map[string]interface{}{"name": "myapp-db", "key": "password"},
+		},
+	}}, env)
+
+	var secrets []*corev1.Secret
+	for _, obj := range objects {
+		if s, ok := obj.(*corev1.Secret); ok {
+			secrets = append(secrets, s)
+		}
+	}
+	assert.Equal(t, 3, len(secrets))
+	assert.Equal(t, "myapp-db", secrets[0].Name)
+	assert.Equal(t, map[string][]byte{"password": []byte("s3cr3t")}, secrets[0].Data)
+	assert.Equal(t, "myapp-registry", secrets[1].Name)
+	assert.Equal(t, map[string][]byte{"username": []byte("admin")}, secrets[1].Data)
+	assert.Equal(t, "myapp-token", secrets[2].Name)
+	assert.Equal(t, map[string][]byte{"VELA_TEST_TOKEN": []byte("my-token")}, secrets[2].Data)
+	assert.Equal(t, "myapp", secrets[2].Labels[oam.LabelAppName])
+
+	for _, obj := range RedactSecrets(objects) {
+		if s, ok := obj.(*corev1.Secret); ok {
+			for _, v := range s.Data {
+				assert.Equal(t, redactedValue, string(v))
+			}
+		}
+	}
+	// redaction must not change the objects to apply
+	assert.Equal(t, []byte("s3cr3t"), secrets[0].Data["password"])
+
+	app.Secrets = map[string]string{"bad": "vault:foo"}
+	_, _, _, err = app.RenderOAM("default", io, tm, true)
+	assert.Error(t, err)
+
+	app.Secrets = map[string]string{"token": "env:VELA_TEST_TOKEN", "db": "config:registry"}
+	app.DisallowLocalSources()
+	_, _, _, err = app.RenderOAM("default", io, tm, true)
+	assert.EqualError(t, err, "secret token: env sources are only allowed when deploying from the CLI")
+	delete(app.Secrets, "token")
+	_, _, _, err = app.RenderOAM("default", io, tm, true)
+	assert.NoError(t, err)
+}
+
+func TestLoadFromFileResolvesSecretFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "appfile-secrets")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "vela.yaml"), []byte(`name: myapp
+secrets:
+  db: file:./password
+  abs: file:/etc/password
+  token: env:TOKEN
+services: {}
+`), 0600))
+
+	app, err := LoadFromFile(filepath.Join(dir, "vela.yaml"))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"db":    "file:" + filepath.Join(dir, "password"),
+		"abs":   "file:/etc/password",
+		"token": "env:TOKEN",
+	}, app.Secrets)
+}
+
+func TestRenderConfigSecret(t *testing.T) {
+	appfileData := `name: myapp
+services:
+  express-server:
+    type: withconfigref
+    image: oamdev/testapp:v1
+    config: demo
+  worker:
+    type: withconfigref
+    image: oamdev/testapp:v1
+    config: demo
+`
+	templateWithConfigRef := `parameter: #withconfigref
+#withconfigref: {
+  image: string
+}
+
+output: {
+  apiVersion: "test.oam.dev/v1"
+  kind: "WebService"
+  metadata: annotations: "config.oam.dev/hash": context.configRef.hash
+  spec: {
+    image: parameter.image
+    envFrom: [{secretRef: name: context.configRef.name}]
+  }
+}
+`
+	tm := template.NewFakeTemplateManager()
+	tm.Templates["withconfigref"] = &template.Template{Captype: types.TypeWorkload, Raw: templateWithConfigRef}
+	io := cmdutil.IOStreams{In: os.Stdin, Out: os.Stdout, ErrOut: os.Stderr}
+	render := func(value string) ([]*v1alpha2.Component, []*corev1.Secret) {
+		app := NewAppFile()
+		app.configGetter = &fakeConfigGetter{Data: []map[string]string{{"name": "DEMO_HELLO", "value": value}}}
+		assert.NoError(t, yaml.Unmarshal([]byte(appfileData), app))
+		comps, _, objects, err := app.RenderOAM("default", io, tm, true)
+		assert.NoError(t, err)
+		var secrets []*corev1.Secret
+		for _, obj := range objects {
+			if s, ok := obj.(*corev1.Secret); ok {
+				secrets = append(secrets, s)
+			}
+		}
+		return comps, secrets
+	}
+
+	comps, secrets := render("hello")
+	// services sharing a config share the Secret
+	assert.Equal(t, 1, len(secrets))
+	assert.Equal(t, "vela-config-default-demo", secrets[0].Name)
+	assert.Equal(t, map[string][]byte{"DEMO_HELLO": []byte("hello")}, secrets[0].Data)
+	hash := secrets[0].Annotations[AnnotationConfigHash]
+	assert.NotEmpty(t, hash)
+	for _, comp := range comps {
+		workload := comp.Spec.Workload.Object.(*unstructured.Unstructured)
+		assert.Equal(t, hash, workload.GetAnnotations()[AnnotationConfigHash])
+		envFrom, _, _ := unstructured.NestedSlice(workload.Object, "spec", "envFrom")
+		assert.Equal(t, []interface{}{map[string]interface{}{
+			"secretRef": map[string]interface{}{"name": "vela-config-default-demo"},
+		}}, envFrom)
+	}
+
+	// the hash changes with config content, so workloads roll
+	_, secrets = render("world")
+	assert.NotEqual(t, hash, secrets[0].Annotations[AnnotationConfigHash])
+}
+
+func TestRenderHost(t *testing.T) {
+	appfileData := `name: myapp
+services:
+  frontend:
+    type: withhost
+    image: oamdev/testapp:v1
+`
+	templateWithHost := `parameter: #withhost
+#withhost: {
+  image: string
+}
+
+output: {
+  apiVersion: "test.oam.dev/v1"
+  kind: "WebService"
+  spec: {
+    image: parameter.image
+    if context["host"] != _|_ {
+      host: context.host
+    }
+  }
+}
+`
+	tm := template.NewFakeTemplateManager()
+	tm.Templates["withhost"] = &template.Template{Captype: types.TypeWorkload, Raw: templateWithHost}
+	io := cmdutil.IOStreams{In: os.Stdin, Out: os.Stdout, ErrOut: os.Stderr}
+	render := func(envMeta *types.EnvMeta) string {
+		app := NewAppFile()
+		app.configGetter = &fakeConfigGetter{Env: envMeta}
+		assert.NoError(t, yaml.Unmarshal([]byte(appfileData), app))
+		comps, _, _, err := app.RenderOAM("default", io, tm, true)
+		assert.NoError(t, err)
+		host, _, _ := unstructured.NestedString(comps[0].Spec.Workload.Object.(*unstructured.Unstructured).Object, "spec", "host")
+		return host
+	}
+	assert.Equal(t, "", render(&types.EnvMeta{Name: "default"}))
+	assert.Equal(t, "frontend.myapp.example.com", render(&types.EnvMeta{Name: "default", Domain: "example.com"}))
+	assert.Equal(t, "myapp.prod.example.com", render(&types.EnvMeta{Name: "prod", Domain: "example.com", HostPattern: "{app}.{env}.{domain}"}))
+}
+
+func TestRenderRouteDefaultsOfEnv(t *testing.T) {
+	routeTemplate, err := ioutil.ReadFile("../../hack/vela-templates/cue/route.cue")
+	assert.NoError(t, err)
+	tm := template.NewFakeTemplateManager()
+	tm.Templates["worker"] = &template.Template{Captype: types.TypeWorkload, Raw: `parameter: {
+  image: string
+}
+output: {
+  apiVersion: "apps/v1"
+  kind: "Deployment"
+  spec: containers: [{image: parameter.image}]
+}
+`}
+	tm.Templates["route"] = &template.Template{Captype: types.TypeTrait, Raw: string(routeTemplate)}
+	io := cmdutil.IOStreams{In: os.Stdin, Out: os.Stdout, ErrOut: os.Stderr}
+	render := func(envMeta *types.EnvMeta, route string) map[string]interface{} {
+		app := NewAppFile()
+		app.configGetter = &fakeConfigGetter{Env: envMeta}
+		assert.NoError(t, yaml.Unmarshal([]byte(`name: myapp
+services:
+  frontend:
+    type: worker
+    image: oamdev/testapp:v1
+    route: `+route+`
+`), app))
+		_, ac, _, err := app.RenderOAM("default", io, tm, true)
+		assert.NoError(t, err)
+		spec, _, _ := unstructured.NestedMap(ac.Spec.Components[0].Traits[0].Trait.Object.(*unstructured.Unstructured).Object, "spec")
+		return spec
+	}
+	envMeta := &types.EnvMeta{Name: "prod", Domain: "example.com", Issuer: "oam-env-prod", IssuerType: "ClusterIssuer", RouteProvider: "contour"}
+
+	// routes without their own issuer and provider use the ones of env
+	assert.Equal(t, map[string]interface{}{
+		"host":     "frontend.myapp.example.com",
+		"tls":      map[string]interface{}{"issuerName": "oam-env-prod", "type": "ClusterIssuer"},
+		"provider": "contour",
+	}, render(envMeta, "{}"))
+
+	assert.Equal(t, map[string]interface{}{
+		"host":     "a.example.com",
+		"tls":      map[string]interface{}{"issuerName": "my-issuer"},
+		"provider": "nginx",
+	}, render(envMeta, "{domain: a.example.com, issuer: my-issuer, provider: nginx}"))
+
+	assert.Equal(t, map[string]interface{}{"host": "frontend.myapp.example.com"},
+		render(&types.EnvMeta{Name: "prod", Domain: "example.com"}, "{}"))
+}
+
+func TestRenderTraitContext(t *testing.T) {
+	appfileData := `name: myapp
+services:
+  frontend:
+    image: oamdev/testapp:v1
+    port: 8080
+    exporter: {}
+`
+	templateDeployment := `parameter: {
+  image: string
+  port: int
+}
+
+output: {
+  apiVersion: "apps/v1"
+  kind: "Deployment"
+  spec: template: {
+    metadata: labels: "app.oam.dev/component": context.name
+    spec: containers: [{
+      name: context.name
+      image: parameter.image
+      ports: [{containerPort: parameter.port}]
+    }]
+  }
+}
+`
+	templateExporter := `parameter: {}
+
+output: {
+  apiVersion: "test.oam.dev/v1"
+  kind: "Exporter"
+  metadata: labels: {
+    app: context.appName
+    env: context.envName
+    namespace: context.namespace
+  }
+  spec: {
+    workloadKind: context.output.kind
+    port: context.ports[0]
+    selector: context.labels
+  }
+}
+`
+	tm := template.NewFakeTemplateManager()
+	tm.Templates["webservice"] = &template.Template{Captype: types.TypeWorkload, Raw: templateDeployment}
+	tm.Templates["exporter"] = &template.Template{Captype: types.TypeTrait, Raw: templateExporter}
+	io := cmdutil.IOStreams{In: os.Stdin, Out: os.Stdout, ErrOut: os.Stderr}
+	app := NewAppFile()
+	app.configGetter = &fakeConfigGetter{Env: &types.EnvMeta{Name: "prod", Namespace: "prod-ns"}}
+	assert.NoError(t, yaml.Unmarshal([]byte(appfileData), app))
+	_, ac, _, err := app.RenderOAM("prod-ns", io, tm, true)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(ac.Spec.Components[0].Traits))
+	trait := ac.Spec.Components[0].Traits[0].Trait.Object.(*unstructured.Unstructured)
+	assert.Equal(t, map[string]string{
+		"app": "myapp", "env": "prod", "namespace": "prod-ns", oam.TraitTypeLabel: "exporter",
+	}, trait.GetLabels())
+	assert.Equal(t, map[string]interface{}{
+		"workloadKind": "Deployment",
+		"port":         float64(8080),
+		"selector":     map[string]interface{}{"app.oam.dev/component": "frontend"},
+	}, trait.Object["spec"])
+}
+
+func TestRenderTraitPatch(t *testing.T) {
+	templateWorker := `parameter: {
+  image: string
+}
+
+output: {
+  apiVersion: "apps/v1"
+  kind: "Deployment"
+  spec: template: spec: containers: [{
+    name: context.name
+    image: parameter.image
+  }]
+}
+`
+	templateSidecar := `parameter: {
+  image: string
+}
+
+patch: spec: template: spec: containers: [{
+  name: "sidecar"
+  image: parameter.image
+}]
+`
+	templateNode := `parameter: {
+  disk: string
+}
+
+patch: spec: template: spec: nodeSelector: disk: parameter.disk
+
+output: {
+  apiVersion: "test.oam.dev/v1"
+  kind: "Placement"
+  spec: disk: parameter.disk
+}
+`
+	tm := template.NewFakeTemplateManager()
+	tm.Templates["worker"] = &template.Template{Captype: types.TypeWorkload, Raw: templateWorker}
+	tm.Templates["sidecar"] = &template.Template{Captype: types.TypeTrait, Raw: templateSidecar}
+	tm.Templates["node"] = &template.Template{Captype: types.TypeTrait, Raw: templateNode}
+	tm.Templates["node2"] = &template.Template{Captype: types.TypeTrait, Raw: templateNode}
+	io := cmdutil.IOStreams{In: os.Stdin, Out: os.Stdout, ErrOut: os.Stderr}
+	render := func(data string) ([]*v1alpha2.Component, *v1alpha2.ApplicationConfiguration, error) {
+		app := NewAppFile()
+		app.configGetter = &fakeConfigGetter{}
+		assert.NoError(t, yaml.Unmarshal([]byte(data), app))
+		comps, ac, _, err := app.RenderOAM("default", io, tm, true)
+		return comps, ac, err
+	}
+
+	comps, ac, err := render(`name: myapp
+services:
+  backend:
+    type: worker
+    image: busybox
+    sidecar:
+      image: envoy
+    node:
+      disk: ssd
+`)
+	assert.NoError(t, err)
+	workload := comps[0].Spec.Workload.Object.(*unstructured.Unstructured)
+	containers, _, _ := unstructured.NestedSlice(workload.Object, "spec", "template", "spec", "containers")
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"name": "backend", "image": "busybox"},
+		map[string]interface{}{"name": "sidecar", "image": "envoy"},
+	}, containers)
+	disk, _, _ := unstructured.NestedString(workload.Object, "spec", "template", "spec", "nodeSelector", "disk")
+	assert.Equal(t, "ssd", disk)
+	// traits with only a patch render no trait object
+	assert.Equal(t, 1, len(ac.Spec.Components[0].Traits))
+	assert.Equal(t, "Placement", ac.Spec.Components[0].Traits[0].Trait.Object.(*unstructured.Unstructured).GetKind())
+
+	_, _, err = render(`name: myapp
+services:
+  backend:
+    type: worker
+    image: busybox
+    node:
+      disk: ssd
+    node2:
+      disk: hdd
+`)
+	assert.EqualError(t, err, "patch workload of service backend failed: traits node and node2 patch spec.template.spec.nodeSelector.disk differently")
+}
+
+func TestRenderValidateTraits(t *testing.T) {
+	templateWorker := `parameter: {
+  image: string
+}
+
+output: {
+  apiVersion: "apps/v1"
+  kind: "Deployment"
+  spec: template: spec: containers: [{
+    name: context.name
+    image: parameter.image
+  }]
+}
+`
+	templateTrait := `parameter: {
+  replicas: *1 | int
+}
+
+output: {
+  apiVersion: "test.oam.dev/v1"
+  kind: "Trait"
+  spec: replicas: parameter.replicas
+}
+`
+	tm := template.NewFakeTemplateManager()
+	tm.Templates["worker"] = &template.Template{Captype: types.TypeWorkload, Raw: templateWorker}
+	tm.Templates["route"] = &template.Template{Captype: types.TypeTrait, Raw: templateTrait, AppliesTo: []string{"webservice"}}
+	tm.Templates["scaler"] = &template.Template{Captype: types.TypeTrait, Raw: templateTrait, ConflictsWith: []string{"autoscale"}}
+	tm.Templates["autoscale"] = &template.Template{Captype: types.TypeTrait, Raw: templateTrait}
+	io := cmdutil.IOStreams{In: os.Stdin, Out: os.Stdout, ErrOut: os.Stderr}
+	render := func(data string) error {
+		app := NewAppFile()
+		app.configGetter = &fakeConfigGetter{}
+		assert.NoError(t, yaml.Unmarshal([]byte(data), app))
+		_, _, _, err := app.RenderOAM("default", io, tm, true)
+		return err
+	}
+
+	assert.NoError(t, render(`name: myapp
+services:
+  backend:
+    type: worker
+    image: busybox
+    scaler:
+      replicas: 2
+`))
+	assert.EqualError(t, render(`name: myapp
+services:
+  backend:
+    type: worker
+    image: busybox
+    route: {}
+    scaler: {}
+    autoscale: {}
+`), "service backend: invalid traits: trait autoscale conflicts with trait scaler; "+
+		"trait route doesn't apply to workload type worker, it applies to webservice")
+}
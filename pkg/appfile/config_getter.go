@@ -1,38 +1,68 @@
 package appfile
 
 import (
-	"bufio"
-	"bytes"
+	"sort"
 
+	"github.com/oam-dev/kubevela/apis/types"
 	"github.com/oam-dev/kubevela/pkg/utils/config"
 	"github.com/oam-dev/kubevela/pkg/utils/env"
 )
 
 type configGetter interface {
 	GetConfigData(configName string) ([]map[string]string, error)
+	// GetEnvName returns the env which configs belong to
+	GetEnvName() (string, error)
+	// GetEnv returns the env which the Appfile is deployed to
+	GetEnv() (*types.EnvMeta, error)
 }
 
-type defaultConfigGetter struct{}
+type defaultConfigGetter struct {
+	// env is the env which the Appfile is deployed to, it's the current env if nil
+	env *types.EnvMeta
+}
 
-func (defaultConfigGetter) GetConfigData(configName string) ([]map[string]string, error) {
+func (g defaultConfigGetter) GetEnv() (*types.EnvMeta, error) {
+	if g.env != nil {
+		return g.env, nil
+	}
 	envName, err := env.GetCurrentEnvName()
 	if err != nil {
 		return nil, err
 	}
-	cfgData, err := config.ReadConfig(envName, configName)
+	return env.GetEnvByName(envName)
+}
+
+func (g defaultConfigGetter) GetEnvName() (string, error) {
+	envMeta, err := g.GetEnv()
+	if err != nil {
+		return "", err
+	}
+	return envMeta.Name, nil
+}
+
+func (g defaultConfigGetter) GetConfigData(configName string) ([]map[string]string, error) {
+	envMeta, err := g.GetEnv()
+	if err != nil {
+		return nil, err
+	}
+	store, err := config.NewStore(envMeta, nil)
 	if err != nil {
 		return nil, err
 	}
-	scanner := bufio.NewScanner(bytes.NewReader(cfgData))
+	cfgData, err := store.Get(configName)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(cfgData))
+	for k := range cfgData {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
 	data := []map[string]string{}
-	for scanner.Scan() {
-		k, v, err := config.ReadConfigLine(scanner.Text())
-		if err != nil {
-			return nil, err
-		}
+	for _, k := range keys {
 		data = append(data, map[string]string{
 			"name":  k,
-			"value": v,
+			"value": cfgData[k],
 		})
 	}
 	return data, nil
@@ -40,8 +70,20 @@ func (defaultConfigGetter) GetConfigData(configName string) ([]map[string]string
 
 type fakeConfigGetter struct {
 	Data []map[string]string
+	Env  *types.EnvMeta
 }
 
 func (f *fakeConfigGetter) GetConfigData(_ string) ([]map[string]string, error) {
 	return f.Data, nil
 }
+
+func (f *fakeConfigGetter) GetEnvName() (string, error) {
+	return "default", nil
+}
+
+func (f *fakeConfigGetter) GetEnv() (*types.EnvMeta, error) {
+	if f.Env != nil {
+		return f.Env, nil
+	}
+	return &types.EnvMeta{Name: "default", Namespace: "default"}, nil
+}
@@ -0,0 +1,92 @@
+package appfile
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// GetServiceLevels groups services by their dependencies: services in the first level depend on nothing,
+// services in level N only depend on services in levels before N. Services in a level are sorted by name.
+// It fails if a service depends on an unknown service or dependencies of services form a cycle.
+func (af *AppFile) GetServiceLevels() ([][]string, error) {
+	deps := make(map[string][]string, len(af.Services))
+	names := make([]string, 0, len(af.Services))
+	for name, svc := range af.Services {
+		d, err := svc.GetDependsOn()
+		if err != nil {
+			return nil, fmt.Errorf("invalid dependsOn in '%s': %w", name, err)
+		}
+		for _, dep := range d {
+			if _, ok := af.Services[dep]; !ok {
+				return nil, fmt.Errorf("service %s depends on unknown service %s", name, dep)
+			}
+		}
+		deps[name] = d
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	levels := make(map[string]int, len(names))
+	var visit func(name string, path []string) (int, error)
+	visit = func(name string, path []string) (int, error) {
+		if l, ok := levels[name]; ok {
+			if l < 0 {
+				return 0, fmt.Errorf("dependency cycle among services: %s", strings.Join(cyclePath(path, name), " -> "))
+			}
+			return l, nil
+		}
+		// mark the service as being visited
+		levels[name] = -1
+		level := 0
+		for _, dep := range deps[name] {
+			l, err := visit(dep, append(path, name))
+			if err != nil {
+				return 0, err
+			}
+			if l+1 > level {
+				level = l + 1
+			}
+		}
+		levels[name] = level
+		return level, nil
+	}
+
+	var result [][]string
+	for _, name := range names {
+		level, err := visit(name, nil)
+		if err != nil {
+			return nil, err
+		}
+		for len(result) <= level {
+			result = append(result, nil)
+		}
+	}
+	for _, name := range names {
+		result[levels[name]] = append(result[levels[name]], name)
+	}
+	return result, nil
+}
+
+// GetServiceOrder returns names of services in the order they're deployed, dependencies go first.
+func (af *AppFile) GetServiceOrder() ([]string, error) {
+	levels, err := af.GetServiceLevels()
+	if err != nil {
+		return nil, err
+	}
+	var order []string
+	for _, level := range levels {
+		order = append(order, level...)
+	}
+	return order, nil
+}
+
+// cyclePath returns the part of path from the first appearance of name, with name appended to close the cycle
+func cyclePath(path []string, name string) []string {
+	for i, p := range path {
+		if p == name {
+			return append(append([]string{}, path[i:]...), name)
+		}
+	}
+	return append(path, name)
+}
@@ -0,0 +1,116 @@
+package appfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetServiceLevels(t *testing.T) {
+	tests := map[string]struct {
+		data   string
+		expect [][]string
+		err    string
+	}{
+		"no dependencies": {
+			data: `
+name: myapp
+services:
+  frontend:
+    image: nginx
+  backend:
+    image: busybox
+`,
+			expect: [][]string{{"backend", "frontend"}},
+		},
+		"dependencies": {
+			data: `
+name: myapp
+services:
+  api:
+    image: api
+    dependsOn: [db, cache]
+  web:
+    image: web
+    dependsOn: [api]
+  worker:
+    image: worker
+    dependsOn: [db]
+  db:
+    image: mysql
+  cache:
+    image: redis
+`,
+			expect: [][]string{{"cache", "db"}, {"api", "worker"}, {"web"}},
+		},
+		"unknown service": {
+			data: `
+name: myapp
+services:
+  api:
+    image: api
+    dependsOn: [db]
+`,
+			err: "service api depends on unknown service db",
+		},
+		"cycle": {
+			data: `
+name: myapp
+services:
+  a:
+    image: a
+    dependsOn: [b]
+  b:
+    image: b
+    dependsOn: [c]
+  c:
+    image: c
+    dependsOn: [a]
+`,
+			err: "dependency cycle among services: a -> b -> c -> a",
+		},
+		"self": {
+			data: `
+name: myapp
+services:
+  a:
+    image: a
+    dependsOn: [a]
+`,
+			err: "dependency cycle among services: a -> a",
+		},
+		"invalid": {
+			data: `
+name: myapp
+services:
+  a:
+    image: a
+    dependsOn: db
+`,
+			err: "invalid dependsOn in 'a': dependsOn must be a list of service names, but got string",
+		},
+	}
+	for key, ca := range tests {
+		af, err := LoadFromBytes([]byte(ca.data))
+		assert.NoError(t, err, key)
+		levels, err := af.GetServiceLevels()
+		if ca.err != "" {
+			assert.EqualError(t, err, ca.err, key)
+			continue
+		}
+		assert.NoError(t, err, key)
+		assert.Equal(t, ca.expect, levels, key)
+	}
+}
+
+func TestGetServiceOrder(t *testing.T) {
+	af := NewAppFile()
+	af.Services = map[string]Service{
+		"web": {"image": "web", "dependsOn": []string{"api"}},
+		"api": {"image": "api"},
+	}
+	order, err := af.GetServiceOrder()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"api", "web"}, order)
+	assert.Equal(t, map[string]interface{}{"image": "web"}, af.Services["web"].GetConfig())
+}
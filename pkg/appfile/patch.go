@@ -0,0 +1,135 @@
+package appfile
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// traitPatch is the `patch` rendered by a trait, it's merged into the workload
+type traitPatch struct {
+	trait string
+	patch map[string]interface{}
+}
+
+// patchOwner records which trait patched a field to which value
+type patchOwner struct {
+	trait string
+	value interface{}
+}
+
+// workloadPatcher merges patches of traits into a workload:
+// - maps are merged recursively
+// - lists whose items are all maps with a `name`, e.g. containers, env and volumes, are merged by the name of items,
+//   items with new names are appended
+// - other values replace the value in the workload
+// A field can't be patched by two traits with different values.
+type workloadPatcher struct {
+	owners map[string]patchOwner
+}
+
+// patchWorkload merges patches into the workload in order
+func patchWorkload(workload map[string]interface{}, patches []traitPatch) error {
+	p := &workloadPatcher{owners: make(map[string]patchOwner)}
+	for _, tp := range patches {
+		if err := p.mergeMap(workload, tp.patch, "", tp.trait); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *workloadPatcher) mergeMap(dst, patch map[string]interface{}, path, trait string) error {
+	keys := make([]string, 0, len(patch))
+	for k := range patch {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fieldPath := k
+		if path != "" {
+			fieldPath = path + "." + k
+		}
+		v, err := p.merge(dst[k], patch[k], fieldPath, trait)
+		if err != nil {
+			return err
+		}
+		dst[k] = v
+	}
+	return nil
+}
+
+// merge merges patch into dst and returns the result
+func (p *workloadPatcher) merge(dst, patch interface{}, path, trait string) (interface{}, error) {
+	switch pv := patch.(type) {
+	case map[string]interface{}:
+		dv, ok := dst.(map[string]interface{})
+		if !ok {
+			if err := p.own(path, trait, map[string]interface{}{}); err != nil {
+				return nil, err
+			}
+			dv = make(map[string]interface{}, len(pv))
+		}
+		return dv, p.mergeMap(dv, pv, path, trait)
+	case []interface{}:
+		dv, ok := dst.([]interface{})
+		if isNamedList(pv) && (dst == nil || ok && isNamedList(dv)) {
+			return p.mergeNamedList(dv, pv, path, trait)
+		}
+	}
+	if err := p.own(path, trait, patch); err != nil {
+		return nil, err
+	}
+	return runtime.DeepCopyJSONValue(patch), nil
+}
+
+func (p *workloadPatcher) mergeNamedList(dst, patch []interface{}, path, trait string) ([]interface{}, error) {
+	for _, item := range patch {
+		pv := item.(map[string]interface{})
+		name := pv["name"].(string)
+		itemPath := fmt.Sprintf("%s[name=%s]", path, name)
+		var dv map[string]interface{}
+		for _, d := range dst {
+			if m := d.(map[string]interface{}); m["name"] == name {
+				dv = m
+				break
+			}
+		}
+		if dv == nil {
+			dv = map[string]interface{}{"name": name}
+			dst = append(dst, dv)
+		}
+		if err := p.mergeMap(dv, pv, itemPath, trait); err != nil {
+			return nil, err
+		}
+	}
+	return dst, nil
+}
+
+// own records that the trait patches the field to value, it fails if another trait patched it to a different value
+func (p *workloadPatcher) own(path, trait string, value interface{}) error {
+	if o, ok := p.owners[path]; ok && o.trait != trait && !reflect.DeepEqual(o.value, value) {
+		return fmt.Errorf("traits %s and %s patch %s differently", o.trait, trait, path)
+	}
+	p.owners[path] = patchOwner{trait: trait, value: value}
+	return nil
+}
+
+// isNamedList checks whether all items of the list are maps with a string `name`
+func isNamedList(l []interface{}) bool {
+	if len(l) == 0 {
+		return false
+	}
+	for _, item := range l {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		if _, ok := m["name"].(string); !ok {
+			return false
+		}
+	}
+	return true
+}
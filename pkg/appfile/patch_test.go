@@ -0,0 +1,74 @@
+package appfile
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func toJSONMap(t *testing.T, data string) map[string]interface{} {
+	m := make(map[string]interface{})
+	assert.NoError(t, json.Unmarshal([]byte(data), &m))
+	return m
+}
+
+func TestPatchWorkload(t *testing.T) {
+	workload := `{"spec":{"replicas":1,"template":{"spec":{"containers":[{"name":"web","image":"nginx","env":[{"name":"A","value":"1"}]}]}}}}`
+	tests := map[string]struct {
+		patches []traitPatch
+		expect  string
+		err     string
+	}{
+		"no patch": {
+			expect: workload,
+		},
+		"merge containers and env by name": {
+			patches: []traitPatch{
+				{trait: "env", patch: toJSONMap(t, `{"spec":{"template":{"spec":{"containers":[{"name":"web","env":[{"name":"A","value":"2"},{"name":"B","value":"3"}]}]}}}}`)},
+				{trait: "sidecar", patch: toJSONMap(t, `{"spec":{"template":{"spec":{"containers":[{"name":"proxy","image":"envoy"}]}}}}`)},
+				{trait: "node", patch: toJSONMap(t, `{"spec":{"template":{"spec":{"nodeSelector":{"disk":"ssd"}}}}}`)},
+			},
+			expect: `{"spec":{"replicas":1,"template":{"spec":{"containers":[
+				{"name":"web","image":"nginx","env":[{"name":"A","value":"2"},{"name":"B","value":"3"}]},
+				{"name":"proxy","image":"envoy"}],"nodeSelector":{"disk":"ssd"}}}}}`,
+		},
+		"lists without names are replaced": {
+			patches: []traitPatch{
+				{trait: "cmd", patch: toJSONMap(t, `{"spec":{"template":{"spec":{"containers":[{"name":"web","command":["sh","-c"]}]}}}}`)},
+			},
+			expect: `{"spec":{"replicas":1,"template":{"spec":{"containers":[{"name":"web","image":"nginx","command":["sh","-c"],"env":[{"name":"A","value":"1"}]}]}}}}`,
+		},
+		"same value from two traits": {
+			patches: []traitPatch{
+				{trait: "a", patch: toJSONMap(t, `{"spec":{"replicas":2}}`)},
+				{trait: "b", patch: toJSONMap(t, `{"spec":{"replicas":2}}`)},
+			},
+			expect: `{"spec":{"replicas":2,"template":{"spec":{"containers":[{"name":"web","image":"nginx","env":[{"name":"A","value":"1"}]}]}}}}`,
+		},
+		"conflict": {
+			patches: []traitPatch{
+				{trait: "a", patch: toJSONMap(t, `{"spec":{"template":{"spec":{"containers":[{"name":"web","env":[{"name":"A","value":"2"}]}]}}}}`)},
+				{trait: "b", patch: toJSONMap(t, `{"spec":{"template":{"spec":{"containers":[{"name":"web","env":[{"name":"A","value":"3"}]}]}}}}`)},
+			},
+			err: "traits a and b patch spec.template.spec.containers[name=web].env[name=A].value differently",
+		},
+		"conflict of a value and a struct": {
+			patches: []traitPatch{
+				{trait: "a", patch: toJSONMap(t, `{"spec":{"strategy":"Recreate"}}`)},
+				{trait: "b", patch: toJSONMap(t, `{"spec":{"strategy":{"type":"RollingUpdate"}}}`)},
+			},
+			err: "traits a and b patch spec.strategy differently",
+		},
+	}
+	for key, ca := range tests {
+		w := toJSONMap(t, workload)
+		err := patchWorkload(w, ca.patches)
+		if ca.err != "" {
+			assert.EqualError(t, err, ca.err, key)
+			continue
+		}
+		assert.NoError(t, err, key)
+		assert.Equal(t, toJSONMap(t, ca.expect), w, key)
+	}
+}
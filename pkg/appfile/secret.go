@@ -0,0 +1,233 @@
+package appfile
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/oam-dev/kubevela/pkg/utils/config"
+)
+
+// Sources of Appfile secrets, a secret is declared as `<source>:<ref>` in the `secrets` section, e.g.
+//   secrets:
+//     db: file:./db-password
+//     token: env:API_TOKEN
+//     registry: config:registry
+const (
+	// SecretSourceFile reads a local file, the key in Secret is the file name
+	SecretSourceFile = "file"
+	// SecretSourceEnv reads an environment variable, the key in Secret is the variable name
+	SecretSourceEnv = "env"
+	// SecretSourceConfig reads a config created by `vela config set`, every item of the config is a key in Secret
+	SecretSourceConfig = "config"
+)
+
+// LabelAppfileSecret marks Secrets rendered from Appfile, so they can be cleaned up along with the app
+const LabelAppfileSecret = "app.oam.dev/appfile-secret"
+
+// AnnotationConfigHash is the hash of config data, it's also set on pod templates so workloads roll when config changes
+const AnnotationConfigHash = "config.oam.dev/hash"
+
+// redactedValue replaces secret values when rendered objects are shown to users
+const redactedValue = "<redacted>"
+
+// FormatSecretName returns the name of Secret rendered from a secret of the app
+func FormatSecretName(appName, secretName string) string {
+	return appName + "-" + secretName
+}
+
+// FormatConfigSecretName returns the name of Secret which a config of env is synced into
+func FormatConfigSecretName(envName, configName string) string {
+	return "vela-config-" + envName + "-" + configName
+}
+
+// renderConfigSecret syncs a config of the current env into a Secret, so services using it refer to the Secret
+// instead of having the values in plaintext
+func (app *AppFile) renderConfigSecret(configName, ns string) (*corev1.Secret, error) {
+	envName, err := app.configGetter.GetEnvName()
+	if err != nil {
+		return nil, err
+	}
+	items, err := app.configGetter.GetConfigData(configName)
+	if err != nil {
+		return nil, err
+	}
+	data := make(map[string][]byte, len(items))
+	for _, item := range items {
+		data[item["name"]] = []byte(item["value"])
+	}
+	// json sorts keys of map, so the hash is stable
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      FormatConfigSecretName(envName, configName),
+			Namespace: ns,
+			Labels: map[string]string{
+				config.LabelConfigName: configName,
+				config.LabelConfigEnv:  envName,
+			},
+			Annotations: map[string]string{
+				AnnotationConfigHash: fmt.Sprintf("%x", sha256.Sum256(b))[:16],
+			},
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: data,
+	}, nil
+}
+
+// configContext returns `context.config` and `context.configRef` of the config Secret. Each item of `context.config`
+// is an env var referring to a key of the Secret, `context.configRef` has the name, keys and hash of the Secret.
+func configContext(secret *corev1.Secret) ([]interface{}, map[string]interface{}) {
+	keys := make([]string, 0, len(secret.Data))
+	for k := range secret.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	config := make([]interface{}, 0, len(keys))
+	for _, k := range keys {
+		config = append(config, map[string]interface{}{
+			"name": k,
+			"valueFrom": map[string]interface{}{
+				"secretKeyRef": map[string]interface{}{
+					"name": secret.Name,
+					"key":  k,
+				},
+			},
+		})
+	}
+	return config, map[string]interface{}{
+		"name": secret.Name,
+		"keys": keys,
+		"hash": secret.Annotations[AnnotationConfigHash],
+	}
+}
+
+// renderSecrets renders the secrets section into Secret objects, it also returns `context.secrets` for CUE templates,
+// which only has the name and keys of each Secret, values never go into templates.
+func (app *AppFile) renderSecrets(ns string) ([]*corev1.Secret, map[string]interface{}, error) {
+	names := make([]string, 0, len(app.Secrets))
+	for name := range app.Secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var secrets []*corev1.Secret
+	ctxSecrets := make(map[string]interface{}, len(names))
+	for _, name := range names {
+		data, err := app.readSecret(name, app.Secrets[name])
+		if err != nil {
+			return nil, nil, err
+		}
+		secret := &corev1.Secret{
+			TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      FormatSecretName(app.Name, name),
+				Namespace: ns,
+				Labels: map[string]string{
+					oam.LabelAppName:   app.Name,
+					LabelAppfileSecret: name,
+				},
+			},
+			Type: corev1.SecretTypeOpaque,
+			Data: data,
+		}
+		keys := make([]string, 0, len(data))
+		for k := range data {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		secrets = append(secrets, secret)
+		ctxSecrets[name] = map[string]interface{}{
+			"name": secret.Name,
+			"keys": keys,
+		}
+	}
+	return secrets, ctxSecrets, nil
+}
+
+func (app *AppFile) readSecret(name, source string) (map[string][]byte, error) {
+	ss := strings.SplitN(source, ":", 2)
+	if len(ss) != 2 || ss[1] == "" {
+		return nil, fmt.Errorf("secret %s must be in format <source>:<ref> where source is one of %s, %s and %s, but got %q",
+			name, SecretSourceFile, SecretSourceEnv, SecretSourceConfig, source)
+	}
+	ref := ss[1]
+	if app.localSourcesDisallowed && (ss[0] == SecretSourceFile || ss[0] == SecretSourceEnv) {
+		return nil, fmt.Errorf("secret %s: %s sources are only allowed when deploying from the CLI", name, ss[0])
+	}
+	switch ss[0] {
+	case SecretSourceFile:
+		b, err := ioutil.ReadFile(filepath.Clean(ref))
+		if err != nil {
+			return nil, fmt.Errorf("read secret %s from file err %w", name, err)
+		}
+		return map[string][]byte{filepath.Base(ref): b}, nil
+	case SecretSourceEnv:
+		v, ok := os.LookupEnv(ref)
+		if !ok {
+			return nil, fmt.Errorf("environment variable %s of secret %s is not set", ref, name)
+		}
+		return map[string][]byte{ref: []byte(v)}, nil
+	case SecretSourceConfig:
+		items, err := app.configGetter.GetConfigData(ref)
+		if err != nil {
+			return nil, fmt.Errorf("read secret %s from config %s err %w", name, ref, err)
+		}
+		data := make(map[string][]byte, len(items))
+		for _, item := range items {
+			data[item["name"]] = []byte(item["value"])
+		}
+		return data, nil
+	default:
+		return nil, fmt.Errorf("unknown source %s of secret %s, must be one of %s, %s and %s",
+			ss[0], name, SecretSourceFile, SecretSourceEnv, SecretSourceConfig)
+	}
+}
+
+// resolveSecretFiles makes relative paths of `file:` secrets relative to dir, the directory of the Appfile, rather
+// than the working directory, they are kept absolute when the Appfile is saved
+func (app *AppFile) resolveSecretFiles(dir string) {
+	for name, source := range app.Secrets {
+		ss := strings.SplitN(source, ":", 2)
+		if len(ss) != 2 || ss[0] != SecretSourceFile || ss[1] == "" || filepath.IsAbs(ss[1]) {
+			continue
+		}
+		if abs, err := filepath.Abs(filepath.Join(dir, ss[1])); err == nil {
+			app.Secrets[name] = SecretSourceFile + ":" + abs
+		}
+	}
+}
+
+// RedactSecrets returns a copy of objs where values of Secrets are masked, use it before printing rendered objects
+func RedactSecrets(objs []oam.Object) []oam.Object {
+	redacted := make([]oam.Object, 0, len(objs))
+	for _, obj := range objs {
+		secret, ok := obj.(*corev1.Secret)
+		if !ok {
+			redacted = append(redacted, obj)
+			continue
+		}
+		secret = secret.DeepCopy()
+		for k := range secret.Data {
+			secret.Data[k] = []byte(redactedValue)
+		}
+		for k := range secret.StringData {
+			secret.StringData[k] = redactedValue
+		}
+		redacted = append(redacted, secret)
+	}
+	return redacted
+}
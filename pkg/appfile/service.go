@@ -4,17 +4,21 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
 
+	"github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
 	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam"
 
 	"cuelang.org/go/cue"
 	cueJson "cuelang.org/go/pkg/encoding/json"
 	"github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 
 	"github.com/oam-dev/kubevela/pkg/appfile/template"
+	"github.com/oam-dev/kubevela/pkg/controller/utils"
 	mycue "github.com/oam-dev/kubevela/pkg/cue"
 )
 
@@ -42,13 +46,58 @@ func (s Service) GetUserConfigName() string {
 	return t.(string)
 }
 
-// GetConfig will get OAM workload and trait information exclude inner section('build','type' and 'config')
+// GetScopes get scopes from AppFile, the key is the scope type and the value is the name of the scope object.
+func (s Service) GetScopes() (map[string]string, error) {
+	t, ok := s["scopes"]
+	if !ok {
+		return nil, nil
+	}
+	raw, ok := t.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("scopes must be a map of scope type to scope name, but got %T", t)
+	}
+	scopes := make(map[string]string, len(raw))
+	for scopeType, v := range raw {
+		scopeName, ok := v.(string)
+		if !ok || scopeName == "" {
+			return nil, fmt.Errorf("name of scope %s must be a non-empty string", scopeType)
+		}
+		scopes[scopeType] = scopeName
+	}
+	return scopes, nil
+}
+
+// GetDependsOn get names of services which must be healthy before the service is deployed
+func (s Service) GetDependsOn() ([]string, error) {
+	t, ok := s["dependsOn"]
+	if !ok {
+		return nil, nil
+	}
+	switch v := t.(type) {
+	case []string:
+		return v, nil
+	case []interface{}:
+		deps := make([]string, 0, len(v))
+		for _, d := range v {
+			dep, ok := d.(string)
+			if !ok || dep == "" {
+				return nil, fmt.Errorf("dependsOn must be a list of service names, but got %v", d)
+			}
+			deps = append(deps, dep)
+		}
+		return deps, nil
+	default:
+		return nil, fmt.Errorf("dependsOn must be a list of service names, but got %T", t)
+	}
+}
+
+// GetConfig will get OAM workload and trait information exclude inner section('build','type','config','scopes' and 'dependsOn')
 func (s Service) GetConfig() map[string]interface{} {
 	config := make(map[string]interface{})
 outerLoop:
 	for k, v := range s {
 		switch k {
-		case "build", "type", "config": // skip
+		case "build", "type", "config", "scopes", "dependsOn": // skip
 			continue outerLoop
 		}
 		config[k] = v
@@ -74,9 +123,27 @@ func (s Service) GetBuild() *Build {
 	return build
 }
 
+// RenderContext is the app and env which a service is rendered in, it's exposed to templates as `context`.
+type RenderContext struct {
+	AppName   string
+	Namespace string
+	EnvName   string
+	// Host is generated from the domain of env for routes without an explicit host
+	Host string
+	// Issuer, IssuerType and RouteProvider are from env for routes without an explicit issuer and provider
+	Issuer        string
+	IssuerType    string
+	RouteProvider string
+	// ConfigSecret is the Secret which the config of the service is synced into, it's nil if the service has no config
+	ConfigSecret *corev1.Secret
+	// Secrets are names and keys of Secrets rendered from the Appfile
+	Secrets map[string]interface{}
+}
+
 // RenderService render all capabilities of a service to CUE values of a Component.
 // It outputs a Component which will be marshaled as standalone Component and also returned AppConfig Component section.
-func (s Service) RenderService(tm template.Manager, name, ns string, cg configGetter) (*v1alpha2.ApplicationConfigurationComponent, *v1alpha2.Component, error) {
+// Templates of traits get the rendered workload as `context.output` besides what's in rc, see mycue.BaseTemplate.
+func (s Service) RenderService(tm template.Manager, name string, rc RenderContext) (*v1alpha2.ApplicationConfigurationComponent, *v1alpha2.Component, error) {
 
 	// sort out configs by workload/trait
 	workloadKeys := map[string]interface{}{}
@@ -96,36 +163,70 @@ func (s Service) RenderService(tm template.Manager, name, ns string, cg configGe
 	component := &v1alpha2.Component{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
-			Namespace: ns,
+			Namespace: rc.Namespace,
 		},
 	}
 
 	ctxData := map[string]interface{}{
-		"name": name,
+		"name":      name,
+		"appName":   rc.AppName,
+		"namespace": rc.Namespace,
 	}
-	if cn := s.GetUserConfigName(); cn != "" {
-		data, err := cg.GetConfigData(cn)
-		if err != nil {
-			return nil, nil, err
+	if rc.EnvName != "" {
+		ctxData["envName"] = rc.EnvName
+	}
+	if rc.Host != "" {
+		ctxData["host"] = rc.Host
+	}
+	if rc.Issuer != "" {
+		ctxData["issuer"] = rc.Issuer
+		if rc.IssuerType != "" {
+			ctxData["issuerType"] = rc.IssuerType
 		}
-		ctxData["config"] = data
+	}
+	if rc.RouteProvider != "" {
+		ctxData["routeProvider"] = rc.RouteProvider
+	}
+	if rc.ConfigSecret != nil {
+		ctxData["config"], ctxData["configRef"] = configContext(rc.ConfigSecret)
+	}
+	if len(rc.Secrets) != 0 {
+		ctxData["secrets"] = rc.Secrets
 	}
 	u, err := evalComponent(tm, wtype, ctxData, intifyValues(workloadKeys))
 	if err != nil {
 		return nil, nil, fmt.Errorf("eval service failed: %w", err)
 	}
 	component.Spec.Workload.Object = u
+	addOutputContext(ctxData, u)
 
-	// render traits
+	// render traits in the order of their types, so patches of traits are applied in a deterministic order
+	traitTypes := make([]string, 0, len(traitKeys))
+	for traitType := range traitKeys {
+		traitTypes = append(traitTypes, traitType)
+	}
+	sort.Strings(traitTypes)
+	if err := tm.ValidateTraits(wtype, traitTypes); err != nil {
+		return nil, nil, fmt.Errorf("service %s: %w", name, err)
+	}
 	traits := make([]v1alpha2.ComponentTrait, 0)
-	for traitType, traitData := range traitKeys {
-		ts, err := evalTraits(tm.LoadTemplate(traitType), ctxData, intifyValues(traitData))
+	var patches []traitPatch
+	for _, traitType := range traitTypes {
+		ts, patch, err := evalTraits(tm.LoadTemplate(traitType), ctxData, intifyValues(traitKeys[traitType]))
 		if err != nil {
 			return nil, nil, fmt.Errorf("eval traits failed: %w", err)
 		}
+		if patch != nil {
+			patches = append(patches, traitPatch{trait: traitType, patch: patch})
+		}
 		// one capability corresponds to one trait only
 		if len(ts) == 1 {
-			ts[0].SetLabels(map[string]string{oam.TraitTypeLabel: traitType})
+			labels := ts[0].GetLabels()
+			if labels == nil {
+				labels = make(map[string]string)
+			}
+			labels[oam.TraitTypeLabel] = traitType
+			ts[0].SetLabels(labels)
 		}
 		for _, t := range ts {
 			traits = append(traits, v1alpha2.ComponentTrait{
@@ -136,13 +237,78 @@ func (s Service) RenderService(tm template.Manager, name, ns string, cg configGe
 		}
 	}
 
+	if err := patchWorkload(u.Object, patches); err != nil {
+		return nil, nil, fmt.Errorf("patch workload of service %s failed: %w", name, err)
+	}
+
+	scopes, err := s.renderScopes(tm)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	acComp := &v1alpha2.ApplicationConfigurationComponent{
 		ComponentName: component.Name,
 		Traits:        traits,
+		Scopes:        scopes,
 	}
 	return acComp, component, nil
 }
 
+// addOutputContext exposes the rendered workload to templates of traits, along with ports and labels of its pods
+// if the workload has a pod template in `spec.template`.
+func addOutputContext(ctxData map[string]interface{}, workload *unstructured.Unstructured) {
+	ctxData["output"] = intifyValues(workload.Object)
+	ports, labels, err := utils.DiscoveryFromPodTemplate(workload, "spec", "template")
+	if err != nil {
+		return
+	}
+	var containerPorts []interface{}
+	for _, p := range ports {
+		containerPorts = append(containerPorts, p.IntValue())
+	}
+	ctxData["ports"] = containerPorts
+	if len(labels) != 0 {
+		podLabels := make(map[string]interface{}, len(labels))
+		for k, v := range labels {
+			podLabels[k] = v
+		}
+		ctxData["labels"] = podLabels
+	}
+}
+
+// renderScopes converts scopes of a service into scope references of the AppConfig Component,
+// the scope objects themselves must already exist in the namespace.
+func (s Service) renderScopes(tm template.Manager) ([]v1alpha2.ComponentScope, error) {
+	scopes, err := s.GetScopes()
+	if err != nil {
+		return nil, err
+	}
+	scopeTypes := make([]string, 0, len(scopes))
+	for scopeType := range scopes {
+		scopeTypes = append(scopeTypes, scopeType)
+	}
+	sort.Strings(scopeTypes)
+
+	var refs []v1alpha2.ComponentScope
+	for _, scopeType := range scopeTypes {
+		if !tm.IsScope(scopeType) {
+			return nil, fmt.Errorf("scope %s is not installed", scopeType)
+		}
+		info := tm.LoadCRDInfo(scopeType)
+		if info == nil {
+			return nil, fmt.Errorf("scope %s was not ready, its CRD info is missing", scopeType)
+		}
+		refs = append(refs, v1alpha2.ComponentScope{
+			ScopeReference: v1alpha1.TypedReference{
+				APIVersion: info.APIVersion,
+				Kind:       info.Kind,
+				Name:       scopes[scopeType],
+			},
+		})
+	}
+	return refs, nil
+}
+
 // GetServices will get all services defined in AppFile
 func (af *AppFile) GetServices() map[string]Service {
 	return af.Services
@@ -258,23 +424,40 @@ func evalComponent(tm template.Manager, wtype string, ctxValues, userValues inte
 	return renderOneOutput(appValue)
 }
 
-func evalTraits(raw string, ctxValues, userValues interface{}) ([]*unstructured.Unstructured, error) {
+// evalTraits renders objects of the trait from `output` or `outputs`, and the patch to the workload from `patch`.
+// A trait must render at least one of them.
+func evalTraits(raw string, ctxValues, userValues interface{}) ([]*unstructured.Unstructured, map[string]interface{}, error) {
 	appValue, err := getValueStruct(raw, ctxValues, userValues)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	var patch map[string]interface{}
+	if patchField, err := appValue.FieldByName("patch", true); err == nil {
+		data, err := cueJson.Marshal(patchField.Value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("marshal patch failed: %w", err)
+		}
+		if err := json.Unmarshal([]byte(data), &patch); err != nil {
+			return nil, nil, fmt.Errorf("patch must be a struct: %w", err)
+		}
 	}
 
 	_, err = appValue.FieldByName("output", true)
 	if err != nil {
 		outputField, err := appValue.FieldByName("outputs", true)
 		if err != nil {
-			return nil, errors.New("both output and outputs fields not found")
+			if patch != nil {
+				return nil, patch, nil
+			}
+			return nil, nil, errors.New("none of output, outputs and patch fields found")
 		}
-		return renderAllOutputs(outputField)
+		us, err := renderAllOutputs(outputField)
+		return us, patch, err
 	}
 	u, err := renderOneOutput(appValue)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	return []*unstructured.Unstructured{u}, nil
+	return []*unstructured.Unstructured{u}, patch, nil
 }
@@ -0,0 +1,167 @@
+package template
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam/discoverymapper"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam/util"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+// LoadFromCluster creates a manager from WorkloadDefinitions, TraitDefinitions and ScopeDefinitions in the cluster.
+// Unlike Load, it doesn't sync templates into local capability dir nor install dependencies of definitions,
+// so it can be used by controllers. Definitions in namespace take precedence over definitions with the same name
+// in other namespaces. Definitions which are not ready are skipped and returned as template errors.
+func LoadFromCluster(ctx context.Context, c client.Reader, dm discoverymapper.DiscoveryMapper, namespace string) (Manager, []error, error) {
+	return loadFromCluster(ctx, c, dm, namespace, true)
+}
+
+// LoadDefinitionsFromCluster is LoadFromCluster without downloading templates from templateURI of definitions,
+// templates in the manager may be empty. It's enough to validate traits, which only needs AppliesTo, ConflictsWith
+// and CrdInfo of definitions, e.g. in admission webhooks which must answer quickly.
+func LoadDefinitionsFromCluster(ctx context.Context, c client.Reader, dm discoverymapper.DiscoveryMapper, namespace string) (Manager, []error, error) {
+	return loadFromCluster(ctx, c, dm, namespace, false)
+}
+
+func loadFromCluster(ctx context.Context, c client.Reader, dm discoverymapper.DiscoveryMapper, namespace string,
+	downloadTemplates bool) (Manager, []error, error) {
+	m := newManager()
+	var templateErrors []error
+	// namespace of the definition which each template comes from
+	from := make(map[string]string)
+	add := func(name, ns string, t *Template) {
+		if _, ok := m.Templates[name]; ok && from[name] == namespace {
+			return
+		}
+		m.Templates[name] = t
+		from[name] = ns
+	}
+
+	var workloadDefs v1alpha2.WorkloadDefinitionList
+	if err := c.List(ctx, &workloadDefs); err != nil {
+		return nil, nil, fmt.Errorf("list WorkloadDefinition err: %w", err)
+	}
+	for _, wd := range workloadDefs.Items {
+		t, err := definitionTemplate(ctx, dm, &wd, wd.Spec.Reference, wd.Spec.Extension, types.TypeWorkload, downloadTemplates)
+		if err != nil {
+			templateErrors = append(templateErrors, err)
+			continue
+		}
+		add(wd.Name, wd.Namespace, t)
+	}
+
+	var traitDefs v1alpha2.TraitDefinitionList
+	if err := c.List(ctx, &traitDefs); err != nil {
+		return nil, nil, fmt.Errorf("list TraitDefinition err: %w", err)
+	}
+	for _, td := range traitDefs.Items {
+		t, err := definitionTemplate(ctx, dm, &td, td.Spec.Reference, td.Spec.Extension, types.TypeTrait, downloadTemplates)
+		if err != nil {
+			templateErrors = append(templateErrors, err)
+			continue
+		}
+		t.AppliesTo = td.Spec.AppliesToWorkloads
+		add(td.Name, td.Namespace, t)
+	}
+
+	var scopeDefs v1alpha2.ScopeDefinitionList
+	if err := c.List(ctx, &scopeDefs); err != nil {
+		return nil, nil, fmt.Errorf("list ScopeDefinition err: %w", err)
+	}
+	for _, sd := range scopeDefs.Items {
+		gvk, err := util.GetGVKFromDefinition(dm, sd.Spec.Reference)
+		if err != nil {
+			templateErrors = append(templateErrors, fmt.Errorf("capability '%s' was not ready: %w", sd.Name, err))
+			continue
+		}
+		add(sd.Name, sd.Namespace, &Template{
+			Captype: types.TypeScope,
+			CrdInfo: &types.CRDInfo{APIVersion: gvk.GroupVersion().String(), Kind: gvk.Kind},
+		})
+	}
+	return m, templateErrors, nil
+}
+
+// remoteTemplates caches templates downloaded from templateURI of definitions, controllers load templates on every
+// reconcile, a template is only downloaded again when the generation of its definition changes
+var remoteTemplates = &templateCache{entries: make(map[string]cachedTemplate)}
+
+type cachedTemplate struct {
+	generation int64
+	uri        string
+	raw        string
+}
+
+type templateCache struct {
+	sync.Mutex
+	entries map[string]cachedTemplate
+}
+
+func (c *templateCache) get(key string, generation int64, uri string) (string, bool) {
+	c.Lock()
+	defer c.Unlock()
+	e, ok := c.entries[key]
+	if !ok || e.generation != generation || e.uri != uri {
+		return "", false
+	}
+	return e.raw, true
+}
+
+func (c *templateCache) set(key string, generation int64, uri, raw string) {
+	c.Lock()
+	defer c.Unlock()
+	c.entries[key] = cachedTemplate{generation: generation, uri: uri, raw: raw}
+}
+
+// remoteTemplate returns the template at uri of the definition, it's downloaded only if not cached for the
+// current generation of the definition
+func remoteTemplate(ctx context.Context, def metav1.Object, tp types.CapType, uri string) (string, error) {
+	key := fmt.Sprintf("%s/%s/%s", tp, def.GetNamespace(), def.GetName())
+	if raw, ok := remoteTemplates.get(key, def.GetGeneration(), uri); ok {
+		return raw, nil
+	}
+	b, err := common.HTTPGet(ctx, uri)
+	if err != nil {
+		return "", err
+	}
+	remoteTemplates.set(key, def.GetGeneration(), uri, string(b))
+	return string(b), nil
+}
+
+// definitionTemplate reads the CUE template from extension of a definition, the template at templateURI is only
+// downloaded if download is true. The template is empty if the definition has none, services using it fail to render
+func definitionTemplate(ctx context.Context, dm discoverymapper.DiscoveryMapper, def metav1.Object, ref v1alpha2.DefinitionReference,
+	extension *runtime.RawExtension, tp types.CapType, download bool) (*Template, error) {
+	name := def.GetName()
+	t := &Template{Captype: tp}
+	if extension != nil && extension.Raw != nil {
+		capability, err := types.ConvertTemplateJSON2Object(extension)
+		if err != nil {
+			return nil, fmt.Errorf("handle template of capability '%s' failed: %w", name, err)
+		}
+		t.Raw = capability.CueTemplate
+		t.ConflictsWith = capability.ConflictsWith
+		if download && t.Raw == "" && capability.CueTemplateURI != "" {
+			raw, err := remoteTemplate(ctx, def, tp, capability.CueTemplateURI)
+			if err != nil {
+				return nil, fmt.Errorf("get template of capability '%s' failed: %w", name, err)
+			}
+			t.Raw = raw
+		}
+	}
+	gvk, err := util.GetGVKFromDefinition(dm, ref)
+	if err != nil {
+		return nil, fmt.Errorf("capability '%s' was not ready: %w", name, err)
+	}
+	t.CrdInfo = &types.CRDInfo{APIVersion: gvk.GroupVersion().String(), Kind: gvk.Kind}
+	t.CrdName = ref.Name
+	return t, nil
+}
@@ -0,0 +1,70 @@
+package template
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam/mock"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+func TestLoadFromClusterCachesRemoteTemplates(t *testing.T) {
+	ctx := context.Background()
+	var downloads int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		downloads++
+		fmt.Fprintf(w, "output: {} // %d", downloads)
+	}))
+	defer srv.Close()
+
+	ext, err := json.Marshal(map[string]string{"templateURI": srv.URL})
+	assert.NoError(t, err)
+	wd := &v1alpha2.WorkloadDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "remote", Namespace: "vela-system", Generation: 1},
+		Spec: v1alpha2.WorkloadDefinitionSpec{
+			Reference: v1alpha2.DefinitionReference{Name: "deployments.apps"},
+			Extension: &runtime.RawExtension{Raw: ext},
+		},
+	}
+	c := fake.NewFakeClientWithScheme(common.Scheme, wd)
+	dm := mock.NewMockDiscoveryMapper()
+	dm.MockKindsFor = func(gvr schema.GroupVersionResource) ([]schema.GroupVersionKind, error) {
+		return []schema.GroupVersionKind{{Group: gvr.Group, Version: "v1", Kind: "Deployment"}}, nil
+	}
+
+	for i := 0; i < 2; i++ {
+		m, templateErrors, err := LoadFromCluster(ctx, c, dm, "default")
+		assert.NoError(t, err)
+		assert.Empty(t, templateErrors)
+		assert.Equal(t, "output: {} // 1", m.LoadTemplate("remote"))
+	}
+	assert.Equal(t, 1, downloads)
+
+	// templates are not downloaded if only definitions are needed
+	m, _, err := LoadDefinitionsFromCluster(ctx, c, dm, "default")
+	assert.NoError(t, err)
+	assert.Equal(t, "", m.LoadTemplate("remote"))
+	assert.Equal(t, "Deployment", m.LoadCRDInfo("remote").Kind)
+	assert.Equal(t, 1, downloads)
+
+	// a new generation of the definition downloads the template again
+	assert.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: "vela-system", Name: "remote"}, wd))
+	wd.Generation = 2
+	assert.NoError(t, c.Update(ctx, wd))
+	m, _, err = LoadFromCluster(ctx, c, dm, "default")
+	assert.NoError(t, err)
+	assert.Equal(t, "output: {} // 2", m.LoadTemplate("remote"))
+	assert.Equal(t, 2, downloads)
+}
@@ -8,7 +8,13 @@ import (
 // Manager defines a manager for template
 type Manager interface {
 	IsTrait(key string) bool
+	IsScope(key string) bool
 	LoadTemplate(key string) (tmpl string)
+	LoadCRDInfo(key string) *types.CRDInfo
+	// MatchTypes returns sorted names of capabilities of the type whose CRD is the apiVersion and kind
+	MatchTypes(captype types.CapType, apiVersion, kind string) []string
+	// ValidateTraits checks that all traits apply to the workload type and don't conflict with each other
+	ValidateTraits(workloadType string, traits []string) error
 }
 
 // Load will load all installed capabilities and create a manager
@@ -22,6 +28,10 @@ func Load() (Manager, error) {
 		t := &Template{}
 		t.Captype = cap.Type
 		t.Raw = cap.CueTemplate
+		t.CrdInfo = cap.CrdInfo
+		t.CrdName = cap.CrdName
+		t.AppliesTo = cap.AppliesTo
+		t.ConflictsWith = cap.ConflictsWith
 		m.Templates[cap.Name] = t
 	}
 	return m, nil
@@ -31,6 +41,13 @@ func Load() (Manager, error) {
 type Template struct {
 	Captype types.CapType
 	Raw     string
+	CrdInfo *types.CRDInfo
+	CrdName string
+
+	// AppliesTo are workloads which a trait applies to, a trait applies to all workloads if it's empty
+	AppliesTo []string
+	// ConflictsWith are traits which can't be applied to a service along with a trait
+	ConflictsWith []string
 }
 
 type manager struct {
@@ -51,6 +68,14 @@ func (m *manager) IsTrait(key string) bool {
 	return t.Captype == types.TypeTrait
 }
 
+func (m *manager) IsScope(key string) bool {
+	t, ok := m.Templates[key]
+	if !ok {
+		return false
+	}
+	return t.Captype == types.TypeScope
+}
+
 func (m *manager) LoadTemplate(key string) string {
 	t, ok := m.Templates[key]
 	if !ok {
@@ -58,3 +83,11 @@ func (m *manager) LoadTemplate(key string) string {
 	}
 	return t.Raw
 }
+
+func (m *manager) LoadCRDInfo(key string) *types.CRDInfo {
+	t, ok := m.Templates[key]
+	if !ok {
+		return nil
+	}
+	return t.CrdInfo
+}
@@ -0,0 +1,90 @@
+package template
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/oam-dev/kubevela/apis/types"
+)
+
+// MatchTypes returns sorted names of capabilities of the type whose CRD is the apiVersion and kind
+func (m *manager) MatchTypes(captype types.CapType, apiVersion, kind string) []string {
+	var names []string
+	for name, t := range m.Templates {
+		if t.Captype == captype && t.CrdInfo != nil && t.CrdInfo.APIVersion == apiVersion && t.CrdInfo.Kind == kind {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ValidateTraits checks that all traits apply to the workload type and don't conflict with each other, the
+// appliesTo of traits is not checked if workloadType is empty. Unknown traits are skipped.
+func (m *manager) ValidateTraits(workloadType string, traits []string) error {
+	sorted := append([]string{}, traits...)
+	sort.Strings(sorted)
+	var errs []string
+	for i, trait := range sorted {
+		t, ok := m.Templates[trait]
+		if !ok {
+			continue
+		}
+		if workloadType != "" && !m.appliesTo(t.AppliesTo, workloadType) {
+			errs = append(errs, fmt.Sprintf("trait %s doesn't apply to workload type %s, it applies to %s",
+				trait, workloadType, strings.Join(t.AppliesTo, ", ")))
+		}
+		for _, other := range sorted[i+1:] {
+			if m.conflicts(trait, other) {
+				errs = append(errs, fmt.Sprintf("trait %s conflicts with trait %s", trait, other))
+			}
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("invalid traits: %s", strings.Join(errs, "; "))
+}
+
+// appliesTo checks whether the workload type matches any item of appliesTo, which could be the name of a workload type,
+// the name of its CRD, e.g. deployments.apps, or its kind in format of `group/version.Kind` or `kind.group/version`.
+func (m *manager) appliesTo(appliesTo []string, workloadType string) bool {
+	if len(appliesTo) == 0 {
+		return true
+	}
+	w := m.Templates[workloadType]
+	for _, a := range appliesTo {
+		if a == "*" || a == workloadType {
+			return true
+		}
+		if w == nil {
+			continue
+		}
+		if w.CrdName != "" && a == w.CrdName {
+			return true
+		}
+		if info := w.CrdInfo; info != nil && (strings.EqualFold(a, info.APIVersion+"."+info.Kind) ||
+			strings.EqualFold(a, info.Kind+"."+info.APIVersion)) {
+			return true
+		}
+	}
+	return false
+}
+
+// conflicts checks whether any of the two traits declares a conflict with the other
+func (m *manager) conflicts(a, b string) bool {
+	declares := func(trait, other string) bool {
+		t, ok := m.Templates[trait]
+		if !ok {
+			return false
+		}
+		for _, c := range t.ConflictsWith {
+			if c == other {
+				return true
+			}
+		}
+		return false
+	}
+	return declares(a, b) || declares(b, a)
+}
@@ -0,0 +1,62 @@
+package template
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/oam-dev/kubevela/apis/types"
+)
+
+func TestValidateTraits(t *testing.T) {
+	m := newManager()
+	m.Templates["webservice"] = &Template{Captype: types.TypeWorkload, CrdName: "deployments.apps",
+		CrdInfo: &types.CRDInfo{APIVersion: "apps/v1", Kind: "Deployment"}}
+	m.Templates["worker"] = &Template{Captype: types.TypeWorkload, CrdName: "deployments.apps",
+		CrdInfo: &types.CRDInfo{APIVersion: "apps/v1", Kind: "Deployment"}}
+	m.Templates["task"] = &Template{Captype: types.TypeWorkload, CrdName: "jobs.batch",
+		CrdInfo: &types.CRDInfo{APIVersion: "batch/v1", Kind: "Job"}}
+	m.Templates["route"] = &Template{Captype: types.TypeTrait, AppliesTo: []string{"webservice"}}
+	m.Templates["rollout"] = &Template{Captype: types.TypeTrait, AppliesTo: []string{"deployments.apps"}}
+	m.Templates["backup"] = &Template{Captype: types.TypeTrait, AppliesTo: []string{"batch/v1.Job"}}
+	m.Templates["anything"] = &Template{Captype: types.TypeTrait, AppliesTo: []string{"*"}}
+	m.Templates["scaler"] = &Template{Captype: types.TypeTrait, ConflictsWith: []string{"autoscale"},
+		CrdInfo: &types.CRDInfo{APIVersion: "core.oam.dev/v1alpha2", Kind: "ManualScalerTrait"}}
+	m.Templates["autoscale"] = &Template{Captype: types.TypeTrait}
+
+	cases := map[string]struct {
+		workloadType string
+		traits       []string
+		err          string
+	}{
+		"no traits":           {workloadType: "worker"},
+		"applies by name":     {workloadType: "webservice", traits: []string{"route"}},
+		"applies by CRD name": {workloadType: "worker", traits: []string{"rollout"}},
+		"applies by kind":     {workloadType: "task", traits: []string{"backup", "anything"}},
+		"applies to all":      {workloadType: "worker", traits: []string{"scaler", "anything"}},
+		"unknown workload":    {workloadType: "unknown", traits: []string{"scaler"}},
+		"unknown trait":       {workloadType: "worker", traits: []string{"unknown"}},
+		"not applies": {workloadType: "worker", traits: []string{"route"},
+			err: "invalid traits: trait route doesn't apply to workload type worker, it applies to webservice"},
+		"not applies to kind": {workloadType: "task", traits: []string{"rollout"},
+			err: "invalid traits: trait rollout doesn't apply to workload type task, it applies to deployments.apps"},
+		"skip appliesTo without workload type": {traits: []string{"route", "backup"}},
+		"conflicts": {workloadType: "worker", traits: []string{"scaler", "autoscale"},
+			err: "invalid traits: trait autoscale conflicts with trait scaler"},
+		"multiple errors": {workloadType: "task", traits: []string{"scaler", "route", "autoscale"},
+			err: "invalid traits: trait autoscale conflicts with trait scaler; " +
+				"trait route doesn't apply to workload type task, it applies to webservice"},
+	}
+	for name, c := range cases {
+		err := m.ValidateTraits(c.workloadType, c.traits)
+		if c.err == "" {
+			assert.NoError(t, err, name)
+			continue
+		}
+		assert.EqualError(t, err, c.err, name)
+	}
+
+	assert.Equal(t, []string{"webservice", "worker"}, m.MatchTypes(types.TypeWorkload, "apps/v1", "Deployment"))
+	assert.Equal(t, []string{"scaler"}, m.MatchTypes(types.TypeTrait, "core.oam.dev/v1alpha2", "ManualScalerTrait"))
+	assert.Empty(t, m.MatchTypes(types.TypeTrait, "apps/v1", "Deployment"))
+}
@@ -61,6 +61,20 @@ func LoadFromFile(fileName string) (*Application, error) {
 	return app, app.Validate()
 }
 
+// LoadFromBytes will load and validate application from YAML or JSON data
+func LoadFromBytes(b []byte) (*Application, error) {
+	tm, err := template.Load()
+	if err != nil {
+		return nil, err
+	}
+	f, err := appfile.LoadFromBytes(b)
+	if err != nil {
+		return nil, err
+	}
+	app := newApplication(f, tm)
+	return app, app.Validate()
+}
+
 // Load will load application with env and name from default vela home dir.
 func Load(envName, appName string) (*Application, error) {
 	appDir, err := getApplicationDir(envName)
@@ -149,6 +163,9 @@ func (app *Application) Validate() error {
 		return errors.New("at least one service is required")
 	}
 	for name, svc := range app.Services {
+		if _, err := svc.GetScopes(); err != nil {
+			return fmt.Errorf("invalid scopes in '%s': %w", name, err)
+		}
 		for traitName, traitData := range svc.GetConfig() {
 			if app.tm.IsTrait(traitName) {
 				if _, ok := traitData.(map[string]interface{}); !ok {
@@ -157,6 +174,9 @@ func (app *Application) Validate() error {
 			}
 		}
 	}
+	if _, err := app.GetServiceLevels(); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -239,8 +259,8 @@ func (app *Application) GetTraitsByType(componentName, traitType string) (map[st
 }
 
 // OAM will convert an AppFile to OAM objects
-// TODO(wonderflow) add scope support here
 func (app *Application) OAM(env *types.EnvMeta, io cmdutil.IOStreams, silence bool) ([]*v1alpha2.Component, *v1alpha2.ApplicationConfiguration, []oam.Object, error) {
+	app.SetEnv(env)
 	comps, appConfig, scopes, err := app.RenderOAM(env.Namespace, io, app.tm, silence)
 	if err != nil {
 		return nil, nil, nil, err
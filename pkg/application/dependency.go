@@ -0,0 +1,163 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ComponentHealth returns the health status and diagnosis of the component. The status policy of the workload
+// definition evaluates the workload if it declares one, otherwise the HealthScope which the component is in reports it,
+// so everything showing health agrees with each other. The status is empty if the component is not in the AppConfig
+// or in a HealthScope, or it's not checked yet.
+func ComponentHealth(ctx context.Context, c client.Reader, ac *v1alpha2.ApplicationConfiguration, compName string) (v1alpha2.HealthStatus, string, error) {
+	if health, diagnosis, found, err := componentPolicyHealth(ctx, c, ac, compName); err != nil || found {
+		return health, diagnosis, err
+	}
+	var scopeName string
+	for _, comp := range ac.Spec.Components {
+		if comp.ComponentName != compName {
+			continue
+		}
+		for _, s := range comp.Scopes {
+			if s.ScopeReference.Kind == v1alpha2.HealthScopeKind {
+				scopeName = s.ScopeReference.Name
+			}
+		}
+	}
+	if scopeName == "" {
+		return "", "", nil
+	}
+	var health v1alpha2.HealthScope
+	if err := c.Get(ctx, client.ObjectKey{Namespace: ac.Namespace, Name: scopeName}, &health); err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", "", nil
+		}
+		return "", "", err
+	}
+	for _, cond := range health.Status.WorkloadHealthConditions {
+		if cond.ComponentName == compName {
+			return cond.HealthStatus, cond.Diagnosis, nil
+		}
+	}
+	return "", "", nil
+}
+
+// ComponentObserved returns whether the latest revision of the component is running, i.e. the deployed AppConfig
+// runs the latest revision of the Component and the workload has observed its latest spec. Until then the HealthScope
+// still reports the health of the previous version.
+func ComponentObserved(ctx context.Context, c client.Client, ac *v1alpha2.ApplicationConfiguration, compName string) (bool, error) {
+	var comp v1alpha2.Component
+	if err := c.Get(ctx, client.ObjectKey{Namespace: ac.Namespace, Name: compName}, &comp); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	if comp.Status.LatestRevision == nil || comp.Status.ObservedGeneration != comp.Generation {
+		return false, nil
+	}
+	var deployed v1alpha2.ApplicationConfiguration
+	if err := c.Get(ctx, client.ObjectKey{Namespace: ac.Namespace, Name: ac.Name}, &deployed); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	if deployed.Status.ObservedGeneration != deployed.Generation {
+		return false, nil
+	}
+	for _, w := range deployed.Status.Workloads {
+		if w.ComponentName != compName {
+			continue
+		}
+		if w.ComponentRevisionName != comp.Status.LatestRevision.Name {
+			return false, nil
+		}
+		workload := &unstructured.Unstructured{}
+		workload.SetAPIVersion(w.Reference.APIVersion)
+		workload.SetKind(w.Reference.Kind)
+		if err := c.Get(ctx, client.ObjectKey{Namespace: ac.Namespace, Name: w.Reference.Name}, workload); err != nil {
+			return false, client.IgnoreNotFound(err)
+		}
+		// workloads without observedGeneration in status are trusted once they're updated
+		observed, found, err := unstructured.NestedInt64(workload.Object, "status", "observedGeneration")
+		if err != nil || !found {
+			return true, nil
+		}
+		return observed >= workload.GetGeneration(), nil
+	}
+	return false, nil
+}
+
+// BlockingDependencies returns dependencies of a service which are not healthy yet, a dependency being updated is
+// blocking until its latest revision is observed, no matter how healthy the previous version is
+func BlockingDependencies(ctx context.Context, c client.Client, ac *v1alpha2.ApplicationConfiguration, deps []string) ([]string, error) {
+	var blocking []string
+	for _, dep := range deps {
+		status, _, err := ComponentHealth(ctx, c, ac, dep)
+		if err != nil {
+			return nil, err
+		}
+		if status != v1alpha2.StatusHealthy {
+			blocking = append(blocking, dep)
+			continue
+		}
+		observed, err := ComponentObserved(ctx, c, ac, dep)
+		if err != nil {
+			return nil, err
+		}
+		if !observed {
+			blocking = append(blocking, dep)
+		}
+	}
+	return blocking, nil
+}
+
+// WaitForHealthy polls the HealthScopes of the AppConfig until all services are healthy or ctx is done
+func WaitForHealthy(ctx context.Context, c client.Client, ac *v1alpha2.ApplicationConfiguration, services []string, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		blocking, err := BlockingDependencies(ctx, c, ac, services)
+		if err != nil {
+			return err
+		}
+		if len(blocking) == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("waiting for %s to be healthy: %w", strings.Join(blocking, ", "), ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// StageAppConfig returns a copy of the AppConfig in which only Components of services are updated,
+// other Components keep their state in the deployed AppConfig, or are left out if they're not deployed yet.
+// deployed is nil if the AppConfig is not deployed yet.
+func StageAppConfig(ac, deployed *v1alpha2.ApplicationConfiguration, services []string) *v1alpha2.ApplicationConfiguration {
+	update := make(map[string]bool, len(services))
+	for _, s := range services {
+		update[s] = true
+	}
+	old := make(map[string]v1alpha2.ApplicationConfigurationComponent)
+	if deployed != nil {
+		for _, comp := range deployed.Spec.Components {
+			old[comp.ComponentName] = comp
+		}
+	}
+	stage := ac.DeepCopy()
+	stage.Spec.Components = nil
+	for _, comp := range ac.Spec.Components {
+		if update[comp.ComponentName] {
+			stage.Spec.Components = append(stage.Spec.Components, *comp.DeepCopy())
+			continue
+		}
+		if o, ok := old[comp.ComponentName]; ok {
+			stage.Spec.Components = append(stage.Spec.Components, *o.DeepCopy())
+		}
+	}
+	return stage
+}
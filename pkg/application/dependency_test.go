@@ -0,0 +1,120 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	"github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+func healthScoped(name string) v1alpha2.ApplicationConfigurationComponent {
+	return v1alpha2.ApplicationConfigurationComponent{ComponentName: name, Scopes: []v1alpha2.ComponentScope{{
+		ScopeReference: runtimev1alpha1.TypedReference{Kind: v1alpha2.HealthScopeKind, Name: "myapp-default-health"},
+	}}}
+}
+
+func TestComponentHealth(t *testing.T) {
+	ctx := context.Background()
+	ac := &v1alpha2.ApplicationConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "myapp", Namespace: "default"},
+		Spec: v1alpha2.ApplicationConfigurationSpec{Components: []v1alpha2.ApplicationConfigurationComponent{
+			healthScoped("db"), healthScoped("cache"), healthScoped("api"), {ComponentName: "unscoped"},
+		}},
+	}
+	health := &v1alpha2.HealthScope{ObjectMeta: metav1.ObjectMeta{Name: "myapp-default-health", Namespace: "default"}}
+	health.Status.WorkloadHealthConditions = []*v1alpha2.WorkloadHealthCondition{
+		{ComponentName: "db", HealthStatus: v1alpha2.StatusHealthy, Diagnosis: "Ready: 1/1"},
+		{ComponentName: "cache", HealthStatus: v1alpha2.StatusUnhealthy, Diagnosis: "Ready: 0/1"},
+	}
+	// db runs its latest revision
+	deployed := ac.DeepCopy()
+	deployed.Status.Workloads = []v1alpha2.WorkloadStatus{{ComponentName: "db", ComponentRevisionName: "db-v1",
+		Reference: runtimev1alpha1.TypedReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "db"}}}
+	db := &v1alpha2.Component{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"}}
+	db.Status.LatestRevision = &v1alpha2.Revision{Name: "db-v1", Revision: 1}
+	dbWorkload := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default", Generation: 1}}
+	dbWorkload.Status.ObservedGeneration = 1
+	c := fake.NewFakeClientWithScheme(common.Scheme, health, deployed, db, dbWorkload)
+
+	status, diagnosis, err := ComponentHealth(ctx, c, ac, "db")
+	assert.NoError(t, err)
+	assert.Equal(t, v1alpha2.StatusHealthy, status)
+	assert.Equal(t, "Ready: 1/1", diagnosis)
+	for _, comp := range []string{"api", "unscoped", "unknown"} {
+		status, _, err = ComponentHealth(ctx, c, ac, comp)
+		assert.NoError(t, err)
+		assert.Empty(t, status, comp)
+	}
+
+	blocking, err := BlockingDependencies(ctx, c, ac, []string{"cache", "db", "api"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"cache", "api"}, blocking)
+
+	assert.NoError(t, WaitForHealthy(ctx, c, ac, []string{"db"}, time.Millisecond))
+	timeout, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	err = WaitForHealthy(timeout, c, ac, []string{"db", "cache"}, time.Millisecond)
+	assert.EqualError(t, err, "waiting for cache to be healthy: context deadline exceeded")
+
+	// the previous version of db is healthy but its latest revision is not running yet
+	db.Status.LatestRevision = &v1alpha2.Revision{Name: "db-v2", Revision: 2}
+	assert.NoError(t, c.Update(ctx, db))
+	blocking, err = BlockingDependencies(ctx, c, ac, []string{"db"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"db"}, blocking)
+	assert.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "myapp"}, deployed))
+	deployed.Status.Workloads[0].ComponentRevisionName = "db-v2"
+	assert.NoError(t, c.Update(ctx, deployed))
+	assert.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "db"}, dbWorkload))
+	dbWorkload.Generation = 2
+	assert.NoError(t, c.Update(ctx, dbWorkload))
+	blocking, err = BlockingDependencies(ctx, c, ac, []string{"db"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"db"}, blocking)
+	dbWorkload.Status.ObservedGeneration = 2
+	assert.NoError(t, c.Update(ctx, dbWorkload))
+	blocking, err = BlockingDependencies(ctx, c, ac, []string{"db"})
+	assert.NoError(t, err)
+	assert.Empty(t, blocking)
+
+	// no HealthScope created yet
+	c = fake.NewFakeClientWithScheme(common.Scheme)
+	status, _, err = ComponentHealth(ctx, c, ac, "db")
+	assert.NoError(t, err)
+	assert.Empty(t, status)
+}
+
+func TestStageAppConfig(t *testing.T) {
+	comp := func(name, image string) v1alpha2.ApplicationConfigurationComponent {
+		return v1alpha2.ApplicationConfigurationComponent{ComponentName: name, RevisionName: name + "-" + image}
+	}
+	ac := &v1alpha2.ApplicationConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "myapp", Namespace: "default"},
+		Spec: v1alpha2.ApplicationConfigurationSpec{Components: []v1alpha2.ApplicationConfigurationComponent{
+			comp("db", "v2"), comp("api", "v2"), comp("web", "v1"),
+		}},
+	}
+
+	// create
+	stage := StageAppConfig(ac, nil, []string{"db"})
+	assert.Equal(t, []v1alpha2.ApplicationConfigurationComponent{comp("db", "v2")}, stage.Spec.Components)
+	assert.Equal(t, 3, len(ac.Spec.Components))
+
+	// update, services not staged yet keep their deployed version, new services are left out
+	deployed := &v1alpha2.ApplicationConfiguration{Spec: v1alpha2.ApplicationConfigurationSpec{Components: []v1alpha2.ApplicationConfigurationComponent{
+		comp("removed", "v1"), comp("api", "v1"), comp("db", "v1"),
+	}}}
+	stage = StageAppConfig(ac, deployed, []string{"db"})
+	assert.Equal(t, []v1alpha2.ApplicationConfigurationComponent{comp("db", "v2"), comp("api", "v1")}, stage.Spec.Components)
+	stage = StageAppConfig(ac, deployed, []string{"db", "api", "web"})
+	assert.Equal(t, ac.Spec.Components, stage.Spec.Components)
+}
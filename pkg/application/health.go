@@ -0,0 +1,107 @@
+package application
+
+import (
+	"context"
+
+	"github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam/util"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/types"
+	mycue "github.com/oam-dev/kubevela/pkg/cue"
+)
+
+// StatusPolicy is the health expression and the message template declared in the extension of a definition
+type StatusPolicy struct {
+	Health  string
+	Message string
+}
+
+// GetStatusPolicy gets the status policy from the definition of the capability, it's nil if the definition doesn't
+// exist or declares neither health nor message
+func GetStatusPolicy(ctx context.Context, c client.Reader, captype types.CapType, name string) (*StatusPolicy, error) {
+	var extension *runtime.RawExtension
+	nn := util.GenNamespacedDefinitionName(name)
+	switch captype {
+	case types.TypeWorkload:
+		var wd v1alpha2.WorkloadDefinition
+		if err := c.Get(ctx, nn, &wd); err != nil {
+			return nil, client.IgnoreNotFound(err)
+		}
+		extension = wd.Spec.Extension
+	case types.TypeTrait:
+		var td v1alpha2.TraitDefinition
+		if err := c.Get(ctx, nn, &td); err != nil {
+			return nil, client.IgnoreNotFound(err)
+		}
+		extension = td.Spec.Extension
+	default:
+		return nil, nil
+	}
+	if extension == nil || extension.Raw == nil {
+		return nil, nil
+	}
+	capability, err := types.ConvertTemplateJSON2Object(extension)
+	if err != nil {
+		return nil, err
+	}
+	if capability.Health == "" && capability.Message == "" {
+		return nil, nil
+	}
+	return &StatusPolicy{Health: capability.Health, Message: capability.Message}, nil
+}
+
+// Eval evaluates the status policy against the live object
+func (p *StatusPolicy) Eval(obj *unstructured.Unstructured) (bool, string, error) {
+	return mycue.EvalStatus(p.Health, p.Message, obj.Object)
+}
+
+// GetWorkloadHealth evaluates the status policy of the definition of the workload, found is false if the workload has
+// no type label or its definition has no status policy
+func GetWorkloadHealth(ctx context.Context, c client.Reader, workload *unstructured.Unstructured) (health v1alpha2.HealthStatus, message string, found bool, err error) {
+	workloadType, ok := workload.GetLabels()[oam.WorkloadTypeLabel]
+	if !ok {
+		return "", "", false, nil
+	}
+	policy, err := GetStatusPolicy(ctx, c, types.TypeWorkload, workloadType)
+	if err != nil || policy == nil {
+		return "", "", false, err
+	}
+	healthy, message, err := policy.Eval(workload)
+	if err != nil {
+		return "", "", false, err
+	}
+	if healthy {
+		return v1alpha2.StatusHealthy, message, true, nil
+	}
+	return v1alpha2.StatusUnhealthy, message, true, nil
+}
+
+// componentPolicyHealth evaluates the status policy of the workload definition against the workload of the component.
+// ac may be rendered rather than deployed, workloads are read from the deployed AppConfig then.
+func componentPolicyHealth(ctx context.Context, c client.Reader, ac *v1alpha2.ApplicationConfiguration, compName string) (v1alpha2.HealthStatus, string, bool, error) {
+	workloads := ac.Status.Workloads
+	if len(workloads) == 0 {
+		var deployed v1alpha2.ApplicationConfiguration
+		if err := c.Get(ctx, client.ObjectKey{Namespace: ac.Namespace, Name: ac.Name}, &deployed); err != nil {
+			return "", "", false, client.IgnoreNotFound(err)
+		}
+		workloads = deployed.Status.Workloads
+	}
+	for _, w := range workloads {
+		if w.ComponentName != compName {
+			continue
+		}
+		workload := &unstructured.Unstructured{}
+		workload.SetAPIVersion(w.Reference.APIVersion)
+		workload.SetKind(w.Reference.Kind)
+		if err := c.Get(ctx, client.ObjectKey{Namespace: ac.Namespace, Name: w.Reference.Name}, workload); err != nil {
+			return "", "", false, client.IgnoreNotFound(err)
+		}
+		return GetWorkloadHealth(ctx, c, workload)
+	}
+	return "", "", false, nil
+}
@@ -0,0 +1,76 @@
+package application
+
+import (
+	"context"
+	"testing"
+
+	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	"github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+func TestComponentHealthByDefinition(t *testing.T) {
+	ctx := context.Background()
+	ac := &v1alpha2.ApplicationConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "myapp", Namespace: "default"},
+		Spec: v1alpha2.ApplicationConfigurationSpec{Components: []v1alpha2.ApplicationConfigurationComponent{
+			healthScoped("web"), healthScoped("db"),
+		}},
+		Status: v1alpha2.ApplicationConfigurationStatus{Workloads: []v1alpha2.WorkloadStatus{
+			{ComponentName: "web", Reference: runtimev1alpha1.TypedReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "web"}},
+			{ComponentName: "db", Reference: runtimev1alpha1.TypedReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "db"}},
+		}},
+	}
+	health := &v1alpha2.HealthScope{ObjectMeta: metav1.ObjectMeta{Name: "myapp-default-health", Namespace: "default"}}
+	health.Status.WorkloadHealthConditions = []*v1alpha2.WorkloadHealthCondition{
+		{ComponentName: "web", HealthStatus: v1alpha2.StatusHealthy, Diagnosis: "Ready: 1/2"},
+		{ComponentName: "db", HealthStatus: v1alpha2.StatusHealthy, Diagnosis: "Ready: 1/1"},
+	}
+	c := fake.NewFakeClientWithScheme(common.Scheme, ac.DeepCopy(), health,
+		&v1alpha2.WorkloadDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: "webservice"},
+			Spec: v1alpha2.WorkloadDefinitionSpec{
+				Reference: v1alpha2.DefinitionReference{Name: "deployments.apps"},
+				Extension: &runtime.RawExtension{Raw: []byte(`{
+"health": "context.output.status.readyReplicas == context.output.spec.replicas",
+"message": "\"Ready: \\(context.output.status.readyReplicas)/\\(context.output.spec.replicas)\""}`)},
+			},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default",
+				Labels: map[string]string{"workload.oam.dev/type": "webservice"}},
+			Spec:   appsv1.DeploymentSpec{Replicas: pointer.Int32Ptr(2)},
+			Status: appsv1.DeploymentStatus{ReadyReplicas: 1},
+		},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"}},
+	)
+
+	// the status policy of the definition takes precedence over the HealthScope
+	status, diagnosis, err := ComponentHealth(ctx, c, ac, "web")
+	assert.NoError(t, err)
+	assert.Equal(t, v1alpha2.HealthStatus(v1alpha2.StatusUnhealthy), status)
+	assert.Equal(t, "Ready: 1/2", diagnosis)
+	blocking, err := BlockingDependencies(ctx, c, ac, []string{"web"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"web"}, blocking)
+
+	// workloads without a status policy are reported by the HealthScope
+	status, diagnosis, err = ComponentHealth(ctx, c, ac, "db")
+	assert.NoError(t, err)
+	assert.Equal(t, v1alpha2.StatusHealthy, status)
+	assert.Equal(t, "Ready: 1/1", diagnosis)
+
+	// a rendered AppConfig has no status, workloads are read from the deployed one
+	rendered := ac.DeepCopy()
+	rendered.Status = v1alpha2.ApplicationConfigurationStatus{}
+	status, _, err = ComponentHealth(ctx, c, rendered, "web")
+	assert.NoError(t, err)
+	assert.Equal(t, v1alpha2.HealthStatus(v1alpha2.StatusUnhealthy), status)
+}
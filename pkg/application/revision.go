@@ -0,0 +1,375 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam"
+	"github.com/ghodss/yaml"
+	"github.com/pmezard/go-difflib/difflib"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/appfile"
+)
+
+// Revisions are stored as ConfigMaps in the namespace of the env, so they're shared by everyone deploying the app.
+// Secrets deployed along with a revision are kept in a Secret of the same name, their values never go into ConfigMaps.
+
+// MaxRevisions is the maximum number of revisions kept for an application, older revisions are pruned
+const MaxRevisions = 50
+
+// LabelRevision labels ConfigMaps and Secrets storing revisions with the revision number, they're also labelled with
+// the app name
+const LabelRevision = "app.oam.dev/revision"
+
+const (
+	revisionKey        = "revision.yaml"
+	revisionSecretsKey = "secrets.yaml"
+)
+
+// Revision is a deployed version of an application, it keeps the Appfile along with the Components, the AppConfig and
+// the Secrets rendered from it, so rolling back to it doesn't depend on current templates of capabilities or configs
+type Revision struct {
+	Revision   int       `json:"revision"`
+	CreateTime time.Time `json:"createTime"`
+	// RollbackTo is the revision which this revision rolled back to, it's 0 for deployments of Appfiles
+	RollbackTo int                                `json:"rollbackTo,omitempty"`
+	AppFile    *appfile.AppFile                   `json:"appfile"`
+	AppConfig  *v1alpha2.ApplicationConfiguration `json:"appConfig"`
+	Components []*v1alpha2.Component              `json:"components"`
+	// Secrets are the Appfile and config Secrets of the revision, they're only loaded by GetRevision.
+	// Only Appfile Secrets are restored by rollbacks, config Secrets are shared by apps of the env.
+	Secrets []*corev1.Secret `json:"-"`
+}
+
+// Description describes how the revision was deployed
+func (r *Revision) Description() string {
+	if r.RollbackTo != 0 {
+		return fmt.Sprintf("Rollback to %d", r.RollbackTo)
+	}
+	return "Deploy"
+}
+
+// FormatRevisionName returns the name of ConfigMap and Secret storing a revision of the app
+func FormatRevisionName(appName string, revision int) string {
+	return fmt.Sprintf("vela-revision-%s-%d", appName, revision)
+}
+
+func revisionLabels(appName string, revision int) map[string]string {
+	return map[string]string{oam.LabelAppName: appName, LabelRevision: strconv.Itoa(revision)}
+}
+
+// RecordRevision records the deployed Components, AppConfig and Secrets in scopes of the application as a new revision
+// in the namespace of the AppConfig
+func (app *Application) RecordRevision(ctx context.Context, c client.Client, ac *v1alpha2.ApplicationConfiguration,
+	comps []*v1alpha2.Component, scopes []oam.Object) (*Revision, error) {
+	var secrets []*corev1.Secret
+	for _, obj := range scopes {
+		if secret, ok := obj.(*corev1.Secret); ok {
+			secrets = append(secrets, secret)
+		}
+	}
+	return recordRevision(ctx, c, ac.Namespace, &Revision{AppFile: app.AppFile, AppConfig: ac, Components: comps, Secrets: secrets})
+}
+
+func recordRevision(ctx context.Context, c client.Client, namespace string, rev *Revision) (*Revision, error) {
+	appName := rev.AppFile.Name
+	revisions, err := listRevisionNumbers(ctx, c, namespace, appName)
+	if err != nil {
+		return nil, err
+	}
+	rev.Revision = 1
+	if len(revisions) != 0 {
+		rev.Revision = revisions[len(revisions)-1] + 1
+	}
+	rev.CreateTime = time.Now()
+	// only the spec of objects is kept, metadata filled by the apiserver would fail re-applying them
+	ac := rev.AppConfig.DeepCopy()
+	cleanObjectMeta(ac)
+	ac.Status = v1alpha2.ApplicationConfigurationStatus{}
+	rev.AppConfig = ac
+	comps := make([]*v1alpha2.Component, 0, len(rev.Components))
+	for _, c := range rev.Components {
+		comp := c.DeepCopy()
+		cleanObjectMeta(comp)
+		comp.Status = v1alpha2.ComponentStatus{}
+		comps = append(comps, comp)
+	}
+	rev.Components = comps
+	secrets := make([]*corev1.Secret, 0, len(rev.Secrets))
+	for _, s := range rev.Secrets {
+		secret := s.DeepCopy()
+		cleanObjectMeta(secret)
+		secrets = append(secrets, secret)
+	}
+	rev.Secrets = secrets
+
+	name := FormatRevisionName(appName, rev.Revision)
+	labels := revisionLabels(appName, rev.Revision)
+	// the Secret goes first, so a revision found by its ConfigMap is always complete
+	if len(rev.Secrets) != 0 {
+		data, err := yaml.Marshal(rev.Secrets)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.Create(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
+			Type:       corev1.SecretTypeOpaque,
+			Data:       map[string][]byte{revisionSecretsKey: data},
+		}); err != nil {
+			return nil, fmt.Errorf("record revision %d of app %s err %w", rev.Revision, appName, err)
+		}
+	}
+	data, err := yaml.Marshal(rev)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Create(ctx, &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
+		Data:       map[string]string{revisionKey: string(data)},
+	}); err != nil {
+		return nil, fmt.Errorf("record revision %d of app %s err %w", rev.Revision, appName, err)
+	}
+	revisions = append(revisions, rev.Revision)
+	for len(revisions) > MaxRevisions {
+		if err := deleteRevision(ctx, c, namespace, appName, revisions[0]); err != nil {
+			return nil, err
+		}
+		revisions = revisions[1:]
+	}
+	return rev, nil
+}
+
+func deleteRevision(ctx context.Context, c client.Client, namespace, appName string, revision int) error {
+	meta := metav1.ObjectMeta{Name: FormatRevisionName(appName, revision), Namespace: namespace}
+	if err := c.Delete(ctx, &corev1.ConfigMap{ObjectMeta: meta}); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	if err := c.Delete(ctx, &corev1.Secret{ObjectMeta: meta}); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+func cleanObjectMeta(obj metav1.Object) {
+	obj.SetResourceVersion("")
+	obj.SetUID("")
+	obj.SetSelfLink("")
+	obj.SetGeneration(0)
+	obj.SetCreationTimestamp(metav1.Time{})
+	obj.SetManagedFields(nil)
+}
+
+func listRevisionConfigMaps(ctx context.Context, c client.Client, namespace, appName string) ([]corev1.ConfigMap, error) {
+	var cms corev1.ConfigMapList
+	if err := c.List(ctx, &cms, client.InNamespace(namespace), AppLabelSelector(appName, LabelRevision)); err != nil {
+		return nil, err
+	}
+	return cms.Items, nil
+}
+
+// listRevisionNumbers returns sorted numbers of revisions of the app
+func listRevisionNumbers(ctx context.Context, c client.Client, namespace, appName string) ([]int, error) {
+	cms, err := listRevisionConfigMaps(ctx, c, namespace, appName)
+	if err != nil {
+		return nil, err
+	}
+	var revisions []int
+	for _, cm := range cms {
+		n, err := strconv.Atoi(cm.Labels[LabelRevision])
+		if err != nil {
+			continue
+		}
+		revisions = append(revisions, n)
+	}
+	sort.Ints(revisions)
+	return revisions, nil
+}
+
+func revisionFromConfigMap(cm *corev1.ConfigMap, appName string) (*Revision, error) {
+	rev := &Revision{}
+	if err := yaml.Unmarshal([]byte(cm.Data[revisionKey]), rev); err != nil {
+		return nil, fmt.Errorf("load revision %s of app %s err %w", cm.Labels[LabelRevision], appName, err)
+	}
+	return rev, nil
+}
+
+// ListRevisions lists revisions of an application from the oldest to the latest, without their Secrets
+func ListRevisions(ctx context.Context, c client.Client, namespace, appName string) ([]*Revision, error) {
+	cms, err := listRevisionConfigMaps(ctx, c, namespace, appName)
+	if err != nil {
+		return nil, err
+	}
+	revisions := make([]*Revision, 0, len(cms))
+	for i := range cms {
+		rev, err := revisionFromConfigMap(&cms[i], appName)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, rev)
+	}
+	sort.Slice(revisions, func(i, j int) bool { return revisions[i].Revision < revisions[j].Revision })
+	return revisions, nil
+}
+
+// GetRevision gets a revision of an application along with its Secrets
+func GetRevision(ctx context.Context, c client.Client, namespace, appName string, revision int) (*Revision, error) {
+	key := client.ObjectKey{Namespace: namespace, Name: FormatRevisionName(appName, revision)}
+	var cm corev1.ConfigMap
+	if err := c.Get(ctx, key, &cm); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("revision %d of app %s not found", revision, appName)
+		}
+		return nil, err
+	}
+	rev, err := revisionFromConfigMap(&cm, appName)
+	if err != nil {
+		return nil, err
+	}
+	var secret corev1.Secret
+	if err := c.Get(ctx, key, &secret); err != nil {
+		// revisions without Secrets don't have one
+		if apierrors.IsNotFound(err) {
+			return rev, nil
+		}
+		return nil, err
+	}
+	if err := yaml.Unmarshal(secret.Data[revisionSecretsKey], &rev.Secrets); err != nil {
+		return nil, fmt.Errorf("load secrets of revision %d of app %s err %w", revision, appName, err)
+	}
+	return rev, nil
+}
+
+// DeleteRevisions deletes all revisions of an application
+func DeleteRevisions(ctx context.Context, c client.Client, namespace, appName string) error {
+	selector := []client.DeleteAllOfOption{client.InNamespace(namespace), AppLabelSelector(appName, LabelRevision)}
+	if err := c.DeleteAllOf(ctx, &corev1.ConfigMap{}, selector...); err != nil {
+		return err
+	}
+	return c.DeleteAllOf(ctx, &corev1.Secret{}, selector...)
+}
+
+// DiffRevisions returns the unified diff of Appfiles from the old revision to the new one,
+// the old revision could be nil to diff against an empty Appfile
+func DiffRevisions(old, cur *Revision) (string, error) {
+	var from []string
+	fromFile := "/dev/null"
+	if old != nil {
+		data, err := marshalAppfile(old.AppFile)
+		if err != nil {
+			return "", err
+		}
+		from = difflib.SplitLines(data)
+		fromFile = fmt.Sprintf("revision %d", old.Revision)
+	}
+	data, err := marshalAppfile(cur.AppFile)
+	if err != nil {
+		return "", err
+	}
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        from,
+		B:        difflib.SplitLines(data),
+		FromFile: fromFile,
+		ToFile:   fmt.Sprintf("revision %d", cur.Revision),
+		Context:  3,
+	})
+}
+
+// marshalAppfile marshals the Appfile without timestamps, which change on every deployment
+func marshalAppfile(f *appfile.AppFile) (string, error) {
+	if f == nil {
+		return "", nil
+	}
+	af := *f
+	af.CreateTime = time.Time{}
+	af.UpdateTime = time.Time{}
+	data, err := yaml.Marshal(&af)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// Rollback re-applies the Appfile Secrets, Components and AppConfig of a revision, restores its Appfile and records
+// the rollback as a new revision. Config Secrets are not rolled back, they may be used by other apps of the env and
+// are changed by `vela config` only.
+func Rollback(ctx context.Context, c client.Client, env *types.EnvMeta, appName string, to int) (*Revision, error) {
+	target, err := GetRevision(ctx, c, env.Namespace, appName, to)
+	if err != nil {
+		return nil, err
+	}
+	// hosts of the revision may have been claimed by other apps since then
+	if err := CheckRouteHostConflicts(ctx, c, target.AppConfig); err != nil {
+		return nil, err
+	}
+	revisions, err := listRevisionNumbers(ctx, c, env.Namespace, appName)
+	if err != nil {
+		return nil, err
+	}
+	// services and Appfile secrets added after the revision are removed
+	if latest := revisions[len(revisions)-1]; latest != to {
+		latestRev, err := GetRevision(ctx, c, env.Namespace, appName, latest)
+		if err != nil {
+			return nil, err
+		}
+		keep := make(map[string]bool)
+		for _, comp := range target.Components {
+			keep[comp.Name] = true
+		}
+		for _, comp := range latestRev.Components {
+			if keep[comp.Name] {
+				continue
+			}
+			if err := c.Delete(ctx, comp.DeepCopy()); err != nil && !apierrors.IsNotFound(err) {
+				return nil, fmt.Errorf("delete service %s err %w", comp.Name, err)
+			}
+		}
+		for _, secret := range target.Secrets {
+			keep[secret.Name] = true
+		}
+		for _, secret := range latestRev.Secrets {
+			// config Secrets may be used by other apps of the env
+			if _, ok := secret.Labels[appfile.LabelAppfileSecret]; !ok || keep[secret.Name] {
+				continue
+			}
+			if err := c.Delete(ctx, secret.DeepCopy()); err != nil && !apierrors.IsNotFound(err) {
+				return nil, fmt.Errorf("delete secret %s err %w", secret.Name, err)
+			}
+		}
+	}
+	for _, secret := range target.Secrets {
+		if _, ok := secret.Labels[appfile.LabelAppfileSecret]; !ok {
+			continue
+		}
+		if err := createOrUpdateSecret(ctx, c, secret.DeepCopy()); err != nil {
+			return nil, fmt.Errorf("rollback secret %s err %w", secret.Name, err)
+		}
+	}
+	for _, comp := range target.Components {
+		if err := CreateOrUpdateComponent(ctx, c, comp.DeepCopy()); err != nil {
+			return nil, fmt.Errorf("rollback service %s err %w", comp.Name, err)
+		}
+	}
+	if err := CreateOrUpdateAppConfig(ctx, c, target.AppConfig.DeepCopy()); err != nil {
+		return nil, fmt.Errorf("rollback app %s err %w", appName, err)
+	}
+	app := &Application{AppFile: target.AppFile}
+	if err := app.Save(env.Name); err != nil {
+		return nil, err
+	}
+	return recordRevision(ctx, c, env.Namespace, &Revision{
+		RollbackTo: to,
+		AppFile:    app.AppFile,
+		AppConfig:  target.AppConfig,
+		Components: target.Components,
+		Secrets:    target.Secrets,
+	})
+}
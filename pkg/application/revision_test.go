@@ -0,0 +1,154 @@
+package application
+
+import (
+	"context"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/apis/standard.oam.dev/v1alpha1"
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/appfile"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+	"github.com/oam-dev/kubevela/pkg/utils/config"
+	"github.com/oam-dev/kubevela/pkg/utils/system"
+)
+
+func TestRevisions(t *testing.T) {
+	ctx := context.Background()
+	home, err := ioutil.TempDir("", "vela-revision")
+	assert.NoError(t, err)
+	defer os.RemoveAll(home)
+	assert.NoError(t, os.Setenv(system.VelaHomeEnv, home))
+	defer os.Unsetenv(system.VelaHomeEnv)
+
+	env := &types.EnvMeta{Name: "default", Namespace: "default"}
+	// a revision of another app
+	c := fake.NewFakeClientWithScheme(common.Scheme, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name: "vela-revision-other-1", Namespace: "default", Labels: revisionLabels("other", 1)}})
+	configSecret := func(value string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: appfile.FormatConfigSecretName("default", "demo"), Namespace: "default",
+				Labels: map[string]string{config.LabelConfigName: "demo"}},
+			Data: map[string][]byte{"DEBUG": []byte(value)},
+		}
+	}
+	deploy := func(images, secrets map[string]string, host, debug string) *Revision {
+		app := &Application{AppFile: appfile.NewAppFile()}
+		app.Name = "myapp"
+		ac := &v1alpha2.ApplicationConfiguration{ObjectMeta: metav1.ObjectMeta{Name: "myapp", Namespace: "default"}}
+		var comps []*v1alpha2.Component
+		for name, image := range images {
+			app.Services[name] = appfile.Service{"image": image}
+			acComp := v1alpha2.ApplicationConfigurationComponent{ComponentName: name}
+			if name == "web" && host != "" {
+				acComp.Traits = append(acComp.Traits, routeTrait(host, ""))
+			}
+			ac.Spec.Components = append(ac.Spec.Components, acComp)
+			comps = append(comps, &v1alpha2.Component{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+				Spec:       v1alpha2.ComponentSpec{Workload: runtime.RawExtension{Raw: []byte(`{"image":"` + image + `"}`)}},
+			})
+		}
+		var scopes []oam.Object
+		for name, value := range secrets {
+			app.Secrets[name] = "env:" + strings.ToUpper(name)
+			scopes = append(scopes, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: appfile.FormatSecretName("myapp", name), Namespace: "default",
+					Labels: map[string]string{oam.LabelAppName: "myapp", appfile.LabelAppfileSecret: name}},
+				Data: map[string][]byte{strings.ToUpper(name): []byte(value)},
+			})
+		}
+		scopes = append(scopes, configSecret(debug))
+		assert.NoError(t, app.Run(ctx, c, ac, comps, scopes))
+		assert.NoError(t, app.Save("default"))
+		rev, err := app.RecordRevision(ctx, c, ac, comps, scopes)
+		assert.NoError(t, err)
+		return rev
+	}
+
+	assert.Equal(t, 1, deploy(map[string]string{"web": "nginx:1.18"}, map[string]string{"db": "pass1"}, "web.example.com", "false").Revision)
+	assert.Equal(t, 2, deploy(map[string]string{"web": "nginx:1.19", "worker": "busybox"},
+		map[string]string{"db": "pass2", "token": "abc"}, "", "true").Revision)
+
+	// revisions are ConfigMaps labelled with the app and the revision, values of Secrets are only in Secrets
+	var cm corev1.ConfigMap
+	assert.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "vela-revision-myapp-2"}, &cm))
+	assert.Equal(t, map[string]string{oam.LabelAppName: "myapp", LabelRevision: "2"}, cm.Labels)
+	assert.NotContains(t, cm.Data[revisionKey], "pass2")
+	assert.NotContains(t, cm.Data[revisionKey], base64.StdEncoding.EncodeToString([]byte("pass2")))
+
+	revisions, err := ListRevisions(ctx, c, "default", "myapp")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(revisions))
+	assert.Equal(t, "Deploy", revisions[1].Description())
+	// metadata filled by the apiserver is not kept
+	assert.Empty(t, revisions[1].Components[0].ResourceVersion)
+	diff, err := DiffRevisions(revisions[0], revisions[1])
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(diff, "--- revision 1\n+++ revision 2\n"), diff)
+	assert.Contains(t, diff, "-    image: nginx:1.18\n+    image: nginx:1.19\n")
+	assert.Contains(t, diff, "+  worker:\n+    image: busybox\n")
+
+	// the host of revision 1 has been claimed by another app since then
+	other := &v1alpha1.Route{
+		ObjectMeta: metav1.ObjectMeta{Name: "other-route", Namespace: "default", Labels: map[string]string{oam.LabelAppName: "other"}},
+		Spec:       v1alpha1.RouteSpec{Host: "web.example.com"},
+	}
+	assert.NoError(t, c.Create(ctx, other))
+	_, err = Rollback(ctx, c, env, "myapp", 1)
+	assert.EqualError(t, err, "route host conflicts:\n  host web.example.com of service web is already claimed by app other in namespace default")
+	assert.NoError(t, c.Delete(ctx, other))
+
+	rev, err := Rollback(ctx, c, env, "myapp", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, rev.Revision)
+	assert.Equal(t, "Rollback to 1", rev.Description())
+	var web v1alpha2.Component
+	assert.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "web"}, &web))
+	assert.JSONEq(t, `{"image":"nginx:1.18"}`, string(web.Spec.Workload.Raw))
+	var worker v1alpha2.Component
+	assert.Error(t, c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "worker"}, &worker))
+	var ac v1alpha2.ApplicationConfiguration
+	assert.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "myapp"}, &ac))
+	assert.Equal(t, 1, len(ac.Spec.Components))
+	// Secrets are restored to the values of the revision, and the ones added after it are removed
+	var secret corev1.Secret
+	assert.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "myapp-db"}, &secret))
+	assert.Equal(t, "pass1", string(secret.Data["DB"]))
+	assert.Error(t, c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "myapp-token"}, &secret))
+	// the config Secret is shared by apps of the env, it keeps the current values
+	assert.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "vela-config-default-demo"}, &secret))
+	assert.Equal(t, "true", string(secret.Data["DEBUG"]))
+	app, err := Load("default", "myapp")
+	assert.NoError(t, err)
+	assert.Equal(t, "nginx:1.18", app.Services["web"]["image"])
+	rev, err = GetRevision(ctx, c, "default", "myapp", 3)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(rev.Secrets))
+
+	_, err = Rollback(ctx, c, env, "myapp", 5)
+	assert.EqualError(t, err, "revision 5 of app myapp not found")
+	assert.NoError(t, DeleteRevisions(ctx, c, "default", "myapp"))
+	revisions, err = ListRevisions(ctx, c, "default", "myapp")
+	assert.NoError(t, err)
+	assert.Empty(t, revisions)
+	var secrets corev1.SecretList
+	assert.NoError(t, c.List(ctx, &secrets, client.HasLabels{LabelRevision}))
+	assert.Empty(t, secrets.Items)
+	// revisions of other apps are left alone
+	revisions, err = ListRevisions(ctx, c, "default", "other")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(revisions))
+}
@@ -0,0 +1,135 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/standard.oam.dev/v1alpha1"
+)
+
+// RouteHost is the host of a route trait in an AppConfig
+type RouteHost struct {
+	Component string
+	Host      string
+	TLS       bool
+}
+
+// URL returns the URL which the route serves
+func (r RouteHost) URL() string {
+	if r.TLS {
+		return "https://" + r.Host
+	}
+	return "http://" + r.Host
+}
+
+// GetRouteHosts returns hosts of all route traits in the AppConfig, routes without a host are skipped
+func GetRouteHosts(ac *v1alpha2.ApplicationConfiguration) ([]RouteHost, error) {
+	var hosts []RouteHost
+	for _, comp := range ac.Spec.Components {
+		for _, tr := range comp.Traits {
+			u, err := traitToUnstructured(tr.Trait)
+			if err != nil {
+				return nil, fmt.Errorf("parse trait of component %s failed: %w", comp.ComponentName, err)
+			}
+			if u == nil || u.GroupVersionKind().GroupKind() != v1alpha1.SchemeGroupVersion.WithKind("Route").GroupKind() {
+				continue
+			}
+			host, _, _ := unstructured.NestedString(u.Object, "spec", "host")
+			if host == "" {
+				continue
+			}
+			issuer, _, _ := unstructured.NestedString(u.Object, "spec", "tls", "issuerName")
+			hosts = append(hosts, RouteHost{Component: comp.ComponentName, Host: host, TLS: issuer != ""})
+		}
+	}
+	return hosts, nil
+}
+
+// traitToUnstructured converts a trait rendered locally (Object) or read from cluster (Raw) into unstructured
+func traitToUnstructured(raw runtime.RawExtension) (*unstructured.Unstructured, error) {
+	if u, ok := raw.Object.(*unstructured.Unstructured); ok {
+		return u, nil
+	}
+	data := raw.Raw
+	if raw.Object != nil {
+		var err error
+		if data, err = json.Marshal(raw.Object); err != nil {
+			return nil, err
+		}
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+	u := &unstructured.Unstructured{}
+	if err := json.Unmarshal(data, &u.Object); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// CheckRouteHostConflicts makes sure hosts of routes in the AppConfig are not claimed twice,
+// neither by another service of the same app nor by routes of other apps in the cluster.
+// Users not allowed to list routes of the whole cluster can't see routes in other namespaces,
+// then only routes in the namespace of the AppConfig are checked.
+func CheckRouteHostConflicts(ctx context.Context, c client.Client, ac *v1alpha2.ApplicationConfiguration) error {
+	hosts, err := GetRouteHosts(ac)
+	if err != nil {
+		return err
+	}
+	if len(hosts) == 0 {
+		return nil
+	}
+	var conflicts []string
+	claimed := make(map[string]string, len(hosts))
+	for _, h := range hosts {
+		if comp, ok := claimed[h.Host]; ok {
+			conflicts = append(conflicts, fmt.Sprintf("host %s is claimed by both service %s and %s", h.Host, comp, h.Component))
+			continue
+		}
+		claimed[h.Host] = h.Component
+	}
+
+	var routes v1alpha1.RouteList
+	err = c.List(ctx, &routes)
+	if apierrors.IsForbidden(err) {
+		err = c.List(ctx, &routes, client.InNamespace(ac.Namespace))
+	}
+	if err != nil {
+		// no route can conflict if the Route CRD is not installed
+		if meta.IsNoMatchError(err) {
+			return nil
+		}
+		return fmt.Errorf("list routes failed: %w", err)
+	}
+	for _, r := range routes.Items {
+		comp, ok := claimed[r.Spec.Host]
+		if !ok {
+			continue
+		}
+		owner := r.GetLabels()[oam.LabelAppName]
+		if r.Namespace == ac.Namespace && owner == ac.Name {
+			continue
+		}
+		if owner == "" {
+			owner = "unknown"
+		}
+		conflicts = append(conflicts, fmt.Sprintf("host %s of service %s is already claimed by app %s in namespace %s",
+			r.Spec.Host, comp, owner, r.Namespace))
+	}
+	if len(conflicts) == 0 {
+		return nil
+	}
+	sort.Strings(conflicts)
+	return fmt.Errorf("route host conflicts:\n  %s", strings.Join(conflicts, "\n  "))
+}
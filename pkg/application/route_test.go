@@ -0,0 +1,111 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam"
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/apis/standard.oam.dev/v1alpha1"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+func routeTrait(host, issuer string) v1alpha2.ComponentTrait {
+	spec := map[string]interface{}{"host": host}
+	if issuer != "" {
+		spec["tls"] = map[string]interface{}{"issuerName": issuer}
+	}
+	return v1alpha2.ComponentTrait{Trait: runtime.RawExtension{Object: &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "standard.oam.dev/v1alpha1",
+		"kind":       "Route",
+		"spec":       spec,
+	}}}}
+}
+
+func TestGetRouteHosts(t *testing.T) {
+	ac := &v1alpha2.ApplicationConfiguration{Spec: v1alpha2.ApplicationConfigurationSpec{Components: []v1alpha2.ApplicationConfigurationComponent{
+		{ComponentName: "frontend", Traits: []v1alpha2.ComponentTrait{routeTrait("frontend.myapp.example.com", "oam-env-default")}},
+		{ComponentName: "backend", Traits: []v1alpha2.ComponentTrait{
+			{Trait: runtime.RawExtension{Raw: []byte(`{"apiVersion":"standard.oam.dev/v1alpha1","kind":"Route","spec":{"host":"backend.myapp.example.com"}}`)}},
+			{Trait: runtime.RawExtension{Raw: []byte(`{"apiVersion":"standard.oam.dev/v1alpha1","kind":"MetricsTrait","spec":{}}`)}},
+		}},
+	}}}
+	hosts, err := GetRouteHosts(ac)
+	assert.NoError(t, err)
+	assert.Equal(t, []RouteHost{
+		{Component: "frontend", Host: "frontend.myapp.example.com", TLS: true},
+		{Component: "backend", Host: "backend.myapp.example.com"},
+	}, hosts)
+	assert.Equal(t, "https://frontend.myapp.example.com", hosts[0].URL())
+	assert.Equal(t, "http://backend.myapp.example.com", hosts[1].URL())
+}
+
+func TestCheckRouteHostConflicts(t *testing.T) {
+	route := func(ns, app, host string) runtime.Object {
+		return &v1alpha1.Route{
+			ObjectMeta: metav1.ObjectMeta{Name: app + "-route", Namespace: ns, Labels: map[string]string{oam.LabelAppName: app}},
+			Spec:       v1alpha1.RouteSpec{Host: host},
+		}
+	}
+	c := fake.NewFakeClientWithScheme(common.Scheme,
+		route("default", "myapp", "frontend.myapp.example.com"),
+		route("default", "other", "taken.example.com"),
+		route("prod", "myapp", "prod.example.com"),
+	)
+	app := func(hosts ...string) *v1alpha2.ApplicationConfiguration {
+		ac := &v1alpha2.ApplicationConfiguration{ObjectMeta: metav1.ObjectMeta{Name: "myapp", Namespace: "default"}}
+		for i, h := range hosts {
+			ac.Spec.Components = append(ac.Spec.Components, v1alpha2.ApplicationConfigurationComponent{
+				ComponentName: []string{"frontend", "backend"}[i],
+				Traits:        []v1alpha2.ComponentTrait{routeTrait(h, "")},
+			})
+		}
+		return ac
+	}
+	ctx := context.Background()
+
+	// routes of the app itself don't conflict
+	assert.NoError(t, CheckRouteHostConflicts(ctx, c, app("frontend.myapp.example.com")))
+	assert.NoError(t, CheckRouteHostConflicts(ctx, c, app()))
+
+	err := CheckRouteHostConflicts(ctx, c, app("taken.example.com"))
+	assert.EqualError(t, err, "route host conflicts:\n  host taken.example.com of service frontend is already claimed by app other in namespace default")
+
+	// an app with the same name in another namespace is another app
+	err = CheckRouteHostConflicts(ctx, c, app("prod.example.com"))
+	assert.EqualError(t, err, "route host conflicts:\n  host prod.example.com of service frontend is already claimed by app myapp in namespace prod")
+
+	err = CheckRouteHostConflicts(ctx, c, app("a.example.com", "a.example.com"))
+	assert.EqualError(t, err, "route host conflicts:\n  host a.example.com is claimed by both service frontend and backend")
+
+	// only routes in the namespace of the app are checked if routes of the cluster can't be listed
+	nc := &namespacedClient{Client: c}
+	assert.NoError(t, CheckRouteHostConflicts(ctx, nc, app("prod.example.com")))
+	err = CheckRouteHostConflicts(ctx, nc, app("taken.example.com"))
+	assert.EqualError(t, err, "route host conflicts:\n  host taken.example.com of service frontend is already claimed by app other in namespace default")
+}
+
+// namespacedClient is a client of users who are only allowed to list objects in namespaces
+type namespacedClient struct {
+	client.Client
+}
+
+func (c *namespacedClient) List(ctx context.Context, list runtime.Object, opts ...client.ListOption) error {
+	listOpts := &client.ListOptions{}
+	listOpts.ApplyOptions(opts)
+	if listOpts.Namespace == "" {
+		return apierrors.NewForbidden(schema.GroupResource{Group: "standard.oam.dev", Resource: "routes"}, "",
+			errors.New("cannot list resource at the cluster scope"))
+	}
+	return c.Client.List(ctx, list, opts...)
+}
@@ -2,29 +2,41 @@ package application
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
 	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
 	ctypes "k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/appfile"
 	cmdutil "github.com/oam-dev/kubevela/pkg/commands/util"
 )
 
-// BuildRun will build OAM and deploy from Appfile
+// BuildRun will build OAM and deploy from Appfile, the deployment is recorded as a new revision
 func (app *Application) BuildRun(ctx context.Context, client client.Client, env *types.EnvMeta, io cmdutil.IOStreams) error {
 	components, appconfig, scopes, err := app.OAM(env, io, true)
 	if err != nil {
 		return err
 	}
-	return app.Run(ctx, client, appconfig, components, scopes)
+	if err := app.Run(ctx, client, appconfig, components, scopes); err != nil {
+		return err
+	}
+	_, err = app.RecordRevision(ctx, client, appconfig, components, scopes)
+	return err
 }
 
 // Run will deploy OAM objects.
 func (app *Application) Run(ctx context.Context, client client.Client,
 	ac *v1alpha2.ApplicationConfiguration, comps []*v1alpha2.Component, scopes []oam.Object) error {
+	if err := CheckRouteHostConflicts(ctx, client, ac); err != nil {
+		return err
+	}
 	for _, comp := range comps {
 		if err := CreateOrUpdateComponent(ctx, client, comp); err != nil {
 			return err
@@ -33,7 +45,10 @@ func (app *Application) Run(ctx context.Context, client client.Client,
 	if err := CreateScopes(ctx, client, scopes); err != nil {
 		return err
 	}
-	return CreateOrUpdateAppConfig(ctx, client, ac)
+	if err := CreateOrUpdateAppConfig(ctx, client, ac); err != nil {
+		return err
+	}
+	return DeleteStaleSecrets(ctx, client, ac, scopes)
 }
 
 // CreateOrUpdateComponent will create if not exist and update if exists.
@@ -68,13 +83,20 @@ func CreateOrUpdateAppConfig(ctx context.Context, client client.Client, appConfi
 	return client.Update(ctx, appConfig)
 }
 
-// CreateScopes will create all scopes
+// CreateScopes will create all scopes and other objects rendered along with them, e.g. Secrets of the Appfile.
+// Existing scopes are left as they are, while Secrets are updated to the latest values.
 func CreateScopes(ctx context.Context, client client.Client, scopes []oam.Object) error {
 	for _, obj := range scopes {
+		if secret, ok := obj.(*corev1.Secret); ok {
+			if err := createOrUpdateSecret(ctx, client, secret); err != nil {
+				return err
+			}
+			continue
+		}
 		key := ctypes.NamespacedName{Name: obj.GetName(), Namespace: obj.GetNamespace()}
 		err := client.Get(ctx, key, obj)
 		if err == nil {
-			return nil
+			continue
 		}
 		if !apierrors.IsNotFound(err) {
 			return err
@@ -85,3 +107,52 @@ func CreateScopes(ctx context.Context, client client.Client, scopes []oam.Object
 	}
 	return nil
 }
+
+// AppLabelSelector selects objects of the app which have the label. Use it instead of client.MatchingLabels along with
+// client.HasLabels, each of them replaces the selector set by the other.
+func AppLabelSelector(appName, label string) client.MatchingLabelsSelector {
+	sel := labels.SelectorFromSet(labels.Set{oam.LabelAppName: appName})
+	if r, err := labels.NewRequirement(label, selection.Exists, nil); err == nil {
+		sel = sel.Add(*r)
+	}
+	return client.MatchingLabelsSelector{Selector: sel}
+}
+
+// DeleteStaleSecrets deletes Appfile Secrets of the app which are not rendered any more, i.e. secrets removed from
+// the Appfile. Call it after the AppConfig is updated, so workloads don't refer to them any more.
+func DeleteStaleSecrets(ctx context.Context, c client.Client, ac *v1alpha2.ApplicationConfiguration, scopes []oam.Object) error {
+	rendered := make(map[string]bool)
+	for _, obj := range scopes {
+		if secret, ok := obj.(*corev1.Secret); ok {
+			rendered[secret.Name] = true
+		}
+	}
+	var secrets corev1.SecretList
+	if err := c.List(ctx, &secrets, client.InNamespace(ac.Namespace),
+		AppLabelSelector(ac.Name, appfile.LabelAppfileSecret)); err != nil {
+		return err
+	}
+	for i := range secrets.Items {
+		secret := &secrets.Items[i]
+		if rendered[secret.Name] {
+			continue
+		}
+		if err := c.Delete(ctx, secret); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("delete secret %s err %w", secret.Name, err)
+		}
+	}
+	return nil
+}
+
+func createOrUpdateSecret(ctx context.Context, client client.Client, secret *corev1.Secret) error {
+	var exist corev1.Secret
+	key := ctypes.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}
+	if err := client.Get(ctx, key, &exist); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		return client.Create(ctx, secret)
+	}
+	secret.ResourceVersion = exist.ResourceVersion
+	return client.Update(ctx, secret)
+}
@@ -0,0 +1,44 @@
+package application
+
+import (
+	"context"
+	"testing"
+
+	"github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/pkg/appfile"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+func TestRunDeletesStaleSecrets(t *testing.T) {
+	ctx := context.Background()
+	secret := func(app, name string) *corev1.Secret {
+		return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: appfile.FormatSecretName(app, name), Namespace: "default",
+			Labels: map[string]string{oam.LabelAppName: app, appfile.LabelAppfileSecret: name}}}
+	}
+	// Secrets of other apps and Secrets not rendered from Appfiles are left alone
+	c := fake.NewFakeClientWithScheme(common.Scheme, secret("other", "token"),
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "myapp-tls", Namespace: "default",
+			Labels: map[string]string{oam.LabelAppName: "myapp"}}})
+	app := &Application{AppFile: appfile.NewAppFile()}
+	app.Name = "myapp"
+	ac := &v1alpha2.ApplicationConfiguration{ObjectMeta: metav1.ObjectMeta{Name: "myapp", Namespace: "default"}}
+
+	assert.NoError(t, app.Run(ctx, c, ac.DeepCopy(), nil, []oam.Object{secret("myapp", "db"), secret("myapp", "token")}))
+	// token is removed from the Appfile
+	assert.NoError(t, app.Run(ctx, c, ac.DeepCopy(), nil, []oam.Object{secret("myapp", "db")}))
+
+	var secrets corev1.SecretList
+	assert.NoError(t, c.List(ctx, &secrets, client.InNamespace("default")))
+	var names []string
+	for _, s := range secrets.Items {
+		names = append(names, s.Name)
+	}
+	assert.ElementsMatch(t, []string{"myapp-db", "myapp-tls", "other-token"}, names)
+}
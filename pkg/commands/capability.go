@@ -13,6 +13,7 @@ import (
 	"github.com/oam-dev/kubevela/apis/types"
 	cmdutil "github.com/oam-dev/kubevela/pkg/commands/util"
 	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/server/apis"
 )
 
 // CapabilityCommandGroup commands for capability center
@@ -171,20 +172,39 @@ func NewCapListCommand(ioStreams cmdutil.IOStreams) *cobra.Command {
 			if len(args) > 0 {
 				repoName = args[0]
 			}
+			p, err := cmdutil.NewPrinter(cmd, ioStreams)
+			if err != nil {
+				return err
+			}
 			capabilityList, err := oam.ListCapabilities(repoName)
 			if err != nil {
 				return err
 			}
+			if p.Structured() {
+				if capabilityList == nil {
+					capabilityList = []types.Capability{}
+				}
+				return p.Print(capabilityList)
+			}
 			table := uitable.New()
-			table.AddRow("NAME", "CENTER", "TYPE", "DEFINITION", "STATUS", "APPLIES-TO")
+			if p.Wide() {
+				table.AddRow("NAME", "CENTER", "TYPE", "DEFINITION", "STATUS", "APPLIES-TO", "DESCRIPTION")
+			} else {
+				table.AddRow("NAME", "CENTER", "TYPE", "DEFINITION", "STATUS", "APPLIES-TO")
+			}
 
 			for _, c := range capabilityList {
+				if p.Wide() {
+					table.AddRow(c.Name, c.Center, c.Type, c.CrdName, c.Status, c.AppliesTo, c.Description)
+					continue
+				}
 				table.AddRow(c.Name, c.Center, c.Type, c.CrdName, c.Status, c.AppliesTo)
 			}
 			ioStreams.Info(table.String())
 			return nil
 		},
 	}
+	cmdutil.AddOutputFlag(cmd)
 	return cmd
 }
 
@@ -196,9 +216,14 @@ func NewCapCenterListCommand(ioStreams cmdutil.IOStreams) *cobra.Command {
 		Long:    "List all configured capability centers",
 		Example: `vela cap center ls`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return listCapCenters(ioStreams)
+			p, err := cmdutil.NewPrinter(cmd, ioStreams)
+			if err != nil {
+				return err
+			}
+			return listCapCenters(ioStreams, p)
 		},
 	}
+	cmdutil.AddOutputFlag(cmd)
 	return cmd
 }
 
@@ -216,13 +241,19 @@ func NewCapCenterRemoveCommand(ioStreams cmdutil.IOStreams) *cobra.Command {
 	return cmd
 }
 
-func listCapCenters(ioStreams cmdutil.IOStreams) error {
-	table := uitable.New()
-	table.AddRow("NAME", "ADDRESS")
+func listCapCenters(ioStreams cmdutil.IOStreams, p *cmdutil.Printer) error {
 	capabilityCenterList, err := oam.ListCapabilityCenters()
 	if err != nil {
 		return err
 	}
+	if p.Structured() {
+		if capabilityCenterList == nil {
+			capabilityCenterList = []apis.CapabilityCenterMeta{}
+		}
+		return p.Print(capabilityCenterList)
+	}
+	table := uitable.New()
+	table.AddRow("NAME", "ADDRESS")
 	for _, c := range capabilityCenterList {
 		table.AddRow(c.Name, c.URL)
 	}
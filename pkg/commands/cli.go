@@ -70,6 +70,8 @@ func NewCommand() *cobra.Command {
 		// Apps
 		NewListCommand(commandArgs, ioStream),
 		NewDeleteCommand(commandArgs, ioStream),
+		NewHistoryCommand(commandArgs, ioStream),
+		NewRollbackCommand(commandArgs, ioStream),
 		NewAppShowCommand(ioStream),
 		NewAppStatusCommand(commandArgs, ioStream),
 		NewExecCommand(commandArgs, ioStream),
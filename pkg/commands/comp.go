@@ -13,7 +13,6 @@ import (
 	"github.com/oam-dev/kubevela/pkg/plugins"
 
 	"github.com/spf13/cobra"
-	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // constants used in `svc` command
@@ -66,13 +65,12 @@ func NewCompDeployCommands(c types.Args, ioStreams util.IOStreams) *cobra.Comman
 				return nil
 			}
 			o := newRunOptions(ioStreams)
-			newClient, err := client.New(c.Config, client.Options{Scheme: c.Schema})
+			var err error
+			o.Env, err = GetEnv(cmd)
 			if err != nil {
 				return err
 			}
-			o.KubeClient = newClient
-			o.Env, err = GetEnv(cmd)
-			if err != nil {
+			if o.KubeClient, err = newClientForEnv(c, o.Env); err != nil {
 				return err
 			}
 			if err := o.Complete(cmd, args); err != nil {
@@ -1,24 +1,24 @@
 package commands
 
 import (
-	"bufio"
-	"bytes"
-	b64 "encoding/base64"
 	"fmt"
-	"io/ioutil"
+	"sort"
 	"strings"
 
 	"github.com/gosuri/uitable"
 	"github.com/spf13/cobra"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/oam-dev/kubevela/apis/types"
 	cmdutil "github.com/oam-dev/kubevela/pkg/commands/util"
+	"github.com/oam-dev/kubevela/pkg/server/apis"
 	"github.com/oam-dev/kubevela/pkg/utils/config"
 )
 
-// Notes about config dir layout:
-// Under each env dir, there are individual files for each config.
-// The format is the same as k8s Secret.Data field with value base64 encoded.
+// Notes about config storage:
+// Configs are kept in the config store of env, see config.NewStore for the backends.
+// The local backend keeps individual files for each config under each env dir,
+// the format is the same as k8s Secret.Data field with value base64 encoded.
 
 // NewConfigCommand will create command for config management for AppFile
 func NewConfigCommand(args types.Args, io cmdutil.IOStreams) *cobra.Command {
@@ -33,16 +33,16 @@ func NewConfigCommand(args types.Args, io cmdutil.IOStreams) *cobra.Command {
 	}
 	cmd.SetOut(io.Out)
 	cmd.AddCommand(
-		NewConfigListCommand(io),
-		NewConfigGetCommand(io),
-		NewConfigSetCommand(io),
-		NewConfigDeleteCommand(io),
+		NewConfigListCommand(args, io),
+		NewConfigGetCommand(args, io),
+		NewConfigSetCommand(args, io),
+		NewConfigDeleteCommand(args, io),
 	)
 	return cmd
 }
 
 // NewConfigListCommand list all created configs
-func NewConfigListCommand(io cmdutil.IOStreams) *cobra.Command {
+func NewConfigListCommand(c types.Args, io cmdutil.IOStreams) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:                   "ls",
 		Aliases:               []string{"list"},
@@ -51,60 +51,85 @@ func NewConfigListCommand(io cmdutil.IOStreams) *cobra.Command {
 		Long:                  "List all configs",
 		Example:               `vela config ls`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return ListConfigs(io, cmd)
+			return ListConfigs(c, io, cmd)
 		},
 		Annotations: map[string]string{
 			types.TagCommandType: types.TypeStart,
 		},
 	}
 	cmd.SetOut(io.Out)
+	cmdutil.AddOutputFlag(cmd)
 	return cmd
 }
 
-func getConfigDir(cmd *cobra.Command) (string, error) {
+func getConfigStore(c types.Args, cmd *cobra.Command) (config.Store, error) {
 	e, err := GetEnv(cmd)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	var kubeClient client.Client
+	if e.ConfigStore == config.StoreKubernetes {
+		if kubeClient, err = newClientForEnv(c, e); err != nil {
+			return nil, err
+		}
 	}
-	return config.GetConfigsDir(e.Name)
+	return config.NewStore(e, kubeClient)
 }
 
 // ListConfigs will list all configs
-func ListConfigs(ioStreams cmdutil.IOStreams, cmd *cobra.Command) error {
-	d, err := getConfigDir(cmd)
+func ListConfigs(c types.Args, ioStreams cmdutil.IOStreams, cmd *cobra.Command) error {
+	p, err := cmdutil.NewPrinter(cmd, ioStreams)
 	if err != nil {
 		return err
 	}
-	table := uitable.New()
-	table.MaxColWidth = 60
-	table.AddRow("NAME")
-	cfgList, err := listConfigs(d)
+	store, err := getConfigStore(c, cmd)
 	if err != nil {
 		return err
 	}
-
+	cfgList, err := store.List()
+	if err != nil {
+		return err
+	}
+	configs := make([]apis.ConfigMeta, 0, len(cfgList))
 	for _, name := range cfgList {
-		table.AddRow(name)
+		cfg := apis.ConfigMeta{Name: name}
+		// keys are only read when they are printed, values are never printed
+		if p.Structured() || p.Wide() {
+			data, err := store.Get(name)
+			if err != nil {
+				return err
+			}
+			for k := range data {
+				cfg.Keys = append(cfg.Keys, k)
+			}
+			sort.Strings(cfg.Keys)
+		}
+		configs = append(configs, cfg)
 	}
-	ioStreams.Info(table.String())
-	return nil
-}
-
-func listConfigs(dir string) ([]string, error) {
-	files, err := ioutil.ReadDir(dir)
-	if err != nil {
-		return nil, err
+	if p.Structured() {
+		return p.Print(configs)
 	}
 
-	l := []string{}
-	for _, f := range files {
-		l = append(l, f.Name())
+	table := uitable.New()
+	table.MaxColWidth = 60
+	if p.Wide() {
+		table.AddRow("NAME", "KEYS")
+	} else {
+		table.AddRow("NAME")
+	}
+	for _, cfg := range configs {
+		if p.Wide() {
+			table.AddRow(cfg.Name, strings.Join(cfg.Keys, ","))
+			continue
+		}
+		table.AddRow(cfg.Name)
 	}
-	return l, nil
+	ioStreams.Info(table.String())
+	return nil
 }
 
 // NewConfigGetCommand get config from local
-func NewConfigGetCommand(io cmdutil.IOStreams) *cobra.Command {
+func NewConfigGetCommand(c types.Args, io cmdutil.IOStreams) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:                   "get",
 		Aliases:               []string{"get"},
@@ -113,7 +138,7 @@ func NewConfigGetCommand(io cmdutil.IOStreams) *cobra.Command {
 		Long:                  "Get data for a config",
 		Example:               `vela config get <config-name>`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return getConfig(args, io, cmd)
+			return getConfig(c, args, io, cmd)
 		},
 		Annotations: map[string]string{
 			types.TagCommandType: types.TypeStart,
@@ -123,8 +148,8 @@ func NewConfigGetCommand(io cmdutil.IOStreams) *cobra.Command {
 	return cmd
 }
 
-func getConfig(args []string, io cmdutil.IOStreams, cmd *cobra.Command) error {
-	e, err := GetEnv(cmd)
+func getConfig(c types.Args, args []string, io cmdutil.IOStreams, cmd *cobra.Command) error {
+	store, err := getConfigStore(c, cmd)
 	if err != nil {
 		return err
 	}
@@ -132,24 +157,24 @@ func getConfig(args []string, io cmdutil.IOStreams, cmd *cobra.Command) error {
 		return fmt.Errorf("must specify config name, vela config get <name>")
 	}
 	configName := args[0]
-	cfgData, err := config.ReadConfig(e.Name, configName)
+	cfgData, err := store.Get(configName)
 	if err != nil {
 		return err
 	}
+	keys := make([]string, 0, len(cfgData))
+	for k := range cfgData {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
 	io.Infof("Data:\n")
-	scanner := bufio.NewScanner(bytes.NewReader(cfgData))
-	for scanner.Scan() {
-		k, v, err := config.ReadConfigLine(scanner.Text())
-		if err != nil {
-			return err
-		}
-		io.Infof("  %s: %s\n", k, v)
+	for _, k := range keys {
+		io.Infof("  %s: %s\n", k, cfgData[k])
 	}
 	return nil
 }
 
 // NewConfigSetCommand set a config data in local
-func NewConfigSetCommand(io cmdutil.IOStreams) *cobra.Command {
+func NewConfigSetCommand(c types.Args, io cmdutil.IOStreams) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:                   "set",
 		Aliases:               []string{"set"},
@@ -158,7 +183,7 @@ func NewConfigSetCommand(io cmdutil.IOStreams) *cobra.Command {
 		Long:                  "Set data for a config",
 		Example:               `vela config set <config-name> KEY=VALUE K2=V2`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return setConfig(args, io, cmd)
+			return setConfig(c, args, io, cmd)
 		},
 		Annotations: map[string]string{
 			types.TagCommandType: types.TypeStart,
@@ -168,12 +193,11 @@ func NewConfigSetCommand(io cmdutil.IOStreams) *cobra.Command {
 	return cmd
 }
 
-func setConfig(args []string, io cmdutil.IOStreams, cmd *cobra.Command) error {
-	e, err := GetEnv(cmd)
+func setConfig(c types.Args, args []string, io cmdutil.IOStreams, cmd *cobra.Command) error {
+	store, err := getConfigStore(c, cmd)
 	if err != nil {
 		return err
 	}
-	envName := e.Name
 
 	if len(args) < 1 {
 		return fmt.Errorf("must specify config name, vela config set <name> KEY=VALUE")
@@ -194,28 +218,17 @@ func setConfig(args []string, io cmdutil.IOStreams, cmd *cobra.Command) error {
 		input[k] = v
 	}
 
-	cfgData, err := config.ReadConfig(envName, configName)
+	cfgData, err := store.Get(configName)
 	if err != nil {
 		return err
 	}
 
 	io.Infof("reading existing config data and merging with user input\n")
-	scanner := bufio.NewScanner(bytes.NewReader(cfgData))
-	for scanner.Scan() {
-		k, v, err := config.ReadConfigLine(scanner.Text())
-		if err != nil {
-			return err
-		}
-		input[k] = v
-	}
-
-	var out bytes.Buffer
+	// values of the input override existing ones, so a key can be updated by setting it again
 	for k, v := range input {
-		vEnc := b64.StdEncoding.EncodeToString([]byte(v))
-		out.WriteString(fmt.Sprintf("%s: %s\n", k, vEnc))
+		cfgData[k] = v
 	}
-	err = config.WriteConfig(envName, configName, out.Bytes())
-	if err != nil {
+	if err = store.Set(configName, cfgData); err != nil {
 		return err
 	}
 	io.Infof("config data saved successfully %s\n", emojiSucceed)
@@ -223,7 +236,7 @@ func setConfig(args []string, io cmdutil.IOStreams, cmd *cobra.Command) error {
 }
 
 // NewConfigDeleteCommand delete a config from local
-func NewConfigDeleteCommand(io cmdutil.IOStreams) *cobra.Command {
+func NewConfigDeleteCommand(c types.Args, io cmdutil.IOStreams) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:                   "del",
 		Aliases:               []string{"del"},
@@ -232,7 +245,7 @@ func NewConfigDeleteCommand(io cmdutil.IOStreams) *cobra.Command {
 		Long:                  "Delete config",
 		Example:               `vela config del <config-name>`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return deleteConfig(args, io, cmd)
+			return deleteConfig(c, args, io, cmd)
 		},
 		Annotations: map[string]string{
 			types.TagCommandType: types.TypeStart,
@@ -242,8 +255,8 @@ func NewConfigDeleteCommand(io cmdutil.IOStreams) *cobra.Command {
 	return cmd
 }
 
-func deleteConfig(args []string, io cmdutil.IOStreams, cmd *cobra.Command) error {
-	e, err := GetEnv(cmd)
+func deleteConfig(c types.Args, args []string, io cmdutil.IOStreams, cmd *cobra.Command) error {
+	store, err := getConfigStore(c, cmd)
 	if err != nil {
 		return err
 	}
@@ -251,7 +264,7 @@ func deleteConfig(args []string, io cmdutil.IOStreams, cmd *cobra.Command) error
 		return fmt.Errorf("must specify config name, vela config get <name>")
 	}
 	configName := args[0]
-	err = config.DeleteConfig(e.Name, configName)
+	err = store.Delete(configName)
 	if err != nil {
 		return err
 	}
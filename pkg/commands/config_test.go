@@ -8,6 +8,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/oam-dev/kubevela/apis/types"
 	cmdutil "github.com/oam-dev/kubevela/pkg/commands/util"
 	"github.com/oam-dev/kubevela/pkg/utils/system"
 )
@@ -26,7 +27,7 @@ func TestConfigCommand(t *testing.T) {
 
 	// vela config set test a=b
 	io := cmdutil.IOStreams{In: os.Stdin, Out: os.Stdout, ErrOut: os.Stderr}
-	err = setConfig([]string{"test", "a=b"}, io, nil)
+	err = setConfig(types.Args{}, []string{"test", "a=b"}, io, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -34,15 +35,29 @@ func TestConfigCommand(t *testing.T) {
 	// vela config get test
 	var b bytes.Buffer
 	io.Out = &b
-	err = getConfig([]string{"test"}, io, nil)
+	err = getConfig(types.Args{}, []string{"test"}, io, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
 	assert.Equal(t, "Data:\n  a: b\n", b.String())
 
+	// vela config set test a=x, the existing key is updated
+	io.Out = os.Stdout
+	err = setConfig(types.Args{}, []string{"test", "a=x"}, io, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b = bytes.Buffer{}
+	io.Out = &b
+	err = getConfig(types.Args{}, []string{"test"}, io, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "Data:\n  a: x\n", b.String())
+
 	// vela config set test2 c=d
 	io.Out = os.Stdout
-	err = setConfig([]string{"test2", "c=d"}, io, nil)
+	err = setConfig(types.Args{}, []string{"test2", "c=d"}, io, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -50,15 +65,27 @@ func TestConfigCommand(t *testing.T) {
 	// vela config ls
 	b = bytes.Buffer{}
 	io.Out = &b
-	err = ListConfigs(io, nil)
+	err = ListConfigs(types.Args{}, io, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
 	assert.Equal(t, "NAME \ntest \ntest2\n", b.String())
 
+	// vela config ls -o json
+	cmd := NewConfigListCommand(types.Args{}, io)
+	cmd.Flags().String("env", "", "")
+	assert.NoError(t, cmd.Flags().Set(cmdutil.OutputFlag, cmdutil.OutputJSON))
+	b = bytes.Buffer{}
+	io.Out = &b
+	err = ListConfigs(types.Args{}, io, cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.JSONEq(t, `[{"name":"test","keys":["a"]},{"name":"test2","keys":["c"]}]`, b.String())
+
 	// vela config del test
 	io.Out = os.Stdout
-	err = deleteConfig([]string{"test"}, io, nil)
+	err = deleteConfig(types.Args{}, []string{"test"}, io, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -66,7 +93,7 @@ func TestConfigCommand(t *testing.T) {
 	// vela config ls
 	b = bytes.Buffer{}
 	io.Out = &b
-	err = ListConfigs(io, nil)
+	err = ListConfigs(types.Args{}, io, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -18,6 +18,7 @@ import (
 	"github.com/oam-dev/kubevela/apis/types"
 	cmdutil "github.com/oam-dev/kubevela/pkg/commands/util"
 	"github.com/oam-dev/kubevela/pkg/server"
+	"github.com/oam-dev/kubevela/pkg/server/auth"
 	"github.com/oam-dev/kubevela/pkg/server/util"
 	"github.com/oam-dev/kubevela/pkg/utils/helm"
 	"github.com/oam-dev/kubevela/pkg/utils/system"
@@ -65,6 +66,14 @@ func NewDashboardCommand(c types.Args, ioStreams cmdutil.IOStreams, frontendSour
 	cmd.Flags().BoolVar(&o.development, "development", true, "Development mode.")
 	cmd.Flags().StringVar(&o.staticPath, "static", "", "specify local static file directory")
 	cmd.Flags().StringVar(&o.port, "port", util.DefaultDashboardPort, "specify port for dashboard")
+	cmd.Flags().StringVar(&o.auth.TokenFile, "token-auth-file", "", "Enable bearer token authentication with a csv file, each line is in format of token,user,uid,\"group1,group2\".")
+	cmd.Flags().BoolVar(&o.auth.TokenReview, "enable-token-review", false, "Enable bearer token authentication by Kubernetes TokenReview API.")
+	cmd.Flags().StringVar(&o.auth.OIDCIssuerURL, "oidc-issuer-url", "", "The issuer URL of OIDC ID tokens.")
+	cmd.Flags().StringVar(&o.auth.OIDCClientID, "oidc-client-id", "", "The client ID OIDC ID tokens must be issued for.")
+	cmd.Flags().StringVar(&o.auth.OIDCJWKSFile, "oidc-jwks-file", "", "Enable OIDC authentication with a JSON Web Key Set file to verify ID tokens.")
+	cmd.Flags().StringVar(&o.auth.OIDCUsernameClaim, "oidc-username-claim", auth.DefaultUsernameClaim, "The OIDC claim to use as the user name.")
+	cmd.Flags().StringVar(&o.auth.OIDCGroupsClaim, "oidc-groups-claim", auth.DefaultGroupsClaim, "The OIDC claim to use as the user groups.")
+	cmd.Flags().StringVar(&o.auth.PolicyFile, "authorization-policy-file", "", "A yaml file mapping users and groups to envs and verbs they are allowed to access.")
 	cmd.SetOut(ioStreams.Out)
 	return cmd
 }
@@ -78,6 +87,7 @@ type Options struct {
 	staticPath     string
 	port           string
 	frontendSource string
+	auth           auth.Options
 }
 
 // GetStaticPath gets the path of front-end directory
@@ -162,7 +172,7 @@ func SetupAPIServer(c types.Args, cmd *cobra.Command, o Options) error {
 	}
 
 	// Setup RESTful server
-	server, err := server.New(c, o.port, o.staticPath)
+	server, err := server.New(c, o.port, o.staticPath, o.auth)
 	if err != nil {
 		return err
 	}
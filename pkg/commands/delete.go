@@ -9,7 +9,6 @@ import (
 
 	"github.com/spf13/cobra"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
-	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // NewDeleteCommand Delete App
@@ -27,16 +26,15 @@ func NewDeleteCommand(c types.Args, ioStreams cmdutil.IOStreams) *cobra.Command
 	cmd.SetOut(ioStreams.Out)
 
 	cmd.RunE = func(cmd *cobra.Command, args []string) error {
-		newClient, err := client.New(c.Config, client.Options{Scheme: c.Schema})
-		if err != nil {
-			return err
-		}
 		o := &oam.DeleteOptions{}
-		o.Client = newClient
+		var err error
 		o.Env, err = GetEnv(cmd)
 		if err != nil {
 			return err
 		}
+		if o.Client, err = newClientForEnv(c, o.Env); err != nil {
+			return err
+		}
 		if len(args) < 1 {
 			return errors.New("must specify name for the app")
 		}
@@ -3,15 +3,22 @@ package commands
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 
 	"github.com/oam-dev/kubevela/apis/types"
 	cmdutil "github.com/oam-dev/kubevela/pkg/commands/util"
+	"github.com/oam-dev/kubevela/pkg/controller/standard.oam.dev/v1alpha1/routes/ingress"
+	"github.com/oam-dev/kubevela/pkg/server/apis"
+	"github.com/oam-dev/kubevela/pkg/utils/config"
 	"github.com/oam-dev/kubevela/pkg/utils/env"
 	"github.com/oam-dev/kubevela/pkg/utils/system"
 
 	"github.com/gosuri/uitable"
 	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	k8scmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -27,12 +34,12 @@ func NewEnvCommand(c types.Args, ioStream cmdutil.IOStreams) *cobra.Command {
 		},
 	}
 	cmd.SetOut(ioStream.Out)
-	cmd.AddCommand(NewEnvListCommand(ioStream), NewEnvInitCommand(c, ioStream), NewEnvSetCommand(ioStream), NewEnvDeleteCommand(ioStream))
+	cmd.AddCommand(NewEnvListCommand(c, ioStream), NewEnvInitCommand(c, ioStream), NewEnvSetCommand(ioStream), NewEnvDeleteCommand(c, ioStream))
 	return cmd
 }
 
 // NewEnvListCommand creates `env list` command for listing all environments
-func NewEnvListCommand(ioStream cmdutil.IOStreams) *cobra.Command {
+func NewEnvListCommand(c types.Args, ioStream cmdutil.IOStreams) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:                   "ls",
 		Aliases:               []string{"list"},
@@ -41,19 +48,34 @@ func NewEnvListCommand(ioStream cmdutil.IOStreams) *cobra.Command {
 		Long:                  "List all environments",
 		Example:               `vela env ls [env-name]`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return ListEnvs(args, ioStream)
+			newClient, err := client.New(c.Config, client.Options{Scheme: c.Schema})
+			if err != nil {
+				return err
+			}
+			p, err := cmdutil.NewPrinter(cmd, ioStream)
+			if err != nil {
+				return err
+			}
+			return ListEnvs(context.Background(), newClient, args, ioStream, p)
 		},
 		Annotations: map[string]string{
 			types.TagCommandType: types.TypeStart,
 		},
 	}
 	cmd.SetOut(ioStream.Out)
+	cmdutil.AddOutputFlag(cmd)
 	return cmd
 }
 
 // NewEnvInitCommand creates `env init` command for initializing environments
 func NewEnvInitCommand(c types.Args, ioStreams cmdutil.IOStreams) *cobra.Command {
 	var envArgs types.EnvMeta
+	var cluster types.ClusterRef
+	var clusterCAFile string
+	var issuerConfig types.IssuerConfig
+	var dns01 types.DNS01Solver
+	var limits types.LimitConfig
+	var resetGuardrails bool
 	var syncCluster bool
 	ctx := context.Background()
 	cmd := &cobra.Command{
@@ -63,6 +85,59 @@ func NewEnvInitCommand(c types.Args, ioStreams cmdutil.IOStreams) *cobra.Command
 		Long:                  "Create environment and set the currently using environment",
 		Example:               `vela env init test --namespace test --email my@email.com`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			switch envArgs.ConfigStore {
+			case "", config.StoreLocal, config.StoreEncrypted, config.StoreKubernetes:
+			default:
+				return fmt.Errorf("unknown config store %s, must be one of %s, %s and %s", envArgs.ConfigStore,
+					config.StoreLocal, config.StoreEncrypted, config.StoreKubernetes)
+			}
+			switch envArgs.RouteProvider {
+			case "", ingress.TypeNginx, ingress.TypeContour:
+			default:
+				return fmt.Errorf("unknown route provider %s, must be one of %s and %s", envArgs.RouteProvider,
+					ingress.TypeNginx, ingress.TypeContour)
+			}
+			if dns01.Provider != "" {
+				issuerConfig.DNS01 = &dns01
+			} else if cmd.Flags().Changed("dns01-secret") || cmd.Flags().Changed("dns01-secret-key") {
+				return fmt.Errorf("--dns01-provider must be set along with --dns01-secret and --dns01-secret-key")
+			}
+			for _, f := range []string{"issuer", "ingress-class", "dns01-provider", "ca-secret", "cluster-issuer"} {
+				if cmd.Flags().Changed(f) {
+					envArgs.IssuerConfig = &issuerConfig
+				}
+			}
+			for _, f := range []string{"limit-default", "limit-default-request", "limit-max"} {
+				if cmd.Flags().Changed(f) {
+					envArgs.Limits = &limits
+				}
+			}
+			if resetGuardrails {
+				// empty but not nil, so settings of the existing env are not kept
+				if envArgs.Quota == nil {
+					envArgs.Quota = map[string]string{}
+				}
+				if envArgs.Limits == nil {
+					envArgs.Limits = &limits
+				}
+				if envArgs.Isolation == "" {
+					envArgs.Isolation = env.IsolationOpen
+				}
+				if envArgs.IngressNamespaceSelector == nil {
+					envArgs.IngressNamespaceSelector = map[string]string{}
+				}
+			}
+			var err error
+			if cluster.Server != "" {
+				if clusterCAFile != "" {
+					if cluster.CertificateAuthorityData, err = ioutil.ReadFile(filepath.Clean(clusterCAFile)); err != nil {
+						return err
+					}
+				}
+				envArgs.Cluster = &cluster
+			} else if clusterCAFile != "" || cluster.InsecureSkipTLSVerify {
+				return fmt.Errorf("--cluster-server must be set along with --cluster-ca-file and --insecure-skip-tls-verify")
+			}
 			newClient, err := client.New(c.Config, client.Options{Scheme: c.Schema})
 			if err != nil {
 				return err
@@ -82,12 +157,34 @@ func NewEnvInitCommand(c types.Args, ioStreams cmdutil.IOStreams) *cobra.Command
 	cmd.Flags().StringVar(&envArgs.Namespace, "namespace", "", "specify K8s namespace for env")
 	cmd.Flags().StringVar(&envArgs.Email, "email", "", "specify email for production TLS Certificate notification")
 	cmd.Flags().StringVar(&envArgs.Domain, "domain", "", "specify domain your applications")
+	cmd.Flags().StringVar(&envArgs.HostPattern, "host-pattern", "", "specify how hosts of routes are generated from the domain, placeholders are {service}, {app}, {env} and {domain}, default to {service}.{app}.{domain}")
+	cmd.Flags().StringVar(&envArgs.ConfigStore, "config-store", "", "specify where configs of the env are stored: local (default), encrypted or kubernetes")
+	cmd.Flags().StringVar(&envArgs.KubeConfig, "kubeconfig", "", "specify path of the kubeconfig file for the cluster of env, default to the one vela runs with")
+	cmd.Flags().StringVar(&envArgs.KubeContext, "context", "", "specify the kubeconfig context for the cluster of env, default to the current context")
+	cmd.Flags().StringVar(&cluster.Server, "cluster-server", "", "specify address of the API server for the cluster of env, credentials still come from the kubeconfig context")
+	cmd.Flags().StringVar(&clusterCAFile, "cluster-ca-file", "", "specify path of the CA certificate file of the API server set by --cluster-server")
+	cmd.Flags().BoolVar(&cluster.InsecureSkipTLSVerify, "insecure-skip-tls-verify", false, "skip verifying the certificate of the API server set by --cluster-server")
+	cmd.Flags().StringVar(&envArgs.RouteProvider, "route-provider", "", "specify the ingress controller serving routes: nginx (default) or contour")
+	cmd.Flags().StringVar(&issuerConfig.Kind, "issuer", "", "specify the certificate issuer for routes: acme (default), acme-staging, ca or selfsigned")
+	cmd.Flags().StringVar(&issuerConfig.IngressClass, "ingress-class", "", "specify the ingress class solving ACME HTTP01 challenges, default to the route provider")
+	cmd.Flags().StringVar(&dns01.Provider, "dns01-provider", "", "solve ACME challenges by DNS01 with the provider: cloudflare or digitalocean")
+	cmd.Flags().StringVar(&dns01.SecretName, "dns01-secret", "", "specify the Secret holding API token of the DNS01 provider")
+	cmd.Flags().StringVar(&dns01.SecretKey, "dns01-secret-key", "api-token", "specify the key of API token in the Secret of the DNS01 provider")
+	cmd.Flags().StringVar(&issuerConfig.CASecret, "ca-secret", "", "specify the Secret holding the CA key pair for the ca issuer")
+	cmd.Flags().StringVar(&issuerConfig.ClusterIssuer, "cluster-issuer", "", "use an existing ClusterIssuer instead of creating an issuer for the env")
+	cmd.Flags().StringToStringVar(&envArgs.Quota, "quota", nil, "specify hard limits of ResourceQuota in the env namespace, e.g. cpu=4,memory=8Gi,pods=20")
+	cmd.Flags().StringToStringVar(&limits.Default, "limit-default", nil, "specify default resource limits of each container, e.g. cpu=500m,memory=512Mi")
+	cmd.Flags().StringToStringVar(&limits.DefaultRequest, "limit-default-request", nil, "specify default resource requests of each container, e.g. cpu=100m,memory=128Mi")
+	cmd.Flags().StringToStringVar(&limits.Max, "limit-max", nil, "specify max resource limits of each container, e.g. cpu=2,memory=2Gi")
+	cmd.Flags().StringVar(&envArgs.Isolation, "isolation", "", "specify network isolation of the env namespace: open (default), namespace-only or deny-all")
+	cmd.Flags().StringToStringVar(&envArgs.IngressNamespaceSelector, "ingress-namespace-selector", nil, "specify labels of the namespace of the ingress controller, which namespace-only isolation allows traffic from, default to app.kubernetes.io/name=ingress-nginx")
+	cmd.Flags().BoolVar(&resetGuardrails, "reset-guardrails", false, "remove quota, limits and isolation of the env except those set along with this flag")
 	cmd.Flags().BoolVarP(&syncCluster, "sync", "s", true, "synchronize capabilities from cluster into local")
 	return cmd
 }
 
 // NewEnvDeleteCommand creates `env delete` command for deleting environments
-func NewEnvDeleteCommand(ioStreams cmdutil.IOStreams) *cobra.Command {
+func NewEnvDeleteCommand(c types.Args, ioStreams cmdutil.IOStreams) *cobra.Command {
 	ctx := context.Background()
 	cmd := &cobra.Command{
 		Use:                   "delete",
@@ -96,7 +193,11 @@ func NewEnvDeleteCommand(ioStreams cmdutil.IOStreams) *cobra.Command {
 		Long:                  "Delete environment",
 		Example:               `vela env delete test`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return DeleteEnv(ctx, args, ioStreams)
+			newClient, err := client.New(c.Config, client.Options{Scheme: c.Schema})
+			if err != nil {
+				return err
+			}
+			return DeleteEnv(ctx, newClient, args, ioStreams)
 		},
 		Annotations: map[string]string{
 			types.TagCommandType: types.TypeStart,
@@ -127,32 +228,39 @@ func NewEnvSetCommand(ioStreams cmdutil.IOStreams) *cobra.Command {
 }
 
 // ListEnvs shows info of all environments
-func ListEnvs(args []string, ioStreams cmdutil.IOStreams) error {
-	table := uitable.New()
-	table.MaxColWidth = 60
-	table.AddRow("NAME", "CURRENT", "NAMESPACE", "EMAIL", "DOMAIN")
+func ListEnvs(ctx context.Context, c client.Client, args []string, ioStreams cmdutil.IOStreams, p *cmdutil.Printer) error {
 	var envName = ""
 	if len(args) > 0 {
 		envName = args[0]
 	}
-	envList, err := env.ListEnvs(envName)
+	envList, err := env.ListEnvs(ctx, c, envName)
 	if err != nil {
 		return err
 	}
-	for _, env := range envList {
-		table.AddRow(env.Name, env.Current, env.Namespace, env.Email, env.Domain)
+	environments := make([]apis.Environment, 0, len(envList))
+	for _, e := range envList {
+		environments = append(environments, env.DescribeEnv(ctx, c, e))
+	}
+	if p.Structured() {
+		return p.Print(environments)
+	}
+	table := uitable.New()
+	table.MaxColWidth = 60
+	table.AddRow("NAME", "CURRENT", "NAMESPACE", "CLUSTER", "QUOTA", "EMAIL", "DOMAIN")
+	for _, e := range environments {
+		table.AddRow(e.EnvName, e.Current, e.Namespace, e.Cluster, e.Quota, e.Email, e.Domain)
 	}
 	ioStreams.Info(table.String())
 	return nil
 }
 
 // DeleteEnv deletes an environment
-func DeleteEnv(ctx context.Context, args []string, ioStreams cmdutil.IOStreams) error {
+func DeleteEnv(ctx context.Context, c client.Client, args []string, ioStreams cmdutil.IOStreams) error {
 	if len(args) < 1 {
 		return fmt.Errorf("you must specify environment name for 'vela env delete' command")
 	}
 	for _, envName := range args {
-		msg, err := env.DeleteEnv(envName)
+		msg, err := env.DeleteEnv(ctx, c, envName)
 		if err != nil {
 			return err
 		}
@@ -213,3 +321,31 @@ func GetEnv(cmd *cobra.Command) (*types.EnvMeta, error) {
 	}
 	return env.GetEnvByName(envName)
 }
+
+// argsForEnv returns args to access the cluster of env, they're c itself if the env isn't bound to a cluster
+func argsForEnv(c types.Args, envMeta *types.EnvMeta) (types.Args, error) {
+	restConf, err := env.RestConfig(envMeta, c.Config)
+	if err != nil {
+		return c, err
+	}
+	return types.Args{Config: restConf, Schema: c.Schema}, nil
+}
+
+// newClientForEnv creates a client for the cluster of env
+func newClientForEnv(c types.Args, envMeta *types.EnvMeta) (client.Client, error) {
+	envArgs, err := argsForEnv(c, envMeta)
+	if err != nil {
+		return nil, err
+	}
+	return client.New(envArgs.Config, client.Options{Scheme: envArgs.Schema})
+}
+
+// newFactoryForEnv creates the kubectl factory for the cluster and namespace of env, c must be the args of env
+func newFactoryForEnv(c types.Args, envMeta *types.EnvMeta) k8scmdutil.Factory {
+	if env.IsBoundToCluster(envMeta) {
+		return k8scmdutil.NewFactory(k8scmdutil.NewMatchVersionFlags(cmdutil.NewRestConfigGetterByConfig(c.Config, envMeta.Namespace)))
+	}
+	cf := genericclioptions.NewConfigFlags(true)
+	cf.Namespace = &envMeta.Namespace
+	return k8scmdutil.NewFactory(k8scmdutil.NewMatchVersionFlags(cf))
+}
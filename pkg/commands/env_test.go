@@ -64,17 +64,19 @@ func TestENV(t *testing.T) {
 	// List all env
 	var b bytes.Buffer
 	ioStream.Out = &b
-	err = ListEnvs([]string{}, ioStream)
+	p, err := cmdutil.NewPrinter(nil, ioStream)
 	assert.NoError(t, err)
-	assert.Equal(t, "NAME   \tCURRENT\tNAMESPACE\tEMAIL\tDOMAIN\ndefault\t       \tdefault  \t     \t      \nenv1   \t*      \ttest1    \t     \t      \n", b.String())
+	err = ListEnvs(ctx, client, []string{}, ioStream, p)
+	assert.NoError(t, err)
+	assert.Equal(t, "NAME   \tCURRENT\tNAMESPACE\tCLUSTER\tQUOTA\tEMAIL\tDOMAIN\ndefault\t       \tdefault  \t       \t     \t     \t      \nenv1   \t*      \ttest1    \t       \t     \t     \t      \n", b.String())
 	b.Reset()
-	err = ListEnvs([]string{"env1"}, ioStream)
+	err = ListEnvs(ctx, client, []string{"env1"}, ioStream, p)
 	assert.NoError(t, err)
-	assert.Equal(t, "NAME\tCURRENT\tNAMESPACE\tEMAIL\tDOMAIN\nenv1\t       \ttest1    \t     \t      \n", b.String())
+	assert.Equal(t, "NAME\tCURRENT\tNAMESPACE\tCLUSTER\tQUOTA\tEMAIL\tDOMAIN\nenv1\t       \ttest1    \t       \t     \t     \t      \n", b.String())
 	ioStream.Out = os.Stdout
 
 	// can not delete current env
-	err = DeleteEnv(ctx, []string{"env1"}, ioStream)
+	err = DeleteEnv(ctx, client, []string{"env1"}, ioStream)
 	assert.Error(t, err)
 
 	// set as default env
@@ -90,7 +92,7 @@ func TestENV(t *testing.T) {
 	}, gotEnv)
 
 	// delete env
-	err = DeleteEnv(ctx, []string{"env1"}, ioStream)
+	err = DeleteEnv(ctx, client, []string{"env1"}, ioStream)
 	assert.NoError(t, err)
 
 	// can not set as a non-exist env
@@ -101,3 +103,12 @@ func TestENV(t *testing.T) {
 	err = SetEnv([]string{"default"}, ioStream)
 	assert.NoError(t, err)
 }
+
+func TestEnvInitDNS01Flags(t *testing.T) {
+	ioStream := cmdutil.IOStreams{In: os.Stdin, Out: &bytes.Buffer{}, ErrOut: &bytes.Buffer{}}
+	cmd := NewEnvInitCommand(types.Args{}, ioStream)
+	cmd.SetArgs([]string{"prod", "--dns01-secret", "cf-token"})
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+	assert.EqualError(t, cmd.Execute(), "--dns01-provider must be set along with --dns01-secret and --dns01-secret-key")
+}
@@ -115,9 +115,10 @@ func (o *VelaExecOptions) Init(ctx context.Context, c *cobra.Command, argsIn []s
 	o.Env = env
 	o.App = app
 
-	cf := genericclioptions.NewConfigFlags(true)
-	cf.Namespace = &o.Env.Namespace
-	o.f = k8scmdutil.NewFactory(k8scmdutil.NewMatchVersionFlags(cf))
+	if o.VelaC, err = argsForEnv(o.VelaC, o.Env); err != nil {
+		return err
+	}
+	o.f = newFactoryForEnv(o.VelaC, o.Env)
 
 	if o.ClientSet == nil {
 		c, err := kubernetes.NewForConfig(o.VelaC.Config)
@@ -0,0 +1,148 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gosuri/uitable"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/application"
+	cmdutil "github.com/oam-dev/kubevela/pkg/commands/util"
+)
+
+// NewHistoryCommand creates `history` command for listing revisions of an application
+func NewHistoryCommand(c types.Args, ioStreams cmdutil.IOStreams) *cobra.Command {
+	ctx := context.Background()
+	cmd := &cobra.Command{
+		Use:                   "history APP_NAME",
+		DisableFlagsInUseLine: true,
+		Short:                 "List revisions of an application",
+		Long: "List deployed revisions of an application. A revision is recorded each time the app is deployed or " +
+			"rolled back, use --revision to show what changed in the Appfile of a revision.",
+		Example: `vela history frontend
+vela history frontend --revision 2`,
+		Annotations: map[string]string{
+			types.TagCommandType: types.TypeApp,
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 1 {
+				return errors.New("must specify name for the app")
+			}
+			env, err := GetEnv(cmd)
+			if err != nil {
+				return err
+			}
+			revision, err := cmd.Flags().GetInt("revision")
+			if err != nil {
+				return err
+			}
+			newClient, err := newClientForEnv(c, env)
+			if err != nil {
+				return err
+			}
+			if revision != 0 {
+				return printRevisionDiff(ctx, newClient, ioStreams, env, args[0], revision)
+			}
+			return printHistory(ctx, newClient, ioStreams, env, args[0])
+		},
+	}
+	cmd.Flags().IntP("revision", "r", 0, "show the Appfile diff of the revision against its previous revision")
+	cmd.SetOut(ioStreams.Out)
+	return cmd
+}
+
+func printHistory(ctx context.Context, c client.Client, ioStreams cmdutil.IOStreams, env *types.EnvMeta, appName string) error {
+	revisions, err := application.ListRevisions(ctx, c, env.Namespace, appName)
+	if err != nil {
+		return err
+	}
+	if len(revisions) == 0 {
+		return fmt.Errorf("no revision found for app %s in env %s", appName, env.Name)
+	}
+	table := uitable.New()
+	table.AddRow("REVISION", "CREATED-TIME", "SERVICES", "DESCRIPTION")
+	for _, rev := range revisions {
+		table.AddRow(rev.Revision, rev.CreateTime.Format(time.RFC3339), len(rev.Components), rev.Description())
+	}
+	ioStreams.Info(table.String())
+	return nil
+}
+
+func printRevisionDiff(ctx context.Context, c client.Client, ioStreams cmdutil.IOStreams, env *types.EnvMeta,
+	appName string, revision int) error {
+	revisions, err := application.ListRevisions(ctx, c, env.Namespace, appName)
+	if err != nil {
+		return err
+	}
+	for i, rev := range revisions {
+		if rev.Revision != revision {
+			continue
+		}
+		var prev *application.Revision
+		if i > 0 {
+			prev = revisions[i-1]
+		}
+		diff, err := application.DiffRevisions(prev, rev)
+		if err != nil {
+			return err
+		}
+		if diff == "" && prev != nil {
+			ioStreams.Infof("Appfile of revision %d is the same as revision %d\n", revision, prev.Revision)
+			return nil
+		}
+		ioStreams.Info(diff)
+		return nil
+	}
+	return fmt.Errorf("revision %d of app %s not found", revision, appName)
+}
+
+// NewRollbackCommand creates `rollback` command for rolling an application back to a revision
+func NewRollbackCommand(c types.Args, ioStreams cmdutil.IOStreams) *cobra.Command {
+	ctx := context.Background()
+	cmd := &cobra.Command{
+		Use:                   "rollback APP_NAME",
+		DisableFlagsInUseLine: true,
+		Short:                 "Rollback an application to a revision",
+		Long: "Rollback an application to a revision listed by 'vela history', services and traits of the revision " +
+			"are applied again and the rollback is recorded as a new revision.",
+		Example: `vela rollback frontend --to 2`,
+		Annotations: map[string]string{
+			types.TagCommandType: types.TypeApp,
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 1 {
+				return errors.New("must specify name for the app")
+			}
+			to, err := cmd.Flags().GetInt("to")
+			if err != nil {
+				return err
+			}
+			if to <= 0 {
+				return errors.New("must specify the revision to rollback to with --to")
+			}
+			env, err := GetEnv(cmd)
+			if err != nil {
+				return err
+			}
+			newClient, err := newClientForEnv(c, env)
+			if err != nil {
+				return err
+			}
+			rev, err := application.Rollback(ctx, newClient, env, args[0], to)
+			if err != nil {
+				return err
+			}
+			ioStreams.Infof("App %s has been rolled back to revision %d, recorded as revision %d\n",
+				args[0], to, rev.Revision)
+			return nil
+		},
+	}
+	cmd.Flags().Int("to", 0, "the revision to rollback to")
+	cmd.SetOut(ioStreams.Out)
+	return cmd
+}
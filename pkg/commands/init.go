@@ -91,6 +91,9 @@ func NewInitCommand(c types.Args, ioStreams cmdutil.IOStreams) *cobra.Command {
 			if err != nil {
 				return err
 			}
+			if _, err = o.app.RecordRevision(ctx, o.client, appconfig, comps, scopes); err != nil {
+				return err
+			}
 			deployStatus, err := printTrackingDeployStatus(ctx, o.client, o.IOStreams, o.workloadName, o.appName, o.Env)
 			if err != nil {
 				return err
@@ -1,33 +1,47 @@
 package commands
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
 	"fmt"
+	"hash/fnv"
+	"io"
 	"regexp"
+	"sync"
 	"text/template"
 	"time"
 
-	"github.com/oam-dev/kubevela/apis/types"
-	"github.com/oam-dev/kubevela/pkg/application"
-	"github.com/oam-dev/kubevela/pkg/commands/util"
-	cmdutil "github.com/oam-dev/kubevela/pkg/commands/util"
-
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam"
 	"github.com/fatih/color"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/wercker/stern/stern"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/kubernetes"
+
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/application"
+	cmdutil "github.com/oam-dev/kubevela/pkg/commands/util"
 )
 
+// defaultLogsSince is how far back logs are shown by default
+const defaultLogsSince = 48 * time.Hour
+
 // NewLogsCommand creates `logs` command to tail logs of application
 func NewLogsCommand(c types.Args, ioStreams cmdutil.IOStreams) *cobra.Command {
 	largs := &Args{C: c}
 	cmd := &cobra.Command{}
 	cmd.Use = "logs"
 	cmd.Short = "Tail logs for application"
-	cmd.Long = "Tail logs for application"
+	cmd.Long = "Tail logs of containers of services in an application. Logs of multiple services are aggregated " +
+		"and prefixed with the service name, use --all or --service to select services."
+	cmd.Example = `vela logs testapp
+vela logs testapp --all --since 10m --grep error
+vela logs testapp -s frontend -s backend --exclude healthz
+vela logs testapp -s frontend --previous`
 	cmd.RunE = func(cmd *cobra.Command, args []string) error {
 		if len(args) < 1 {
 			ioStreams.Errorf("please specify app name")
@@ -43,6 +57,9 @@ func NewLogsCommand(c types.Args, ioStreams cmdutil.IOStreams) *cobra.Command {
 		}
 		largs.App = app
 		largs.Env = env
+		if largs.C, err = argsForEnv(c, env); err != nil {
+			return err
+		}
 		ctx := context.Background()
 		if err := largs.Run(ctx, ioStreams); err != nil {
 			return err
@@ -53,6 +70,15 @@ func NewLogsCommand(c types.Args, ioStreams cmdutil.IOStreams) *cobra.Command {
 		types.TagCommandType: types.TypeApp,
 	}
 	cmd.Flags().StringVarP(&largs.Output, "output", "o", "default", "output format for logs, support: [default, raw, json]")
+	cmd.Flags().BoolVar(&largs.All, "all", false, "show logs of all services in the app")
+	cmd.Flags().StringSliceVarP(&largs.Services, "service", "s", nil, "show logs of the service, could be repeated")
+	cmd.Flags().DurationVar(&largs.Since, "since", defaultLogsSince, "only show logs newer than a relative duration like 5s, 2m, or 3h")
+	cmd.Flags().Int64Var(&largs.Tail, "tail", -1, "the number of lines from the end of the logs to show, all lines are shown if it's negative")
+	cmd.Flags().StringVarP(&largs.Container, "container", "c", ".*", "regex of names of containers to show logs of")
+	cmd.Flags().StringSliceVar(&largs.Grep, "grep", nil, "only show lines matching any of the regexes")
+	cmd.Flags().StringSliceVar(&largs.Exclude, "exclude", nil, "don't show lines matching any of the regexes")
+	cmd.Flags().BoolVarP(&largs.Previous, "previous", "p", false, "show logs of the previous terminated instance of "+
+		"containers, e.g. crashed ones, rather than following logs of running containers")
 	return cmd
 }
 
@@ -62,34 +88,91 @@ type Args struct {
 	Env    *types.EnvMeta
 	C      types.Args
 	App    *application.Application
+
+	// All selects all services, otherwise Services are selected, the user is asked to choose one if neither is set
+	All       bool
+	Services  []string
+	Since     time.Duration
+	Tail      int64
+	Container string
+	Grep      []string
+	Exclude   []string
+	Previous  bool
+
+	// ClientSet is created from C if it's nil
+	ClientSet kubernetes.Interface
+}
+
+// serviceColors are colors of service name prefixes, services are colored by their order in the selection
+var serviceColors = []color.Attribute{color.FgHiCyan, color.FgHiGreen, color.FgHiMagenta, color.FgHiYellow,
+	color.FgHiBlue, color.FgHiRed}
+
+// podColors are colors of pod and container names, the same as stern uses for following logs
+var podColors = [][2]*color.Color{
+	{color.New(color.FgHiCyan), color.New(color.FgCyan)},
+	{color.New(color.FgHiGreen), color.New(color.FgGreen)},
+	{color.New(color.FgHiMagenta), color.New(color.FgMagenta)},
+	{color.New(color.FgHiYellow), color.New(color.FgYellow)},
+	{color.New(color.FgHiBlue), color.New(color.FgBlue)},
+	{color.New(color.FgHiRed), color.New(color.FgRed)},
+}
+
+// serviceLog is a line of logs with the service it belongs to, it's printed by json output
+type serviceLog struct {
+	Service string `json:"service"`
+	stern.Log
 }
 
 // Run refer to the implementation at https://github.com/oam-dev/stern/blob/master/stern/main.go
 func (l *Args) Run(ctx context.Context, ioStreams cmdutil.IOStreams) error {
-
-	clientSet, err := kubernetes.NewForConfig(l.C.Config)
+	if l.ClientSet == nil {
+		clientSet, err := kubernetes.NewForConfig(l.C.Config)
+		if err != nil {
+			return err
+		}
+		l.ClientSet = clientSet
+	}
+	services, err := l.selectServices()
 	if err != nil {
 		return err
 	}
-	compName, err := util.AskToChooseOneService(l.App.GetComponents())
+	if l.Since <= 0 {
+		return errors.New("--since must be positive")
+	}
+	container, err := regexp.Compile(l.Container)
 	if err != nil {
-		return err
+		return fmt.Errorf("fail to compile '%s' for containers of logs query", l.Container)
 	}
-	// TODO(wonderflow): we could get labels from service to narrow the pods scope selected
-	labelSelector := labels.Everything()
-	pod, err := regexp.Compile(compName + "-.*")
+	include, err := compileRegexes(l.Grep)
 	if err != nil {
-		return fmt.Errorf("fail to compile '%s' for logs query", compName+".*")
+		return err
 	}
-	container := regexp.MustCompile(".*")
-	namespace := l.Env.Namespace
-	added, removed, err := stern.Watch(ctx, clientSet.CoreV1().Pods(namespace), pod, container, nil, stern.RUNNING, labelSelector)
+	exclude, err := compileRegexes(l.Exclude)
 	if err != nil {
 		return err
 	}
-	tails := make(map[string]*stern.Tail)
-	logC := make(chan string, 1024)
+	templates := make(map[string]*template.Template, len(services))
+	for i, svc := range services {
+		if templates[svc], err = l.logTemplate(svc, serviceColors[i%len(serviceColors)]); err != nil {
+			return err
+		}
+	}
+	if l.Previous {
+		return l.printPrevious(ctx, ioStreams, services, templates, container, include, exclude)
+	}
 
+	namespace := l.Env.Namespace
+	tailOpts := &stern.TailOptions{
+		Timestamps:   true,
+		SinceSeconds: int64(l.Since.Seconds()),
+		Exclude:      exclude,
+		Include:      include,
+		Namespace:    false,
+	}
+	if l.Tail >= 0 {
+		tailOpts.TailLines = &l.Tail
+	}
+	logC := make(chan string, 1024)
 	go func() {
 		for {
 			select {
@@ -101,22 +184,101 @@ func (l *Args) Run(ctx context.Context, ioStreams cmdutil.IOStreams) error {
 		}
 	}()
 
+	var mu sync.Mutex
+	tails := make(map[string]*stern.Tail)
+	for _, svc := range services {
+		// pods are selected by the label of the service, so the pod filter matches all
+		added, removed, err := stern.Watch(ctx, l.ClientSet.CoreV1().Pods(namespace), regexp.MustCompile(".*"), container,
+			nil, stern.RUNNING, serviceSelector(svc))
+		if err != nil {
+			return err
+		}
+		tmpl := templates[svc]
+		go func() {
+			for p := range added {
+				id := p.GetID()
+				mu.Lock()
+				if tails[id] != nil {
+					mu.Unlock()
+					continue
+				}
+				tail := stern.NewTail(p.Namespace, p.Pod, p.Container, tmpl, tailOpts)
+				tails[id] = tail
+				mu.Unlock()
+
+				tail.Start(ctx, l.ClientSet.CoreV1().Pods(p.Namespace), logC)
+			}
+		}()
+
+		go func() {
+			for p := range removed {
+				id := p.GetID()
+				mu.Lock()
+				if tails[id] != nil {
+					tails[id].Close()
+					delete(tails, id)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	<-ctx.Done()
+
+	return nil
+}
+
+// selectServices returns services to show logs of, they must exist in the app
+func (l *Args) selectServices() ([]string, error) {
+	all := l.App.GetComponents()
+	if l.All {
+		if len(all) == 0 {
+			return nil, fmt.Errorf("no service exist in the application")
+		}
+		return all, nil
+	}
+	if len(l.Services) == 0 {
+		svc, err := cmdutil.AskToChooseOneService(all)
+		if err != nil {
+			return nil, err
+		}
+		return []string{svc}, nil
+	}
+	var services []string
+	seen := make(map[string]bool)
+	for _, svc := range l.Services {
+		if _, ok := l.App.Services[svc]; !ok {
+			return nil, fmt.Errorf(ErrServiceNotFound, svc)
+		}
+		if !seen[svc] {
+			seen[svc] = true
+			services = append(services, svc)
+		}
+	}
+	return services, nil
+}
+
+// logTemplate returns the template of log lines of the service, lines are prefixed with the colored service name
+// unless the output is raw
+func (l *Args) logTemplate(svc string, svcColor color.Attribute) (*template.Template, error) {
 	var t string
 	switch l.Output {
 	case "default":
 		if color.NoColor {
-			t = "{{.PodName}} {{.ContainerName}} {{.Message}}"
+			t = "{{service}} {{.PodName}} {{.ContainerName}} {{.Message}}"
 		} else {
-			t = "{{color .PodColor .PodName}} {{color .ContainerColor .ContainerName}} {{.Message}}"
+			t = "{{service}} {{color .PodColor .PodName}} {{color .ContainerColor .ContainerName}} {{.Message}}"
 		}
 	case "raw":
 		t = "{{.Message}}"
 	case "json":
 		t = "{{json .}}\n"
+	default:
+		return nil, fmt.Errorf("unknown output format %s for logs, support: [default, raw, json]", l.Output)
 	}
 	funs := map[string]interface{}{
-		"json": func(in interface{}) (string, error) {
-			b, err := json.Marshal(in)
+		"json": func(in stern.Log) (string, error) {
+			b, err := json.Marshal(serviceLog{Service: svc, Log: in})
 			if err != nil {
 				return "", err
 			}
@@ -125,46 +287,148 @@ func (l *Args) Run(ctx context.Context, ioStreams cmdutil.IOStreams) error {
 		"color": func(color color.Color, text string) string {
 			return color.SprintFunc()(text)
 		},
+		"service": func() string {
+			return color.New(svcColor, color.Bold).Sprint(svc)
+		},
 	}
 	template, err := template.New("log").Funcs(funs).Parse(t)
 	if err != nil {
-		return errors.Wrap(err, "unable to parse template")
+		return nil, errors.Wrap(err, "unable to parse template")
 	}
+	return template, nil
+}
 
-	go func() {
-		for p := range added {
-			id := p.GetID()
-			if tails[id] != nil {
-				continue
+// previousTarget is a terminated container of a service whose previous logs are shown
+type previousTarget struct {
+	service string
+	stern.Target
+}
+
+// printPrevious prints logs of the previous terminated instance of containers of the services, containers which
+// have never been terminated are skipped
+func (l *Args) printPrevious(ctx context.Context, ioStreams cmdutil.IOStreams, services []string,
+	templates map[string]*template.Template, container *regexp.Regexp, include, exclude []*regexp.Regexp) error {
+	targets, err := l.previousTargets(ctx, services, container)
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		return fmt.Errorf("no terminated containers found in services %v", services)
+	}
+	since := int64(l.Since.Seconds())
+	for _, target := range targets {
+		opts := &corev1.PodLogOptions{
+			Container:    target.Container,
+			Previous:     true,
+			Timestamps:   true,
+			SinceSeconds: &since,
+		}
+		if l.Tail >= 0 {
+			opts.TailLines = &l.Tail
+		}
+		stream, err := l.ClientSet.CoreV1().Pods(target.Namespace).GetLogs(target.Pod, opts).Stream(ctx)
+		if err != nil {
+			return fmt.Errorf("get previous logs of %s/%s err %w", target.Pod, target.Container, err)
+		}
+		err = printLogLines(ioStreams.Out, stream, templates[target.service], target.Target, include, exclude)
+		_ = stream.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// previousTargets returns containers of pods of the services which have been terminated
+func (l *Args) previousTargets(ctx context.Context, services []string, container *regexp.Regexp) ([]previousTarget, error) {
+	var targets []previousTarget
+	for _, svc := range services {
+		pods, err := l.ClientSet.CoreV1().Pods(l.Env.Namespace).List(ctx, metav1.ListOptions{
+			LabelSelector: serviceSelector(svc).String(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, pod := range pods.Items {
+			for _, status := range pod.Status.ContainerStatuses {
+				if !container.MatchString(status.Name) || status.LastTerminationState.Terminated == nil {
+					continue
+				}
+				targets = append(targets, previousTarget{
+					service: svc,
+					Target:  stern.Target{Namespace: pod.Namespace, Pod: pod.Name, Container: status.Name},
+				})
 			}
-			// 48h
-			dur, _ := time.ParseDuration("48h")
-			tail := stern.NewTail(p.Namespace, p.Pod, p.Container, template, &stern.TailOptions{
-				Timestamps:   true,
-				SinceSeconds: int64(dur.Seconds()),
-				Exclude:      nil,
-				Include:      nil,
-				Namespace:    false,
-				TailLines:    nil, // default for all logs
-			})
-			tails[id] = tail
-
-			tail.Start(ctx, clientSet.CoreV1().Pods(p.Namespace), logC)
 		}
-	}()
+	}
+	return targets, nil
+}
 
-	go func() {
-		for p := range removed {
-			id := p.GetID()
-			if tails[id] == nil {
-				continue
+// printLogLines prints lines read from r by the template, lines are filtered by include and exclude regexes
+func printLogLines(out io.Writer, r io.Reader, tmpl *template.Template, target stern.Target,
+	include, exclude []*regexp.Regexp) error {
+	podColor, containerColor := determinePodColor(target.Pod)
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadString('\n')
+		if line != "" && matchLogLine(line, include, exclude) {
+			if err := tmpl.Execute(out, stern.Log{
+				Message:        line,
+				Namespace:      target.Namespace,
+				PodName:        target.Pod,
+				ContainerName:  target.Container,
+				PodColor:       podColor,
+				ContainerColor: containerColor,
+			}); err != nil {
+				return err
 			}
-			tails[id].Close()
-			delete(tails, id)
 		}
-	}()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
 
-	<-ctx.Done()
+func serviceSelector(svc string) labels.Selector {
+	return labels.SelectorFromSet(map[string]string{oam.LabelAppComponent: svc})
+}
 
-	return nil
+func compileRegexes(exprs []string) ([]*regexp.Regexp, error) {
+	var regexes []*regexp.Regexp
+	for _, expr := range exprs {
+		r, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("fail to compile '%s' for logs query", expr)
+		}
+		regexes = append(regexes, r)
+	}
+	return regexes, nil
+}
+
+// matchLogLine returns whether the line should be shown, the same as stern filters lines of following logs
+func matchLogLine(line string, include, exclude []*regexp.Regexp) bool {
+	for _, r := range exclude {
+		if r.MatchString(line) {
+			return false
+		}
+	}
+	if len(include) == 0 {
+		return true
+	}
+	for _, r := range include {
+		if r.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+func determinePodColor(podName string) (podColor, containerColor *color.Color) {
+	hash := fnv.New32()
+	_, _ = hash.Write([]byte(podName))
+	colors := podColors[hash.Sum32()%uint32(len(podColors))]
+	return colors[0], colors[1]
 }
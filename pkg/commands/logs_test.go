@@ -0,0 +1,122 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam"
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/wercker/stern/stern"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/appfile"
+	"github.com/oam-dev/kubevela/pkg/application"
+	cmdutil "github.com/oam-dev/kubevela/pkg/commands/util"
+)
+
+func TestLogsSelectServices(t *testing.T) {
+	app := &application.Application{
+		AppFile: &appfile.AppFile{
+			Name: "fakeApp",
+			Services: map[string]appfile.Service{
+				"frontend": map[string]interface{}{},
+				"backend":  map[string]interface{}{},
+			},
+		},
+	}
+	l := &Args{App: app, All: true}
+	services, err := l.selectServices()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"backend", "frontend"}, services)
+
+	l = &Args{App: app, Services: []string{"frontend", "backend", "frontend"}}
+	services, err = l.selectServices()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"frontend", "backend"}, services)
+
+	l = &Args{App: app, Services: []string{"db"}}
+	_, err = l.selectServices()
+	assert.EqualError(t, err, "service db not found in app")
+}
+
+func TestMatchLogLine(t *testing.T) {
+	include := []*regexp.Regexp{regexp.MustCompile("error"), regexp.MustCompile("warn")}
+	exclude := []*regexp.Regexp{regexp.MustCompile("healthz")}
+	assert.True(t, matchLogLine("an error occurred", include, exclude))
+	assert.True(t, matchLogLine("warning", include, exclude))
+	assert.False(t, matchLogLine("request served", include, exclude))
+	assert.False(t, matchLogLine("error in /healthz", include, exclude))
+	assert.True(t, matchLogLine("request served", nil, exclude))
+}
+
+func TestLogsPrevious(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = noColor }()
+	pod := func(name, svc string, terminated bool) corev1.Pod {
+		status := corev1.ContainerStatus{Name: "main"}
+		if terminated {
+			status.LastTerminationState.Terminated = &corev1.ContainerStateTerminated{ExitCode: 1}
+		}
+		return corev1.Pod{
+			ObjectMeta: v1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+				Labels:    map[string]string{oam.LabelAppComponent: svc},
+			},
+			Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{status}},
+		}
+	}
+	app := &application.Application{
+		AppFile: &appfile.AppFile{
+			Name: "fakeApp",
+			Services: map[string]appfile.Service{
+				"frontend": map[string]interface{}{},
+				"backend":  map[string]interface{}{},
+			},
+		},
+	}
+	clientSet := fake.NewSimpleClientset(&corev1.PodList{Items: []corev1.Pod{
+		pod("frontend-1", "frontend", true),
+		pod("backend-1", "backend", false),
+	}})
+	l := &Args{
+		Env:       &types.EnvMeta{Namespace: "default"},
+		App:       app,
+		ClientSet: clientSet,
+	}
+	targets, err := l.previousTargets(context.Background(), []string{"frontend", "backend"}, regexp.MustCompile(".*"))
+	assert.NoError(t, err)
+	assert.Equal(t, []previousTarget{{service: "frontend",
+		Target: stern.Target{Namespace: "default", Pod: "frontend-1", Container: "main"}}}, targets)
+
+	l.Services = []string{"backend"}
+	l.Output, l.Since, l.Container, l.Previous = "default", defaultLogsSince, ".*", true
+	assert.EqualError(t, l.Run(context.Background(), cmdutil.IOStreams{}), "no terminated containers found in services [backend]")
+
+	logs := "starting\nGET /healthz\nerror: connection refused\nretrying"
+	tmpl, err := l.logTemplate("frontend", color.FgHiCyan)
+	assert.NoError(t, err)
+	var b bytes.Buffer
+	assert.NoError(t, printLogLines(&b, strings.NewReader(logs), tmpl, targets[0].Target, nil,
+		[]*regexp.Regexp{regexp.MustCompile("healthz")}))
+	assert.Equal(t, "frontend frontend-1 main starting\nfrontend frontend-1 main error: connection refused\n"+
+		"frontend frontend-1 main retrying", b.String())
+
+	l.Output = "json"
+	tmpl, err = l.logTemplate("frontend", color.FgHiCyan)
+	assert.NoError(t, err)
+	b.Reset()
+	assert.NoError(t, printLogLines(&b, strings.NewReader(logs), tmpl, targets[0].Target,
+		[]*regexp.Regexp{regexp.MustCompile("error")}, nil))
+	assert.Contains(t, b.String(), `"service":"frontend"`)
+	assert.Contains(t, b.String(), `"message":"error: connection refused\n"`)
+	assert.NotContains(t, b.String(), "starting")
+}
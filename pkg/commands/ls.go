@@ -2,6 +2,7 @@ package commands
 
 import (
 	"context"
+	"fmt"
 	"strings"
 
 	"github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
@@ -33,7 +34,7 @@ func NewListCommand(c types.Args, ioStreams cmdutil.IOStreams) *cobra.Command {
 			if err != nil {
 				return err
 			}
-			newClient, err := client.New(c.Config, client.Options{Scheme: c.Schema})
+			newClient, err := newClientForEnv(c, env)
 			if err != nil {
 				return err
 			}
@@ -41,34 +42,52 @@ func NewListCommand(c types.Args, ioStreams cmdutil.IOStreams) *cobra.Command {
 			if err != nil {
 				return err
 			}
-			printComponentList(ctx, newClient, appName, env, ioStreams)
-			return nil
+			p, err := cmdutil.NewPrinter(cmd, ioStreams)
+			if err != nil {
+				return err
+			}
+			return printComponentList(ctx, newClient, appName, env, ioStreams, p)
 		},
 		Annotations: map[string]string{
 			types.TagCommandType: types.TypeApp,
 		},
 	}
 	cmd.PersistentFlags().StringP(App, "", "", "specify the name of application")
+	cmdutil.AddOutputFlag(cmd)
 	return cmd
 }
 
-func printComponentList(ctx context.Context, c client.Client, appName string, env *types.EnvMeta, ioStreams cmdutil.IOStreams) {
+func printComponentList(ctx context.Context, c client.Client, appName string, env *types.EnvMeta, ioStreams cmdutil.IOStreams, p *cmdutil.Printer) error {
 	deployedComponentList, err := oam.ListComponents(ctx, c, oam.Option{
 		AppName:   appName,
 		Namespace: env.Namespace,
 	})
 	if err != nil {
-		ioStreams.Infof("listing services: %s\n", err)
-		return
+		return fmt.Errorf("listing services: %w", err)
 	}
 	all := mergeStagingComponents(deployedComponentList, env, ioStreams)
+	if p.Structured() {
+		if all == nil {
+			all = []apis.ComponentMeta{}
+		}
+		return p.Print(all)
+	}
 	table := uitable.New()
-	table.AddRow("SERVICE", "APP", "TYPE", "TRAITS", "STATUS", "CREATED-TIME")
+	header := []interface{}{"SERVICE", "APP", "TYPE", "TRAITS", "STATUS", "CREATED-TIME"}
+	if p.Wide() {
+		header = append(header, "NAMESPACE")
+	}
+	table.AddRow(header...)
 	for _, a := range all {
 		traitAlias := strings.Join(a.TraitNames, ",")
-		table.AddRow(a.Name, a.App, a.WorkloadName, traitAlias, a.Status, a.CreatedTime)
+		row := []interface{}{a.Name, a.App, a.WorkloadName, traitAlias, a.Status, a.CreatedTime}
+		if p.Wide() {
+			row = append(row, env.Namespace)
+		}
+		table.AddRow(row...)
 	}
 	ioStreams.Info(table.String())
+	return nil
 }
 
 func mergeStagingComponents(deployed []apis.ComponentMeta, env *types.EnvMeta, ioStreams cmdutil.IOStreams) []apis.ComponentMeta {
@@ -16,7 +16,6 @@ import (
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	types2 "k8s.io/apimachinery/pkg/types"
-	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/portforward"
 	"k8s.io/client-go/transport/spdy"
@@ -47,6 +46,8 @@ type VelaPortForwardOptions struct {
 	ClientSet            kubernetes.Interface
 	Client               client.Client
 	routeTrait           bool
+	all                  bool
+	envFile              string
 }
 
 // NewPortForwardCommand is vela port-forward command
@@ -62,19 +63,29 @@ func NewPortForwardCommand(c types.Args, ioStreams velacmdutil.IOStreams) *cobra
 	cmd := &cobra.Command{
 		Use:   "port-forward APP_NAME [options] [LOCAL_PORT:]REMOTE_PORT [...[LOCAL_PORT_N:]REMOTE_PORT_N]",
 		Short: "Forward local ports to services in an application",
-		Long:  "Forward local ports to services in an application",
+		Long: "Forward local ports to services in an application. With --all, every service with a port or a " +
+			"route is forwarded onto non-conflicting local ports, and forwarding is re-established when pods are replaced.",
+		Example: `vela port-forward testapp
+vela port-forward testapp 8081:8080
+vela port-forward testapp --all --env-file .env`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) < 1 {
 				ioStreams.Error("Please specify application name.")
 				return nil
 			}
+			if err := o.Init(context.Background(), cmd, args); err != nil {
+				return err
+			}
 			newClient, err := client.New(o.VelaC.Config, client.Options{Scheme: o.VelaC.Schema})
 			if err != nil {
 				return err
 			}
 			o.Client = newClient
-			if err := o.Init(context.Background(), cmd, args); err != nil {
-				return err
+			if o.all {
+				if len(o.Args) > 1 {
+					return fmt.Errorf("ports can't be specified with --all")
+				}
+				return o.RunAll()
 			}
 			if err := o.Complete(); err != nil {
 				return err
@@ -93,6 +104,8 @@ func NewPortForwardCommand(c types.Args, ioStreams velacmdutil.IOStreams) *cobra
 		"The length of time (like 5s, 2m, or 3h, higher than zero) to wait until at least one pod is running",
 	)
 	cmd.Flags().BoolVar(&o.routeTrait, "route", false, "forward ports from route trait service")
+	cmd.Flags().BoolVar(&o.all, "all", false, "forward ports of all services with a port or a route in the application")
+	cmd.Flags().StringVar(&o.envFile, "env-file", "", "write local URLs of services forwarded by --all to the file in .env format")
 	return cmd
 }
 
@@ -114,9 +127,10 @@ func (o *VelaPortForwardOptions) Init(ctx context.Context, cmd *cobra.Command, a
 	}
 	o.App = app
 
-	cf := genericclioptions.NewConfigFlags(true)
-	cf.Namespace = &o.Env.Namespace
-	o.f = k8scmdutil.NewFactory(k8scmdutil.NewMatchVersionFlags(cf))
+	if o.VelaC, err = argsForEnv(o.VelaC, o.Env); err != nil {
+		return err
+	}
+	o.f = newFactoryForEnv(o.VelaC, o.Env)
 
 	if o.ClientSet == nil {
 		c, err := kubernetes.NewForConfig(o.VelaC.Config)
@@ -184,33 +198,19 @@ func (o *VelaPortForwardOptions) Complete() error {
 		return err
 	}
 	if len(o.Args) < 2 {
-		var found bool
-		_, configs := o.App.GetServiceConfig(svcName)
-		for k, v := range configs {
-			if k == "port" {
-				var val string
-				switch pv := v.(type) {
-				case int:
-					val = strconv.Itoa(pv)
-				case string:
-					val = pv
-				case float64:
-					val = strconv.Itoa(int(pv))
-				default:
-					return fmt.Errorf("invalid type '%s' of port %v", reflect.TypeOf(v), k)
-				}
-				if val == "80" {
-					val = "8080:80"
-				} else if val == "443" {
-					val = "8443:443"
-				}
-				o.Args = append(o.Args, val)
-				found = true
-			}
+		val, err := o.getServicePort(svcName)
+		if err != nil {
+			return err
 		}
-		if !found {
+		if val == "" {
 			return fmt.Errorf("no port found in app or arguments")
 		}
+		if val == "80" {
+			val = "8080:80"
+		} else if val == "443" {
+			val = "8443:443"
+		}
+		o.Args = append(o.Args, val)
 	}
 	args := make([]string, len(o.Args))
 	copy(args, o.Args)
@@ -218,6 +218,27 @@ func (o *VelaPortForwardOptions) Complete() error {
 	return o.kcPortForwardOptions.Complete(o.f, o.Cmd, args)
 }
 
+// getServicePort returns the port configured for the service in the app, it's empty if the port isn't configured
+func (o *VelaPortForwardOptions) getServicePort(svcName string) (string, error) {
+	_, configs := o.App.GetServiceConfig(svcName)
+	v, ok := configs["port"]
+	if !ok {
+		return "", nil
+	}
+	switch pv := v.(type) {
+	case int:
+		return strconv.Itoa(pv), nil
+	case int64:
+		return strconv.Itoa(int(pv)), nil
+	case string:
+		return pv, nil
+	case float64:
+		return strconv.Itoa(int(pv)), nil
+	default:
+		return "", fmt.Errorf("invalid type '%s' of port %v", reflect.TypeOf(v), v)
+	}
+}
+
 func (o *VelaPortForwardOptions) getPodName(svcName string) (string, error) {
 	podList, err := o.ClientSet.CoreV1().Pods(o.Env.Namespace).List(o.Context, v1.ListOptions{
 		LabelSelector: labels.Set(map[string]string{
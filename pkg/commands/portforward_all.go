@@ -0,0 +1,361 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam"
+	"github.com/gosuri/uitable"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	types2 "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+
+	"github.com/oam-dev/kubevela/pkg/application"
+)
+
+const (
+	// podCheckInterval is how often the forwarded pod is checked, forwarding is re-established once it's gone
+	podCheckInterval = 2 * time.Second
+	// reconnectInterval is the interval between attempts to re-establish forwarding
+	reconnectInterval = time.Second
+)
+
+// forwardTarget is a service forwarded by `vela port-forward --all`
+type forwardTarget struct {
+	Service string
+	// Route is the Kubernetes Service created by the route trait, it's empty if the port of the service is forwarded
+	Route      string
+	Selector   labels.Selector
+	RemotePort int
+	LocalPort  int
+}
+
+// RunAll forwards ports of all services with a port or a route, it runs until interrupted
+func (o *VelaPortForwardOptions) RunAll() error {
+	targets, skipped, err := o.getForwardTargets()
+	if err != nil {
+		return err
+	}
+	addresses := probeAddresses(o.kcPortForwardOptions.Address)
+	isFree := func(port int) bool { return isLocalPortFree(addresses, port) }
+	if err := allocateLocalPorts(targets, isFree); err != nil {
+		return err
+	}
+	o.ioStreams.Info(formatForwardTargets(targets))
+	if len(skipped) != 0 {
+		o.ioStreams.Infof("Skipped services without port or route: %s\n", strings.Join(skipped, ", "))
+	}
+	if o.envFile != "" {
+		if err := writeForwardEnvFile(o.envFile, targets); err != nil {
+			return err
+		}
+		o.ioStreams.Infof("Local URLs are written to %s\n", o.envFile)
+	}
+
+	ctx, cancel := context.WithCancel(o.Context)
+	defer cancel()
+	sc := make(chan os.Signal, 1)
+	signal.Notify(sc, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sc)
+	go func() {
+		select {
+		case <-sc:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := range targets {
+		wg.Add(1)
+		go func(t forwardTarget) {
+			defer wg.Done()
+			o.keepForwarding(ctx, t)
+		}(targets[i])
+	}
+	wg.Wait()
+	return nil
+}
+
+// getForwardTargets returns services to forward, services without port or route are returned as skipped
+func (o *VelaPortForwardOptions) getForwardTargets() ([]forwardTarget, []string, error) {
+	var targets []forwardTarget
+	var skipped []string
+	var appconfig *v1alpha2.ApplicationConfiguration
+	for _, svcName := range o.App.GetComponents() {
+		val, err := o.getServicePort(svcName)
+		if err != nil {
+			return nil, nil, err
+		}
+		if val != "" {
+			_, remote := splitPort(val)
+			port, err := strconv.Atoi(remote)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid port %s of service %s", val, svcName)
+			}
+			targets = append(targets, forwardTarget{
+				Service:    svcName,
+				Selector:   labels.SelectorFromSet(map[string]string{oam.LabelAppComponent: svcName}),
+				RemotePort: port,
+			})
+			continue
+		}
+
+		if appconfig == nil {
+			if appconfig, err = application.GetAppConfig(o.Context, o.Client, o.App, o.Env); err != nil {
+				return nil, nil, err
+			}
+		}
+		routeSvc := getRouteServiceName(appconfig, svcName)
+		if routeSvc == "" {
+			skipped = append(skipped, svcName)
+			continue
+		}
+		var svc = corev1.Service{}
+		if err := o.Client.Get(o.Context, types2.NamespacedName{Name: routeSvc, Namespace: o.Env.Namespace}, &svc); err != nil {
+			return nil, nil, err
+		}
+		if len(svc.Spec.Ports) == 0 || len(svc.Spec.Selector) == 0 {
+			skipped = append(skipped, svcName)
+			continue
+		}
+		// pods are forwarded directly, so the target port of the Service is used if it's a number
+		port := int(svc.Spec.Ports[0].Port)
+		if target := svc.Spec.Ports[0].TargetPort.IntValue(); target > 0 {
+			port = target
+		}
+		targets = append(targets, forwardTarget{
+			Service:    svcName,
+			Route:      routeSvc,
+			Selector:   labels.SelectorFromSet(svc.Spec.Selector),
+			RemotePort: port,
+		})
+	}
+	if len(targets) == 0 {
+		return nil, nil, fmt.Errorf("no service with port or route found in app %s", o.App.Name)
+	}
+	return targets, skipped, nil
+}
+
+// allocateLocalPorts assigns each target a local port which is free and not used by other targets. The remote port
+// is preferred, except that 80 and 443 are mapped to 8080 and 8443 as they need privileges to listen on.
+func allocateLocalPorts(targets []forwardTarget, isFree func(port int) bool) error {
+	used := make(map[int]bool)
+	for i := range targets {
+		port := targets[i].RemotePort
+		switch port {
+		case 80:
+			port = 8080
+		case 443:
+			port = 8443
+		}
+		for used[port] || !isFree(port) {
+			port++
+			if port > 65535 {
+				return fmt.Errorf("no free local port found for service %s", targets[i].Service)
+			}
+		}
+		used[port] = true
+		targets[i].LocalPort = port
+	}
+	return nil
+}
+
+// probeAddresses returns the addresses to probe for free ports when listening on the --address values. Like kubectl,
+// localhost is listened on both 127.0.0.1 and ::1, ::1 is skipped if IPv6 isn't available on the machine.
+func probeAddresses(addresses []string) []string {
+	var probes []string
+	for _, addr := range addresses {
+		if addr != "localhost" {
+			probes = append(probes, addr)
+			continue
+		}
+		probes = append(probes, "127.0.0.1")
+		if l, err := net.Listen("tcp", "[::1]:0"); err == nil {
+			_ = l.Close()
+			probes = append(probes, "::1")
+		}
+	}
+	return probes
+}
+
+// isLocalPortFree returns whether the port can be listened on all the addresses
+func isLocalPortFree(addresses []string, port int) bool {
+	for _, addr := range addresses {
+		l, err := net.Listen("tcp", net.JoinHostPort(addr, strconv.Itoa(port)))
+		if err != nil {
+			return false
+		}
+		_ = l.Close()
+	}
+	return true
+}
+
+// localURL returns the local URL of the target, it's https if the remote port is 443
+func localURL(t forwardTarget) string {
+	scheme := "http"
+	if t.RemotePort == 443 {
+		scheme = "https"
+	}
+	return scheme + "://127.0.0.1:" + strconv.Itoa(t.LocalPort)
+}
+
+func formatForwardTargets(targets []forwardTarget) string {
+	table := uitable.New()
+	table.AddRow("SERVICE", "REMOTE PORT", "LOCAL URL", "VIA")
+	for _, t := range targets {
+		via := "pod"
+		if t.Route != "" {
+			via = "route " + t.Route
+		}
+		table.AddRow(t.Service, t.RemotePort, localURL(t), via)
+	}
+	return table.String()
+}
+
+var envKeyInvalidChars = regexp.MustCompile(`[^A-Z0-9_]`)
+
+// forwardEnvKey returns the variable name of the local URL of the service, e.g. MY_SERVICE_URL for my-service
+func forwardEnvKey(svcName string) string {
+	return envKeyInvalidChars.ReplaceAllString(strings.ToUpper(svcName), "_") + "_URL"
+}
+
+// writeForwardEnvFile writes local URLs of targets to the file in .env format for local tooling
+func writeForwardEnvFile(path string, targets []forwardTarget) error {
+	sorted := make([]forwardTarget, len(targets))
+	copy(sorted, targets)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Service < sorted[j].Service })
+	var b strings.Builder
+	for _, t := range sorted {
+		fmt.Fprintf(&b, "%s=%s\n", forwardEnvKey(t.Service), localURL(t))
+	}
+	return ioutil.WriteFile(path, []byte(b.String()), 0600)
+}
+
+// keepForwarding forwards the local port to a running pod of the target, forwarding is re-established to another
+// pod once the pod is gone until ctx is done
+func (o *VelaPortForwardOptions) keepForwarding(ctx context.Context, t forwardTarget) {
+	var reconnect bool
+	for {
+		podName, err := o.getRunningPod(ctx, t.Selector)
+		if err == nil {
+			var established bool
+			established, err = o.forwardToPod(ctx, podName, t, reconnect)
+			if err != nil && ctx.Err() == nil {
+				o.ioStreams.Errorf("Forwarding service %s err: %v\n", t.Service, err)
+			}
+			if established {
+				reconnect = false
+			}
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		if !reconnect {
+			o.ioStreams.Infof("Forwarding of service %s is interrupted, re-establishing ...\n", t.Service)
+		}
+		reconnect = true
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(reconnectInterval):
+		}
+	}
+}
+
+// getRunningPod returns a running pod selected by the selector, pods being deleted are skipped
+func (o *VelaPortForwardOptions) getRunningPod(ctx context.Context, selector labels.Selector) (string, error) {
+	podList, err := o.ClientSet.CoreV1().Pods(o.Env.Namespace).List(ctx, v1.ListOptions{
+		LabelSelector: selector.String(),
+	})
+	if err != nil {
+		return "", err
+	}
+	for _, p := range podList.Items {
+		if p.DeletionTimestamp == nil && p.Status.Phase == corev1.PodRunning {
+			return p.Name, nil
+		}
+	}
+	return "", fmt.Errorf("no running pod found by %s", selector)
+}
+
+// forwardToPod forwards the local port of the target to the pod, it returns once the pod is gone or ctx is done, and
+// whether forwarding has been established
+func (o *VelaPortForwardOptions) forwardToPod(ctx context.Context, podName string, t forwardTarget,
+	reconnect bool) (bool, error) {
+	transport, upgrader, err := spdy.RoundTripperFor(o.VelaC.Config)
+	if err != nil {
+		return false, err
+	}
+	req := o.ClientSet.CoreV1().RESTClient().Post().Resource("pods").Namespace(o.Env.Namespace).
+		Name(podName).SubResource("portforward")
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, req.URL())
+
+	stopCh := make(chan struct{})
+	readyCh := make(chan struct{})
+	done := make(chan struct{})
+	defer close(done)
+	ready := readyCh
+	go func() {
+		ticker := time.NewTicker(podCheckInterval)
+		defer ticker.Stop()
+		defer close(stopCh)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-done:
+				return
+			case <-ready:
+				if reconnect {
+					o.ioStreams.Infof("Re-established forwarding of service %s to pod %s\n", t.Service, podName)
+				}
+				ready = nil
+			case <-ticker.C:
+				if !o.isPodRunning(ctx, podName) {
+					return
+				}
+			}
+		}
+	}()
+
+	ports := []string{fmt.Sprintf("%d:%d", t.LocalPort, t.RemotePort)}
+	fw, err := portforward.NewOnAddresses(dialer, o.kcPortForwardOptions.Address, ports, stopCh, readyCh,
+		ioutil.Discard, o.ioStreams.ErrOut)
+	if err != nil {
+		return false, err
+	}
+	err = fw.ForwardPorts()
+	select {
+	case <-readyCh:
+		return true, err
+	default:
+		return false, err
+	}
+}
+
+func (o *VelaPortForwardOptions) isPodRunning(ctx context.Context, podName string) bool {
+	pod, err := o.ClientSet.CoreV1().Pods(o.Env.Namespace).Get(ctx, podName, v1.GetOptions{})
+	if err != nil {
+		// the pod is regarded as running if it fails to get it for other reasons, e.g. network errors
+		return !apierrors.IsNotFound(err)
+	}
+	return pod.DeletionTimestamp == nil && pod.Status.Phase == corev1.PodRunning
+}
@@ -2,19 +2,30 @@ package commands
 
 import (
 	"context"
+	"io/ioutil"
+	"net"
 	"os"
+	"path/filepath"
 	"testing"
 
+	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	"github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
 	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam"
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	k8sfake "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/kubectl/pkg/cmd/portforward"
 	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/appfile"
+	"github.com/oam-dev/kubevela/pkg/application"
 	cmdutil "github.com/oam-dev/kubevela/pkg/commands/util"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
 )
 
 func TestPortForwardCommand(t *testing.T) {
@@ -50,3 +61,125 @@ func TestPortForwardCommand(t *testing.T) {
 	err := o.Init(context.Background(), cmd, []string{"fakeApp", "8081:8080"})
 	assert.NoError(t, err)
 }
+
+func TestGetForwardTargets(t *testing.T) {
+	appconfig := &v1alpha2.ApplicationConfiguration{
+		ObjectMeta: v1.ObjectMeta{Name: "myapp", Namespace: "default"},
+		Status: v1alpha2.ApplicationConfigurationStatus{Workloads: []v1alpha2.WorkloadStatus{{
+			ComponentName: "api",
+			Traits: []v1alpha2.WorkloadTrait{{Reference: runtimev1alpha1.TypedReference{
+				APIVersion: "standard.oam.dev/v1alpha1", Kind: "Route", Name: "api-route"}}},
+		}}},
+	}
+	routeSvc := &corev1.Service{
+		ObjectMeta: v1.ObjectMeta{Name: "api-route", Namespace: "default"},
+		Spec: corev1.ServiceSpec{
+			Selector: map[string]string{oam.LabelAppComponent: "api"},
+			Ports:    []corev1.ServicePort{{Port: 80, TargetPort: intstr.FromInt(3000)}},
+		},
+	}
+	o := &VelaPortForwardOptions{
+		Context: context.Background(),
+		Env:     &types.EnvMeta{Namespace: "default"},
+		Client:  fake.NewFakeClientWithScheme(common.Scheme, appconfig, routeSvc),
+		App: &application.Application{AppFile: &appfile.AppFile{
+			Name: "myapp",
+			Services: map[string]appfile.Service{
+				"web":    map[string]interface{}{"port": 80},
+				"api":    map[string]interface{}{},
+				"worker": map[string]interface{}{},
+			},
+		}},
+	}
+	targets, skipped, err := o.getForwardTargets()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"worker"}, skipped)
+	assert.Equal(t, []forwardTarget{
+		{Service: "api", Route: "api-route", Selector: labels.SelectorFromSet(map[string]string{oam.LabelAppComponent: "api"}), RemotePort: 3000},
+		{Service: "web", Selector: labels.SelectorFromSet(map[string]string{oam.LabelAppComponent: "web"}), RemotePort: 80},
+	}, targets)
+
+	o.App.Services = map[string]appfile.Service{"worker": map[string]interface{}{}}
+	_, _, err = o.getForwardTargets()
+	assert.EqualError(t, err, "no service with port or route found in app myapp")
+}
+
+func TestAllocateLocalPorts(t *testing.T) {
+	targets := []forwardTarget{
+		{Service: "a", RemotePort: 80},
+		{Service: "b", RemotePort: 8080},
+		{Service: "c", RemotePort: 443},
+		{Service: "d", RemotePort: 3000},
+	}
+	// 3000 is taken by another process
+	assert.NoError(t, allocateLocalPorts(targets, func(port int) bool { return port != 3000 }))
+	var ports []int
+	for _, target := range targets {
+		ports = append(ports, target.LocalPort)
+	}
+	assert.Equal(t, []int{8080, 8081, 8443, 3001}, ports)
+
+	targets = []forwardTarget{{Service: "a", RemotePort: 65535}}
+	assert.EqualError(t, allocateLocalPorts(targets, func(port int) bool { return false }),
+		"no free local port found for service a")
+}
+
+func TestWriteForwardEnvFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "port-forward")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, ".env")
+	assert.NoError(t, writeForwardEnvFile(path, []forwardTarget{
+		{Service: "web", RemotePort: 80, LocalPort: 8080},
+		{Service: "api-server", RemotePort: 3000, LocalPort: 3000},
+		{Service: "gateway", RemotePort: 443, LocalPort: 8443},
+	}))
+	data, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "API_SERVER_URL=http://127.0.0.1:3000\nGATEWAY_URL=https://127.0.0.1:8443\n"+
+		"WEB_URL=http://127.0.0.1:8080\n", string(data))
+}
+
+func TestIsLocalPortFree(t *testing.T) {
+	addresses := probeAddresses([]string{"localhost"})
+	assert.Equal(t, "127.0.0.1", addresses[0])
+	assert.Equal(t, []string{"0.0.0.0"}, probeAddresses([]string{"0.0.0.0"}))
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer l.Close()
+	port := l.Addr().(*net.TCPAddr).Port
+	assert.False(t, isLocalPortFree(addresses, port))
+}
+
+func TestGetRunningPod(t *testing.T) {
+	now := v1.Now()
+	pod := func(name string, phase corev1.PodPhase, deleting bool) corev1.Pod {
+		p := corev1.Pod{
+			ObjectMeta: v1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{oam.LabelAppComponent: "web"}},
+			Status:     corev1.PodStatus{Phase: phase},
+		}
+		if deleting {
+			p.DeletionTimestamp = &now
+		}
+		return p
+	}
+	o := &VelaPortForwardOptions{
+		Env: &types.EnvMeta{Namespace: "default"},
+		ClientSet: k8sfake.NewSimpleClientset(&corev1.PodList{Items: []corev1.Pod{
+			pod("web-1", corev1.PodRunning, true),
+			pod("web-2", corev1.PodPending, false),
+			pod("web-3", corev1.PodRunning, false),
+		}}),
+	}
+	selector := labels.SelectorFromSet(map[string]string{oam.LabelAppComponent: "web"})
+	name, err := o.getRunningPod(context.Background(), selector)
+	assert.NoError(t, err)
+	assert.Equal(t, "web-3", name)
+	assert.False(t, o.isPodRunning(context.Background(), "web-1"))
+	assert.False(t, o.isPodRunning(context.Background(), "web-4"))
+	assert.True(t, o.isPodRunning(context.Background(), "web-3"))
+
+	_, err = o.getRunningPod(context.Background(), labels.SelectorFromSet(map[string]string{oam.LabelAppComponent: "api"}))
+	assert.Error(t, err)
+}
@@ -54,6 +54,18 @@ func RefreshDefinitions(ctx context.Context, c types.Args, ioStreams cmdutil.IOS
 	}
 	syncedTemplates = append(syncedTemplates, templates...)
 	plugins.SinkTemp2Local(templates, dir)
+
+	templates, templateErrors, err = plugins.GetScopesFromCluster(ctx, types.DefaultKubeVelaNS, c, nil)
+	if err != nil {
+		return err
+	}
+	if len(templateErrors) > 0 {
+		for _, e := range templateErrors {
+			ioStreams.Infof("WARN: %v, you will unable to use this scope capability", e)
+		}
+	}
+	syncedTemplates = append(syncedTemplates, templates...)
+	plugins.SinkTemp2Local(templates, dir)
 	plugins.RemoveLegacyTemps(syncedTemplates, dir)
 
 	printRefreshReport(syncedTemplates, oldCaps, ioStreams, silentOutput)
@@ -79,6 +91,11 @@ func printRefreshReport(newCaps, oldCaps []types.Capability, io cmdutil.IOStream
 				table.AddRow(cap.Name, cap.Type, cap.Description)
 			}
 		}
+		for _, cap := range report[unchanged] {
+			if cap.Type == types.TypeScope {
+				table.AddRow(cap.Name, cap.Type, cap.Description)
+			}
+		}
 		if !silent {
 			io.Infof("Automatically discover capabilities successfully %s(no changes)\n\n", emojiSucceed)
 			io.Info(table.String())
@@ -3,6 +3,7 @@ package commands
 import (
 	"fmt"
 	"os"
+	"sort"
 
 	"github.com/gosuri/uitable"
 	"github.com/spf13/cobra"
@@ -11,6 +12,7 @@ import (
 	"github.com/oam-dev/kubevela/pkg/application"
 	cmdutil "github.com/oam-dev/kubevela/pkg/commands/util"
 	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/server/apis"
 )
 
 // NewAppShowCommand will show current application config
@@ -33,26 +35,38 @@ func NewAppShowCommand(ioStreams cmdutil.IOStreams) *cobra.Command {
 				return err
 			}
 
-			return showApplication(cmd, env, appName)
+			p, err := cmdutil.NewPrinter(cmd, ioStreams)
+			if err != nil {
+				return err
+			}
+			return showApplication(cmd, env, appName, p)
 		},
 		Annotations: map[string]string{
 			types.TagCommandType: types.TypeApp,
 		},
 	}
 	cmd.Flags().StringP("svc", "s", "", "service name")
+	cmdutil.AddOutputFlag(cmd)
 	cmd.SetOut(ioStreams.Out)
 	return cmd
 }
 
-func showApplication(cmd *cobra.Command, env *types.EnvMeta, appName string) error {
+func showApplication(cmd *cobra.Command, env *types.EnvMeta, appName string, p *cmdutil.Printer) error {
 	app, err := application.Load(env.Name, appName)
 	if err != nil {
 		return err
 	}
-	targetServices, err := oam.GetServicesWhenDescribingApplication(cmd, app)
+	targetServices, err := getTargetServices(cmd, app, !p.Structured())
 	if err != nil {
 		return err
 	}
+	if p.Structured() {
+		detail, err := describeApplication(app, env, targetServices)
+		if err != nil {
+			return err
+		}
+		return p.Print(detail)
+	}
 
 	cmd.Printf("About:\n\n")
 	table := uitable.New()
@@ -79,6 +93,50 @@ func showApplication(cmd *cobra.Command, env *types.EnvMeta, appName string) err
 	return nil
 }
 
+// getTargetServices returns services specified by `--svc`. If prompt is false, e.g. for structured output, it never
+// prompts to choose a service, all services are returned if `--svc` isn't set.
+func getTargetServices(cmd *cobra.Command, app *application.Application, prompt bool) ([]string, error) {
+	if prompt {
+		return oam.GetServicesWhenDescribingApplication(cmd, app)
+	}
+	if svcName := cmd.Flag("svc").Value.String(); svcName != "" {
+		if _, ok := app.Services[svcName]; !ok {
+			return nil, fmt.Errorf(ErrServiceNotFound, svcName)
+		}
+		return []string{svcName}, nil
+	}
+	services := make([]string, 0, len(app.Services))
+	for svcName := range app.Services {
+		services = append(services, svcName)
+	}
+	sort.Strings(services)
+	return services, nil
+}
+
+// describeApplication converts the Appfile of the application to the shape printed by `vela show -o`
+func describeApplication(app *application.Application, env *types.EnvMeta, services []string) (apis.ApplicationDetail, error) {
+	detail := apis.ApplicationDetail{
+		Name:        app.Name,
+		Namespace:   env.Namespace,
+		CreatedTime: app.CreateTime.String(),
+		UpdatedTime: app.UpdateTime.String(),
+	}
+	for _, svcName := range services {
+		wtype, data := app.GetWorkload(svcName)
+		traits, err := app.GetTraits(svcName)
+		if err != nil {
+			return detail, err
+		}
+		detail.Services = append(detail.Services, apis.ServiceDetail{
+			Name:         svcName,
+			WorkloadType: wtype,
+			Arguments:    data,
+			Traits:       traits,
+		})
+	}
+	return detail, nil
+}
+
 func showComponent(cmd *cobra.Command, env *types.EnvMeta, compName, appName string) error {
 	var app *application.Application
 	var err error
@@ -21,9 +21,11 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/appfile"
 	"github.com/oam-dev/kubevela/pkg/application"
 	cmdutil "github.com/oam-dev/kubevela/pkg/commands/util"
 	oam2 "github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/server/apis"
 )
 
 // HealthStatus represents health status strings.
@@ -96,10 +98,13 @@ const (
 func NewAppStatusCommand(c types.Args, ioStreams cmdutil.IOStreams) *cobra.Command {
 	ctx := context.Background()
 	cmd := &cobra.Command{
-		Use:     "status APP_NAME",
-		Short:   "Show status of an application",
-		Long:    "Show status of an application, including workloads and traits of each service.",
-		Example: `vela status APP_NAME`,
+		Use:   "status APP_NAME",
+		Short: "Show status of an application",
+		Long: "Show status of an application, including workloads and traits of each service. With --watch, status of " +
+			"all services is checked concurrently and redrawn as it changes, until all services are healthy if " +
+			"--timeout is set, or until interrupted.",
+		Example: `vela status APP_NAME
+vela status APP_NAME --watch --timeout 5m`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			argsLength := len(args)
 			if argsLength == 0 {
@@ -112,32 +117,67 @@ func NewAppStatusCommand(c types.Args, ioStreams cmdutil.IOStreams) *cobra.Comma
 				ioStreams.Errorf("Error: failed to get Env: %s", err)
 				return err
 			}
-			newClient, err := client.New(c.Config, client.Options{Scheme: c.Schema})
+			p, err := cmdutil.NewPrinter(cmd, ioStreams)
 			if err != nil {
 				return err
 			}
-			return printAppStatus(ctx, newClient, ioStreams, appName, env, cmd)
+			watch, err := cmd.Flags().GetBool("watch")
+			if err != nil {
+				return err
+			}
+			if watch {
+				if p.Structured() {
+					return errors.New("--watch can't be used with structured output")
+				}
+				timeout, err := cmd.Flags().GetDuration("timeout")
+				if err != nil {
+					return err
+				}
+				allowUndiagnosed, err := cmd.Flags().GetBool("allow-undiagnosed")
+				if err != nil {
+					return err
+				}
+				return watchAppStatus(ctx, c, env, appName, cmd, timeout, allowUndiagnosed, ioStreams)
+			}
+			newClient, err := newClientForEnv(c, env)
+			if err != nil {
+				return err
+			}
+			return printAppStatus(ctx, newClient, ioStreams, appName, env, cmd, p)
 		},
 		Annotations: map[string]string{
 			types.TagCommandType: types.TypeApp,
 		},
 	}
 	cmd.Flags().StringP("svc", "s", "", "service name")
+	cmd.Flags().BoolP("watch", "w", false, "watch status of all services and redraw it as it changes")
+	cmd.Flags().Duration("timeout", 0, "with --watch, exit once all services are healthy, or exit non-zero if "+
+		"they are not healthy within the timeout")
+	cmd.Flags().Bool("allow-undiagnosed", false, "with --watch, regard services which can't be diagnosed, i.e. "+
+		"having neither health in their workload definition nor a HealthScope, as healthy")
+	cmdutil.AddOutputFlag(cmd)
 	cmd.SetOut(ioStreams.Out)
 	return cmd
 }
 
-func printAppStatus(ctx context.Context, c client.Client, ioStreams cmdutil.IOStreams, appName string, env *types.EnvMeta, cmd *cobra.Command) error {
+func printAppStatus(ctx context.Context, c client.Client, ioStreams cmdutil.IOStreams, appName string, env *types.EnvMeta, cmd *cobra.Command, p *cmdutil.Printer) error {
 	app, err := application.Load(env.Name, appName)
 	if err != nil {
 		return err
 	}
 	namespace := env.Name
 
-	targetServices, err := oam2.GetServicesWhenDescribingApplication(cmd, app)
+	targetServices, err := getTargetServices(cmd, app, !p.Structured())
 	if err != nil {
 		return err
 	}
+	if p.Structured() {
+		status, err := getAppStatus(ctx, c, app, env, targetServices)
+		if err != nil {
+			return err
+		}
+		return p.Print(status)
+	}
 
 	cmd.Printf("About:\n\n")
 	table := uitable.New()
@@ -158,6 +198,27 @@ func printAppStatus(ctx context.Context, c client.Client, ioStreams cmdutil.IOSt
 	return nil
 }
 
+// getAppStatus gets the status of the application with its target services, it's checked once rather than waiting for
+// services to be healthy
+func getAppStatus(ctx context.Context, c client.Client, app *application.Application, env *types.EnvMeta, services []string) (apis.ApplicationStatus, error) {
+	status, err := oam2.GetApplicationStatus(ctx, c, app, env.Namespace)
+	if err != nil {
+		return status, err
+	}
+	target := make(map[string]bool, len(services))
+	for _, svcName := range services {
+		target[svcName] = true
+	}
+	var components []apis.ComponentStatus
+	for _, comp := range status.Components {
+		if target[comp.Name] {
+			components = append(components, comp)
+		}
+	}
+	status.Components = components
+	return status, nil
+}
+
 func printComponentStatus(ctx context.Context, c client.Client, ioStreams cmdutil.IOStreams, compName, appName string, env *types.EnvMeta) error {
 	app, appConfig, err := getApp(ctx, c, compName, appName, env)
 	if err != nil {
@@ -172,6 +233,17 @@ func printComponentStatus(ctx context.Context, c client.Client, ioStreams cmduti
 	}
 	workloadType := svc.GetType()
 
+	blocking, err := getBlockingDependencies(ctx, c, appConfig, svc, compName)
+	if err != nil {
+		return err
+	}
+	if len(blocking) != 0 {
+		ioStreams.Infof(white.Sprintf("  - Name: %s\n", compName))
+		ioStreams.Infof("    Type: %s\n", workloadType)
+		ioStreams.Infof("    %s\n\n", yellow.Sprintf("Blocked: waiting for %s to be healthy", strings.Join(blocking, ", ")))
+		return nil
+	}
+
 	healthStatus, healthInfo, err := healthCheckLoop(ctx, c, compName, appName, env)
 	if err != nil {
 		ioStreams.Info(healthInfo)
@@ -183,6 +255,13 @@ func printComponentStatus(ctx context.Context, c client.Client, ioStreams cmduti
 	healthColor := getHealthStatusColor(healthStatus)
 	healthInfo = strings.ReplaceAll(healthInfo, "\n", "\n\t") // format healthInfo output
 	ioStreams.Infof("    %s %s\n", healthColor.Sprint(healthStatus), healthColor.Sprint(healthInfo))
+	if hosts, err := application.GetRouteHosts(appConfig); err == nil {
+		for _, h := range hosts {
+			if h.Component == compName {
+				ioStreams.Infof("    URL: %s\n", h.URL())
+			}
+		}
+	}
 
 	// workload Must found
 	ioStreams.Infof("    Traits:\n")
@@ -202,6 +281,20 @@ func printComponentStatus(ctx context.Context, c client.Client, ioStreams cmduti
 	return nil
 }
 
+// getBlockingDependencies returns dependencies which the service is waiting for, it's empty if the service is deployed
+func getBlockingDependencies(ctx context.Context, c client.Client, appConfig *v1alpha2.ApplicationConfiguration, svc appfile.Service, compName string) ([]string, error) {
+	for _, comp := range appConfig.Spec.Components {
+		if comp.ComponentName == compName {
+			return nil, nil
+		}
+	}
+	deps, err := svc.GetDependsOn()
+	if err != nil || len(deps) == 0 {
+		return nil, err
+	}
+	return application.BlockingDependencies(ctx, c, appConfig, deps)
+}
+
 func traitCheckLoop(ctx context.Context, c client.Client, reference runtimev1alpha1.TypedReference, compName string, appConfig *v1alpha2.ApplicationConfiguration, app *application.Application, timeout time.Duration) (string, string, error) {
 	tr, err := oam2.GetUnstructured(ctx, c, appConfig.Namespace, reference)
 	if err != nil {
@@ -213,7 +306,7 @@ func traitCheckLoop(ctx context.Context, c client.Client, reference runtimev1alp
 		return traitType, message, err
 	}
 
-	checker := oam2.GetChecker(traitType, c)
+	checker := oam2.GetChecker(ctx, traitType, reference, c)
 
 	// Health Check Loop For Trait
 	var message string
@@ -264,14 +357,6 @@ HealthCheckLoop:
 	return healthStatus, healthInfo, nil
 }
 
-func tryGetWorkloadStatus(ctx context.Context, c client.Client, ns string, wlRef runtimev1alpha1.TypedReference) (string, error) {
-	workload, err := oam2.GetUnstructured(ctx, c, ns, wlRef)
-	if err != nil {
-		return "", err
-	}
-	return oam2.GetStatusFromObject(workload)
-}
-
 func printTrackingDeployStatus(ctx context.Context, c client.Client, ioStreams cmdutil.IOStreams, compName, appName string, env *types.EnvMeta) (CompStatus, error) {
 	sDeploy := newTrackingSpinner("Checking Status ...")
 	sDeploy.Start()
@@ -342,15 +427,30 @@ func trackHealthCheckingStatus(ctx context.Context, c client.Client, compName, a
 		appConfigConditionMsg := appConfig.Status.GetCondition(runtimev1alpha1.TypeSynced).Message
 		return compStatusUnknown, HealthStatusUnknown, "", fmt.Errorf(ErrFmtNotInitialized, appConfigConditionMsg)
 	}
-	// check whether referenced a HealthScope
+	workload, err := oam2.GetUnstructured(ctx, c, env.Namespace, wlStatus.Reference)
+	if err != nil {
+		return compStatusUnknown, HealthStatusUnknown, "", err
+	}
+	statusInfo, err := oam2.GetStatusFromObject(workload)
+	if err != nil {
+		return compStatusUnknown, HealthStatusUnknown, "", err
+	}
+	// health is evaluated like dependencies and Applications do, by the status policy of the workload definition,
+	// or by the HealthScope if the definition has none
+	healthStatus, diagnosis, found, err := application.GetWorkloadHealth(ctx, c, workload)
+	if err != nil {
+		return compStatusUnknown, HealthStatusUnknown, "", err
+	}
+	if found && diagnosis == "" {
+		diagnosis = statusInfo
+	}
 	var healthScopeName string
 	for _, v := range wlStatus.Scopes {
 		if v.Reference.Kind == kindHealthScope {
 			healthScopeName = v.Reference.Name
 		}
 	}
-	var healthStatus HealthStatus
-	if healthScopeName != "" {
+	if !found && healthScopeName != "" {
 		var healthScope v1alpha2.HealthScope
 		if err = c.Get(ctx, client.ObjectKey{Namespace: env.Namespace, Name: healthScopeName}, &healthScope); err != nil {
 			return compStatusUnknown, HealthStatusUnknown, "", err
@@ -364,23 +464,18 @@ func trackHealthCheckingStatus(ctx context.Context, c client.Client, compName, a
 		if wlhc == nil {
 			return compStatusUnknown, HealthStatusUnknown, "", fmt.Errorf("cannot get health condition from the health scope: %s", healthScope.Name)
 		}
-		healthStatus = wlhc.HealthStatus
-		if healthStatus == HealthStatusHealthy {
-			return compStatusHealthCheckDone, healthStatus, wlhc.Diagnosis, nil
-		}
-		if healthStatus == HealthStatusUnhealthy {
-			cTime := appConfig.GetCreationTimestamp()
-			if time.Since(cTime.Time) <= healthCheckBufferTime {
-				return compStatusHealthChecking, HealthStatusUnknown, "", nil
-			}
-			return compStatusHealthCheckDone, healthStatus, wlhc.Diagnosis, nil
-		}
+		healthStatus, diagnosis = wlhc.HealthStatus, wlhc.Diagnosis
 	}
-	// No health scope specified or health status is unknown , try get status from workload
-	statusInfo, err := tryGetWorkloadStatus(ctx, c, env.Namespace, wlStatus.Reference)
-	if err != nil {
-		return compStatusUnknown, HealthStatusUnknown, "", err
+	switch healthStatus {
+	case HealthStatusHealthy:
+		return compStatusHealthCheckDone, healthStatus, diagnosis, nil
+	case HealthStatusUnhealthy:
+		if time.Since(appConfig.GetCreationTimestamp().Time) <= healthCheckBufferTime {
+			return compStatusHealthChecking, HealthStatusUnknown, "", nil
+		}
+		return compStatusHealthCheckDone, healthStatus, diagnosis, nil
 	}
+	// no health scope specified or health status is unknown, show status of the workload
 	return compStatusHealthCheckDone, HealthStatusNotDiagnosed, statusInfo, nil
 }
 
@@ -0,0 +1,281 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam/discoverymapper"
+	"github.com/gosuri/uitable"
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh/terminal"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/application"
+	cmdutil "github.com/oam-dev/kubevela/pkg/commands/util"
+	oam2 "github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/server/apis"
+)
+
+const (
+	// maxWatchEvents is the number of latest Kubernetes Events shown by `vela status --watch`
+	maxWatchEvents = 5
+	// redrawInterval batches status changes computed together into one redraw
+	redrawInterval = 200 * time.Millisecond
+	// clearScreen moves the cursor to the top left and clears the terminal
+	clearScreen = "\033[H\033[2J"
+)
+
+// watchAppStatus watches status of services of the application and redraws it as it changes. If timeout is set, it
+// returns once all services are healthy, otherwise it watches until interrupted. It returns an error if any service
+// isn't healthy when it stops watching. Services which can't be diagnosed are only regarded as healthy if
+// allowUndiagnosed is set.
+func watchAppStatus(ctx context.Context, c types.Args, env *types.EnvMeta, appName string, cmd *cobra.Command,
+	timeout time.Duration, allowUndiagnosed bool, ioStreams cmdutil.IOStreams) error {
+	app, err := application.Load(env.Name, appName)
+	if err != nil {
+		return err
+	}
+	services, err := getTargetServices(cmd, app, false)
+	if err != nil {
+		return err
+	}
+	envArgs, err := argsForEnv(c, env)
+	if err != nil {
+		return err
+	}
+	newClient, err := client.New(envArgs.Config, client.Options{Scheme: envArgs.Schema})
+	if err != nil {
+		return err
+	}
+	dm, err := discoverymapper.New(envArgs.Config)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var timeoutCh <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		timeoutCh = timer.C
+	}
+	sc := make(chan os.Signal, 1)
+	signal.Notify(sc, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sc)
+
+	events := make(chan apis.AppEvent)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- oam2.WatchApplication(ctx, envArgs.Config, newClient, dm, app, env.Namespace, events)
+	}()
+
+	view := newStatusView(appName, services, allowUndiagnosed)
+	draw := newScreen(ioStreams.Out)
+	ticker := time.NewTicker(redrawInterval)
+	defer ticker.Stop()
+	var dirty bool
+	for {
+		select {
+		case e := <-events:
+			view.apply(e)
+			view.updated = time.Now()
+			dirty = true
+		case <-ticker.C:
+			if !dirty {
+				continue
+			}
+			dirty = false
+			draw(view.render())
+			if timeout > 0 && len(view.notHealthy()) == 0 {
+				return nil
+			}
+		case err := <-errCh:
+			if err != nil {
+				return err
+			}
+			return view.result()
+		case <-timeoutCh:
+			draw(view.render())
+			return view.result()
+		case <-sc:
+			return view.result()
+		}
+	}
+}
+
+// newScreen returns a function drawing content, the terminal is cleared before drawing so that the content is
+// redrawn in place, otherwise e.g. in CI logs the content is appended
+func newScreen(out io.Writer) func(content string) {
+	f, ok := out.(*os.File)
+	isTerminal := ok && terminal.IsTerminal(int(f.Fd()))
+	return func(content string) {
+		if isTerminal {
+			_, _ = io.WriteString(out, clearScreen)
+		}
+		_, _ = io.WriteString(out, content+"\n")
+	}
+}
+
+// statusView keeps the latest status of an application from events of oam.WatchApplication
+type statusView struct {
+	app      string
+	services []string
+	status   apis.ApplicationStatus
+	// components are health of services without traits, their traits are kept in traits in the order reported
+	components map[string]apis.ComponentStatus
+	traits     map[string][]apis.TraitStatus
+	events     []apis.KubeEvent
+	err        string
+	updated    time.Time
+	// allowUndiagnosed regards services which can't be diagnosed as healthy
+	allowUndiagnosed bool
+}
+
+func newStatusView(app string, services []string, allowUndiagnosed bool) *statusView {
+	return &statusView{
+		app:              app,
+		services:         services,
+		components:       make(map[string]apis.ComponentStatus),
+		traits:           make(map[string][]apis.TraitStatus),
+		allowUndiagnosed: allowUndiagnosed,
+	}
+}
+
+func (v *statusView) apply(e apis.AppEvent) {
+	switch data := e.Data.(type) {
+	case apis.ApplicationStatus:
+		v.status = data
+		v.err = ""
+	case apis.ComponentStatus:
+		v.components[data.Name] = data
+		v.err = ""
+	case apis.TraitStatus:
+		traits := v.traits[data.Component]
+		for i, tr := range traits {
+			if tr.Name == data.Name {
+				traits[i] = data
+				return
+			}
+		}
+		v.traits[data.Component] = append(traits, data)
+	case apis.KubeEvent:
+		v.events = append(v.events, data)
+		if len(v.events) > maxWatchEvents {
+			v.events = v.events[len(v.events)-maxWatchEvents:]
+		}
+	case string:
+		if e.Type == apis.AppEventError {
+			v.err = data
+		}
+	}
+}
+
+// notHealthy returns services which are not deployed, not healthy or have traits not done yet. Services which can't
+// be diagnosed, e.g. right after they're deployed, are not healthy either unless allowUndiagnosed is set.
+func (v *statusView) notHealthy() []string {
+	var services []string
+	for _, svc := range v.services {
+		comp, ok := v.components[svc]
+		undiagnosed := ok && comp.HealthStatus == ""
+		if !ok || (undiagnosed && !v.allowUndiagnosed) ||
+			(!undiagnosed && comp.HealthStatus != string(v1alpha2.StatusHealthy)) {
+			services = append(services, svc)
+			continue
+		}
+		for _, tr := range v.traits[svc] {
+			if tr.Status != oam2.StatusDone {
+				services = append(services, svc)
+				break
+			}
+		}
+	}
+	return services
+}
+
+func (v *statusView) result() error {
+	if v.err != "" {
+		return errors.New(v.err)
+	}
+	if services := v.notHealthy(); len(services) != 0 {
+		return fmt.Errorf("services of app %s are not healthy: %s", v.app, strings.Join(services, ", "))
+	}
+	return nil
+}
+
+func (v *statusView) render() string {
+	var b strings.Builder
+	status := v.status.Status
+	if status == "" {
+		status = "Unknown"
+	}
+	fmt.Fprintf(&b, "App: %s\tStatus: %s", v.app, status)
+	if !v.updated.IsZero() {
+		fmt.Fprintf(&b, "\tUpdated at: %s", v.updated.Format("15:04:05"))
+	}
+	b.WriteString("\n")
+	if v.status.Message != "" {
+		fmt.Fprintf(&b, "Message: %s\n", v.status.Message)
+	}
+	if v.err != "" {
+		fmt.Fprintf(&b, "Error: %s\n", v.err)
+	}
+	b.WriteString("\n")
+
+	table := uitable.New()
+	table.MaxColWidth = 80
+	table.AddRow("SERVICE", "HEALTH", "REPLICAS", "MESSAGE")
+	for _, svc := range v.services {
+		comp, ok := v.components[svc]
+		if !ok {
+			table.AddRow(svc, "-", "-", "waiting to be deployed")
+			continue
+		}
+		health := comp.HealthStatus
+		if health == "" {
+			health = string(HealthStatusNotDiagnosed)
+		}
+		message := comp.Diagnosis
+		if message == "" {
+			message = comp.WorkloadStatus
+		}
+		table.AddRow(svc, health, valueOrDash(comp.Replicas), message)
+		for _, tr := range v.traits[svc] {
+			name := tr.Type
+			if name == "" {
+				name = tr.Name
+			}
+			table.AddRow("  "+name, tr.Status, "", tr.Message)
+		}
+	}
+	b.WriteString(table.String())
+	b.WriteString("\n")
+
+	if len(v.events) != 0 {
+		b.WriteString("\nEvents:\n")
+		table = uitable.New()
+		table.MaxColWidth = 80
+		for _, e := range v.events {
+			table.AddRow("  "+e.Type, e.Reason, e.Object, e.Message)
+		}
+		b.WriteString(table.String())
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func valueOrDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
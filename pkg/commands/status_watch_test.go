@@ -0,0 +1,64 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/server/apis"
+)
+
+func TestStatusView(t *testing.T) {
+	v := newStatusView("myapp", []string{"frontend", "backend"}, false)
+	v.apply(apis.AppEvent{Type: apis.AppEventError, Data: "app myapp is not deployed in namespace default"})
+	assert.EqualError(t, v.result(), "app myapp is not deployed in namespace default")
+
+	v.apply(apis.AppEvent{Type: apis.AppEventStatus, Data: apis.ApplicationStatus{Name: "myapp", Status: "True"}})
+	v.apply(apis.AppEvent{Type: apis.AppEventHealth, Data: apis.ComponentStatus{Name: "frontend", HealthStatus: "UNHEALTHY",
+		Diagnosis: "Ready: 1/2", Replicas: "1/2"}})
+	v.apply(apis.AppEvent{Type: apis.AppEventTrait, Data: apis.TraitStatus{Component: "frontend", Type: "route",
+		Name: "frontend-route", Status: oam.StatusChecking}})
+	assert.Equal(t, []string{"frontend", "backend"}, v.notHealthy())
+	assert.EqualError(t, v.result(), "services of app myapp are not healthy: frontend, backend")
+	out := v.render()
+	assert.Contains(t, out, "App: myapp\tStatus: True\n")
+	assert.Regexp(t, `frontend\s+UNHEALTHY\s+1/2\s+Ready: 1/2`, out)
+	assert.Regexp(t, `route\s+checking`, out)
+	assert.Regexp(t, `backend\s+-\s+-\s+waiting to be deployed`, out)
+
+	v.apply(apis.AppEvent{Type: apis.AppEventHealth, Data: apis.ComponentStatus{Name: "frontend", HealthStatus: "HEALTHY",
+		Diagnosis: "Ready: 2/2", Replicas: "2/2"}})
+	v.apply(apis.AppEvent{Type: apis.AppEventTrait, Data: apis.TraitStatus{Component: "frontend", Type: "route",
+		Name: "frontend-route", Status: oam.StatusDone, Message: "Visiting URL: https://frontend.example.com"}})
+	// backend has no status policy, it can't be diagnosed so it's not regarded as healthy unless allowed
+	v.apply(apis.AppEvent{Type: apis.AppEventHealth, Data: apis.ComponentStatus{Name: "backend"}})
+	assert.Equal(t, []string{"backend"}, v.notHealthy())
+	v.allowUndiagnosed = true
+	assert.Empty(t, v.notHealthy())
+	assert.NoError(t, v.result())
+	assert.Len(t, v.traits["frontend"], 1)
+	out = v.render()
+	assert.Regexp(t, `route\s+done\s+Visiting URL: https://frontend.example.com`, out)
+	assert.Regexp(t, `backend\s+NOT DIAGNOSED\s+-`, out)
+
+	for i := 0; i < maxWatchEvents+2; i++ {
+		v.apply(apis.AppEvent{Type: apis.AppEventKube, Data: apis.KubeEvent{Type: "Normal", Reason: "ScalingReplicaSet",
+			Object: "deployment/frontend", Message: fmt.Sprintf("event %d", i)}})
+	}
+	assert.Len(t, v.events, maxWatchEvents)
+	out = v.render()
+	assert.NotContains(t, out, "event 1\n")
+	assert.Contains(t, out, fmt.Sprintf("event %d", maxWatchEvents+1))
+}
+
+func TestNewScreen(t *testing.T) {
+	var b bytes.Buffer
+	draw := newScreen(&b)
+	draw("first")
+	draw("second")
+	// the terminal isn't cleared if the output isn't a terminal
+	assert.Equal(t, "first\nsecond\n", b.String())
+}
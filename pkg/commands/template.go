@@ -30,7 +30,7 @@ func NewTemplateContextCommand(ioStream cmdutil.IOStreams) *cobra.Command {
 		Use:                   "context",
 		DisableFlagsInUseLine: true,
 		Short:                 "Show context parameters",
-		Long:                  "Show context parameter",
+		Long:                  "Show fields of context which are filled by KubeVela when rendering CUE templates of workloads and traits",
 		Example:               `vela template context`,
 		Annotations: map[string]string{
 			types.TagCommandType: types.TypeSystem,
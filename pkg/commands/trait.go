@@ -51,13 +51,12 @@ func AddTraitCommands(parentCmd *cobra.Command, c types.Args, ioStreams cmdutil.
 			RunE: func(cmd *cobra.Command, args []string) error {
 				o := &commandOptions{IOStreams: ioStreams, traitType: name}
 				o.Template = tmp
-				newClient, err := client.New(c.Config, client.Options{Scheme: c.Schema})
+				var err error
+				o.Env, err = GetEnv(cmd)
 				if err != nil {
 					return err
 				}
-				o.Client = newClient
-				o.Env, err = GetEnv(cmd)
-				if err != nil {
+				if o.Client, err = newClientForEnv(c, o.Env); err != nil {
 					return err
 				}
 				detach, _ := cmd.Flags().GetBool(TraitDetach)
@@ -24,12 +24,16 @@ func NewTraitsCommand(c types.Args, ioStreams cmdutil.IOStreams) *cobra.Command
 		Long:                  "List traits",
 		Example:               `vela traits`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			p, err := cmdutil.NewPrinter(cmd, ioStreams)
+			if err != nil {
+				return err
+			}
 			if syncCluster {
-				if err := RefreshDefinitions(ctx, c, ioStreams, true); err != nil {
+				if err := RefreshDefinitions(ctx, c, p.InfoStreams(ioStreams), true); err != nil {
 					return err
 				}
 			}
-			return printTraitList(&workloadName, ioStreams)
+			return printTraitList(&workloadName, ioStreams, p)
 		},
 		Annotations: map[string]string{
 			types.TagCommandType: types.TypeCap,
@@ -39,18 +43,34 @@ func NewTraitsCommand(c types.Args, ioStreams cmdutil.IOStreams) *cobra.Command
 	cmd.SetOut(ioStreams.Out)
 	cmd.Flags().StringVar(&workloadName, "apply-to", "", "Workload name")
 	cmd.Flags().BoolVarP(&syncCluster, "sync", "s", true, "Synchronize capabilities from cluster into local")
+	cmdutil.AddOutputFlag(cmd)
 	return cmd
 }
 
-func printTraitList(workloadName *string, ioStreams cmdutil.IOStreams) error {
+func printTraitList(workloadName *string, ioStreams cmdutil.IOStreams, p *cmdutil.Printer) error {
 	table := uitable.New()
 	table.Wrap = true
 	traitDefinitionList, err := oam.ListTraitDefinitions(workloadName)
 	if err != nil {
 		return err
 	}
-	table.AddRow("NAME", "DESCRIPTION", "APPLIES TO")
+	if p.Structured() {
+		if traitDefinitionList == nil {
+			traitDefinitionList = []types.Capability{}
+		}
+		return p.Print(traitDefinitionList)
+	}
+	if p.Wide() {
+		table.AddRow("NAME", "DESCRIPTION", "APPLIES TO", "CONFLICTS WITH", "PARAMETERS")
+	} else {
+		table.AddRow("NAME", "DESCRIPTION", "APPLIES TO")
+	}
 	for _, t := range traitDefinitionList {
+		if p.Wide() {
+			table.AddRow(t.Name, t.Description, strings.Join(t.AppliesTo, "\n"), strings.Join(t.ConflictsWith, "\n"),
+				parameterNames(t.Parameters))
+			continue
+		}
 		table.AddRow(t.Name, t.Description, strings.Join(t.AppliesTo, "\n"))
 	}
 	ioStreams.Info(table.String())
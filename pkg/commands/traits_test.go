@@ -87,6 +87,8 @@ func Test_printTraitList(t *testing.T) {
 		b := bytes.Buffer{}
 		iostream := cmdutil.IOStreams{Out: &b}
 		nn := c.workloadName
-		assert.NoError(t, printTraitList(&nn, iostream))
+		p, err := cmdutil.NewPrinter(nil, iostream)
+		assert.NoError(t, err)
+		assert.NoError(t, printTraitList(&nn, iostream, p))
 	}
 }
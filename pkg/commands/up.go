@@ -7,7 +7,9 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/oam-dev/kubevela/pkg/utils/common"
 
@@ -32,6 +34,9 @@ var (
 	appFilePath string
 )
 
+// dependencyTimeout is how long `vela up` waits for dependencies of services to be healthy
+const dependencyTimeout = 5 * time.Minute
+
 // NewUpCommand will create command for applying an AppFile
 func NewUpCommand(c types.Args, ioStream cmdutil.IOStreams) *cobra.Command {
 	cmd := &cobra.Command{
@@ -47,7 +52,7 @@ func NewUpCommand(c types.Args, ioStream cmdutil.IOStreams) *cobra.Command {
 			if err != nil {
 				return err
 			}
-			kubecli, err := client.New(c.Config, client.Options{Scheme: c.Schema})
+			kubecli, err := newClientForEnv(c, velaEnv)
 			if err != nil {
 				return err
 			}
@@ -114,10 +119,15 @@ func (o *AppfileOptions) Run(filePath string) error {
 		return err
 	}
 
+	app.SetEnv(o.Env)
 	comps, appConfig, scopes, err := app.BuildOAM(o.Env.Namespace, o.IO, tm, false)
 	if err != nil {
 		return err
 	}
+	levels, err := app.GetServiceLevels()
+	if err != nil {
+		return err
+	}
 
 	var w bytes.Buffer
 
@@ -144,7 +154,8 @@ func (o *AppfileOptions) Run(filePath string) error {
 		}
 		w.WriteByte('\n')
 	}
-	for _, scope := range scopes {
+	// values of Secrets are not written into the deploy config
+	for _, scope := range appfile.RedactSecrets(scopes) {
 		w.WriteString("---\n")
 		err = enc.Encode(scope, &w)
 		if err != nil {
@@ -167,7 +178,7 @@ func (o *AppfileOptions) Run(filePath string) error {
 	}
 
 	o.IO.Infof("\nApplying deploy configs ...\n")
-	return o.ApplyAppConfig(appConfig, comps, scopes)
+	return o.ApplyAppConfig(appConfig, comps, scopes, app, levels)
 }
 
 func (o *AppfileOptions) saveToAppDir(f *appfile.AppFile) error {
@@ -180,40 +191,100 @@ func (o *AppfileOptions) saveToAppDir(f *appfile.AppFile) error {
 // - for create, it displays app status along with information of url, metrics, ssh, logging.
 // - for update, it rolls out a canary deployment and prints its information. User can verify the canary deployment.
 //   This will wait for user approval. If approved, it continues upgrading the whole; otherwise, it would rollback.
-func (o *AppfileOptions) ApplyAppConfig(ac *v1alpha2.ApplicationConfiguration, comps []*v1alpha2.Component, scopes []oam.Object) error {
+// Services are deployed level by level of their dependencies, see AppFile.GetServiceLevels.
+func (o *AppfileOptions) ApplyAppConfig(ac *v1alpha2.ApplicationConfiguration, comps []*v1alpha2.Component, scopes []oam.Object,
+	app *appfile.AppFile, levels [][]string) error {
 	key := apitypes.NamespacedName{
 		Namespace: ac.Namespace,
 		Name:      ac.Name,
 	}
 	o.IO.Infof("Checking if app has been deployed...\n")
 	var tmpAC v1alpha2.ApplicationConfiguration
+	deployed := &tmpAC
 	err := o.Kubecli.Get(context.TODO(), key, &tmpAC)
 	switch {
 	case apierrors.IsNotFound(err):
 		o.IO.Infof("App has not been deployed, creating a new deployment...\n")
+		deployed = nil
 	case err == nil:
 		o.IO.Infof("App exists, updating existing deployment...\n")
 	default:
 		return err
 	}
-	if err := o.apply(ac, comps, scopes); err != nil {
+	if err := o.apply(ac, deployed, comps, scopes, app, levels); err != nil {
 		return err
 	}
+	rev, err := (&application.Application{AppFile: app}).RecordRevision(context.TODO(), o.Kubecli, ac, comps, scopes)
+	if err != nil {
+		return errors.Wrap(err, "record revision failed")
+	}
+	o.IO.Infof("Recorded revision %d of app %s\n", rev.Revision, ac.Name)
 	o.IO.Infof(o.Info(ac.Name, comps))
 	return nil
 }
 
-func (o *AppfileOptions) apply(ac *v1alpha2.ApplicationConfiguration, comps []*v1alpha2.Component, scopes []oam.Object) error {
+// apply deploys services level by level, services of a level are deployed after their dependencies are healthy.
+// Until then the AppConfig keeps the deployed version of them, so they're not changed before their dependencies.
+func (o *AppfileOptions) apply(ac, deployed *v1alpha2.ApplicationConfiguration, comps []*v1alpha2.Component, scopes []oam.Object,
+	app *appfile.AppFile, levels [][]string) error {
+	ctx := context.TODO()
+	if err := application.CheckRouteHostConflicts(ctx, o.Kubecli, ac); err != nil {
+		return err
+	}
+	if err := application.CreateScopes(ctx, o.Kubecli, scopes); err != nil {
+		return err
+	}
+	compsByName := make(map[string]*v1alpha2.Component, len(comps))
 	for _, comp := range comps {
-		if err := application.CreateOrUpdateComponent(context.TODO(), o.Kubecli, comp); err != nil {
+		compsByName[comp.Name] = comp
+	}
+	var applied []string
+	for _, level := range levels {
+		deps, err := levelDependencies(app, level)
+		if err != nil {
+			return err
+		}
+		if len(deps) != 0 {
+			o.IO.Infof("Waiting for %s to be healthy before deploying %s ...\n", strings.Join(deps, ", "), strings.Join(level, ", "))
+			waitCtx, cancel := context.WithTimeout(ctx, dependencyTimeout)
+			err = application.WaitForHealthy(waitCtx, o.Kubecli, ac, deps, trackingInterval)
+			cancel()
+			if err != nil {
+				return err
+			}
+		}
+		for _, name := range level {
+			if err := application.CreateOrUpdateComponent(ctx, o.Kubecli, compsByName[name]); err != nil {
+				return err
+			}
+		}
+		applied = append(applied, level...)
+		if err := application.CreateOrUpdateAppConfig(ctx, o.Kubecli, application.StageAppConfig(ac, deployed, applied)); err != nil {
 			return err
 		}
 	}
+	// secrets removed from the Appfile are deleted once no service refers to them
+	return application.DeleteStaleSecrets(ctx, o.Kubecli, ac, scopes)
+}
 
-	if err := application.CreateScopes(context.TODO(), o.Kubecli, scopes); err != nil {
-		return err
+// levelDependencies returns the sorted dependencies of services in a level
+func levelDependencies(app *appfile.AppFile, level []string) ([]string, error) {
+	seen := make(map[string]bool)
+	var deps []string
+	for _, name := range level {
+		ds, err := app.Services[name].GetDependsOn()
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range ds {
+			if !seen[d] {
+				seen[d] = true
+				deps = append(deps, d)
+			}
+		}
 	}
-	return application.CreateOrUpdateAppConfig(context.TODO(), o.Kubecli, ac)
+	sort.Strings(deps)
+	return deps, nil
 }
 
 // Info shows the status of each service in the Appfile
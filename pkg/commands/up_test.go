@@ -1,18 +1,27 @@
 package commands
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"os"
 	"testing"
 
+	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
 	"github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
 	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	apitypes "k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/appfile"
 	"github.com/oam-dev/kubevela/pkg/commands/util"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
 )
 
 func TestUp(t *testing.T) {
@@ -38,3 +47,72 @@ func TestUp(t *testing.T) {
 	assert.Contains(t, msg, "App has been deployed")
 	assert.Contains(t, msg, fmt.Sprintf("App status: vela status %s", appName))
 }
+
+// revisionClient acts like the OAM runtime, Components get their revisions and AppConfigs run them when they're written
+type revisionClient struct {
+	ctrlclient.Client
+}
+
+func (c revisionClient) track(obj runtime.Object) {
+	switch o := obj.(type) {
+	case *v1alpha2.Component:
+		o.Status.LatestRevision = &v1alpha2.Revision{Name: o.Name + "-v1", Revision: 1}
+	case *v1alpha2.ApplicationConfiguration:
+		o.Status.Workloads = nil
+		for _, comp := range o.Spec.Components {
+			o.Status.Workloads = append(o.Status.Workloads, v1alpha2.WorkloadStatus{
+				ComponentName:         comp.ComponentName,
+				ComponentRevisionName: comp.ComponentName + "-v1",
+				Reference:             runtimev1alpha1.TypedReference{APIVersion: "apps/v1", Kind: "Deployment", Name: comp.ComponentName},
+			})
+		}
+	}
+}
+
+func (c revisionClient) Create(ctx context.Context, obj runtime.Object, opts ...ctrlclient.CreateOption) error {
+	c.track(obj)
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func (c revisionClient) Update(ctx context.Context, obj runtime.Object, opts ...ctrlclient.UpdateOption) error {
+	c.track(obj)
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+func TestApplyInOrder(t *testing.T) {
+	ctx := context.Background()
+	app := appfile.NewAppFile()
+	app.Name = "myapp"
+	app.Services = map[string]appfile.Service{
+		"db":  {"image": "mysql"},
+		"api": {"image": "api", "dependsOn": []string{"db"}},
+	}
+	levels, err := app.GetServiceLevels()
+	assert.NoError(t, err)
+	scope := v1alpha2.ComponentScope{ScopeReference: runtimev1alpha1.TypedReference{Kind: v1alpha2.HealthScopeKind, Name: "myapp-default-health"}}
+	ac := &v1alpha2.ApplicationConfiguration{
+		ObjectMeta: v1.ObjectMeta{Name: "myapp", Namespace: "default"},
+		Spec: v1alpha2.ApplicationConfigurationSpec{Components: []v1alpha2.ApplicationConfigurationComponent{
+			{ComponentName: "db", Scopes: []v1alpha2.ComponentScope{scope}},
+			{ComponentName: "api", Scopes: []v1alpha2.ComponentScope{scope}},
+		}},
+	}
+	comps := []*v1alpha2.Component{
+		{ObjectMeta: v1.ObjectMeta{Name: "db", Namespace: "default"}},
+		{ObjectMeta: v1.ObjectMeta{Name: "api", Namespace: "default"}},
+	}
+	health := &v1alpha2.HealthScope{ObjectMeta: v1.ObjectMeta{Name: "myapp-default-health", Namespace: "default"}}
+	health.Status.WorkloadHealthConditions = []*v1alpha2.WorkloadHealthCondition{{ComponentName: "db", HealthStatus: v1alpha2.StatusHealthy}}
+	client := revisionClient{fake.NewFakeClientWithScheme(common.Scheme, health,
+		&appsv1.Deployment{ObjectMeta: v1.ObjectMeta{Name: "db", Namespace: "default"}})}
+	var out bytes.Buffer
+	o := AppfileOptions{Kubecli: client, IO: util.IOStreams{Out: &out, ErrOut: &out}}
+
+	assert.NoError(t, o.apply(ac, nil, comps, nil, app, levels))
+	assert.Contains(t, out.String(), "Waiting for db to be healthy before deploying api ...")
+	var got v1alpha2.ApplicationConfiguration
+	assert.NoError(t, client.Get(ctx, apitypes.NamespacedName{Namespace: "default", Name: "myapp"}, &got))
+	assert.Equal(t, 2, len(got.Spec.Components))
+	var comp v1alpha2.Component
+	assert.NoError(t, client.Get(ctx, apitypes.NamespacedName{Namespace: "default", Name: "api"}, &comp))
+}
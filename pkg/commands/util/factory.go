@@ -33,6 +33,14 @@ func NewRestConfigGetter(namespace string) genericclioptions.RESTClientGetter {
 	}
 }
 
+// NewRestConfigGetterByConfig creates a RESTClientGetter from the rest.Config, e.g. the one of a cluster which env is bound to
+func NewRestConfigGetterByConfig(config *rest.Config, namespace string) genericclioptions.RESTClientGetter {
+	return &restConfigGetter{
+		config:    config,
+		namespace: namespace,
+	}
+}
+
 type restConfigGetter struct {
 	config    *rest.Config
 	namespace string
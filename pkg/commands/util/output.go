@@ -0,0 +1,145 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"github.com/ghodss/yaml"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// Output formats of commands supporting the `--output` flag
+const (
+	OutputTable      = "table"
+	OutputWide       = "wide"
+	OutputJSON       = "json"
+	OutputYAML       = "yaml"
+	OutputGoTemplate = "go-template"
+	OutputJSONPath   = "jsonpath"
+)
+
+// OutputFlag is the name of the flag for output format
+const OutputFlag = "output"
+
+// AddOutputFlag adds the `-o, --output` flag to the command
+func AddOutputFlag(cmd *cobra.Command) {
+	cmd.Flags().StringP(OutputFlag, "o", OutputTable,
+		"output format, one of: table, wide, json, yaml, go-template=TEMPLATE, jsonpath=EXPRESSION")
+}
+
+// Printer prints objects in json, yaml, go-template and jsonpath formats. Commands print tables by themselves
+// for table and wide formats, which are not stable for scripts.
+type Printer struct {
+	format string
+	// template is the go-template or jsonpath expression
+	template string
+	out      io.Writer
+}
+
+// NewPrinter creates a printer by the output flag of the command, it prints tables if cmd is nil
+func NewPrinter(cmd *cobra.Command, ioStreams IOStreams) (*Printer, error) {
+	format := OutputTable
+	if cmd != nil {
+		var err error
+		if format, err = cmd.Flags().GetString(OutputFlag); err != nil {
+			return nil, err
+		}
+	}
+	return NewPrinterForFormat(format, ioStreams.Out)
+}
+
+// NewPrinterForFormat creates a printer of the output format
+func NewPrinterForFormat(format string, out io.Writer) (*Printer, error) {
+	p := &Printer{format: format, out: out}
+	switch {
+	case format == "":
+		p.format = OutputTable
+	case format == OutputTable, format == OutputWide, format == OutputJSON, format == OutputYAML:
+	case strings.HasPrefix(format, OutputGoTemplate+"="):
+		p.format, p.template = OutputGoTemplate, strings.TrimPrefix(format, OutputGoTemplate+"=")
+	case strings.HasPrefix(format, OutputJSONPath+"="):
+		p.format, p.template = OutputJSONPath, strings.TrimPrefix(format, OutputJSONPath+"=")
+	default:
+		return nil, fmt.Errorf("unknown output format %q, must be one of: table, wide, json, yaml, "+
+			"go-template=TEMPLATE, jsonpath=EXPRESSION", format)
+	}
+	if (p.format == OutputGoTemplate || p.format == OutputJSONPath) && p.template == "" {
+		return nil, fmt.Errorf("template of output format %s is empty", p.format)
+	}
+	return p, nil
+}
+
+// Structured returns whether objects should be printed by the printer rather than tables
+func (p *Printer) Structured() bool {
+	return p.format != OutputTable && p.format != OutputWide
+}
+
+// Wide returns whether tables should include additional columns
+func (p *Printer) Wide() bool {
+	return p.format == OutputWide
+}
+
+// InfoStreams returns the streams for informational messages, they go to ErrOut when printing structured output so
+// that the output could be parsed by scripts
+func (p *Printer) InfoStreams(ioStreams IOStreams) IOStreams {
+	if !p.Structured() || ioStreams.ErrOut == nil {
+		return ioStreams
+	}
+	ioStreams.Out = ioStreams.ErrOut
+	return ioStreams
+}
+
+// Print prints the object in the structured format, objects are printed as their JSON representation,
+// so templates refer to fields by their JSON names
+func (p *Printer) Print(obj interface{}) error {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	switch p.format {
+	case OutputJSON:
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, data, "", "  "); err != nil {
+			return err
+		}
+		buf.WriteByte('\n')
+		_, err = p.out.Write(buf.Bytes())
+		return err
+	case OutputYAML:
+		out, err := yaml.JSONToYAML(data)
+		if err != nil {
+			return err
+		}
+		_, err = p.out.Write(out)
+		return err
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	switch p.format {
+	case OutputGoTemplate:
+		t, err := template.New("output").Parse(p.template)
+		if err != nil {
+			return fmt.Errorf("parse go-template err %w", err)
+		}
+		return t.Execute(p.out, value)
+	case OutputJSONPath:
+		j := jsonpath.New("output").AllowMissingKeys(true)
+		expr := p.template
+		if !strings.HasPrefix(expr, "{") {
+			expr = "{" + expr + "}"
+		}
+		if err := j.Parse(expr); err != nil {
+			return fmt.Errorf("parse jsonpath err %w", err)
+		}
+		return j.Execute(p.out, value)
+	}
+	return fmt.Errorf("output format %s is not structured", p.format)
+}
@@ -0,0 +1,93 @@
+package util
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type outputItem struct {
+	Name   string   `json:"name"`
+	Traits []string `json:"traits,omitempty"`
+}
+
+func TestPrinter(t *testing.T) {
+	items := []outputItem{{Name: "frontend", Traits: []string{"route", "scaler"}}, {Name: "backend"}}
+	cases := map[string]struct {
+		format     string
+		structured bool
+		wide       bool
+		expected   string
+	}{
+		"table": {format: "", structured: false},
+		"wide":  {format: OutputWide, structured: false, wide: true},
+		"json": {format: OutputJSON, structured: true, expected: `[
+  {
+    "name": "frontend",
+    "traits": [
+      "route",
+      "scaler"
+    ]
+  },
+  {
+    "name": "backend"
+  }
+]
+`},
+		"yaml": {format: OutputYAML, structured: true, expected: `- name: frontend
+  traits:
+  - route
+  - scaler
+- name: backend
+`},
+		"go-template":             {format: "go-template={{range .}}{{.name}} {{end}}", structured: true, expected: "frontend backend "},
+		"jsonpath":                {format: "jsonpath={[*].name}", structured: true, expected: "frontend backend"},
+		"jsonpath without braces": {format: "jsonpath=[0].traits[1]", structured: true, expected: "scaler"},
+	}
+	for name, c := range cases {
+		var b bytes.Buffer
+		p, err := NewPrinterForFormat(c.format, &b)
+		assert.NoError(t, err, name)
+		assert.Equal(t, c.structured, p.Structured(), name)
+		assert.Equal(t, c.wide, p.Wide(), name)
+		if !c.structured {
+			continue
+		}
+		assert.NoError(t, p.Print(items), name)
+		assert.Equal(t, c.expected, b.String(), name)
+	}
+}
+
+func TestPrinterErrors(t *testing.T) {
+	var b bytes.Buffer
+	_, err := NewPrinterForFormat("xml", &b)
+	assert.EqualError(t, err, `unknown output format "xml", must be one of: table, wide, json, yaml, go-template=TEMPLATE, jsonpath=EXPRESSION`)
+	_, err = NewPrinterForFormat("go-template=", &b)
+	assert.EqualError(t, err, "template of output format go-template is empty")
+
+	p, err := NewPrinterForFormat("go-template={{.name", &b)
+	assert.NoError(t, err)
+	assert.Error(t, p.Print(outputItem{Name: "frontend"}))
+
+	p, err = NewPrinterForFormat(OutputTable, &b)
+	assert.NoError(t, err)
+	assert.EqualError(t, p.Print(outputItem{Name: "frontend"}), "output format table is not structured")
+}
+
+func TestPrinterInfoStreams(t *testing.T) {
+	var out, errOut bytes.Buffer
+	ioStreams := IOStreams{Out: &out, ErrOut: &errOut}
+
+	p, err := NewPrinter(nil, ioStreams)
+	assert.NoError(t, err)
+	info := p.InfoStreams(ioStreams)
+	info.Info("table")
+	assert.Equal(t, "table\n", out.String())
+
+	p, err = NewPrinterForFormat(OutputJSON, &out)
+	assert.NoError(t, err)
+	info = p.InfoStreams(ioStreams)
+	info.Info("json")
+	assert.Equal(t, "json\n", errOut.String())
+}
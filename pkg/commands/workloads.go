@@ -2,10 +2,12 @@ package commands
 
 import (
 	"context"
+	"strings"
 
 	"github.com/oam-dev/kubevela/apis/types"
 	cmdutil "github.com/oam-dev/kubevela/pkg/commands/util"
-	"github.com/oam-dev/kubevela/pkg/plugins"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/server/apis"
 
 	"github.com/gosuri/uitable"
 	"github.com/spf13/cobra"
@@ -22,16 +24,20 @@ func NewWorkloadsCommand(c types.Args, ioStreams cmdutil.IOStreams) *cobra.Comma
 		Long:                  "List workloads",
 		Example:               `vela workloads`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			p, err := cmdutil.NewPrinter(cmd, ioStreams)
+			if err != nil {
+				return err
+			}
 			if syncCluster {
-				if err := RefreshDefinitions(ctx, c, ioStreams, true); err != nil {
+				if err := RefreshDefinitions(ctx, c, p.InfoStreams(ioStreams), true); err != nil {
 					return err
 				}
 			}
-			workloads, err := plugins.LoadInstalledCapabilityWithType(types.TypeWorkload)
+			workloads, err := oam.ListWorkloads()
 			if err != nil {
 				return err
 			}
-			return printWorkloadList(workloads, ioStreams)
+			return printWorkloadList(workloads, ioStreams, p)
 		},
 		Annotations: map[string]string{
 			types.TagCommandType: types.TypeCap,
@@ -39,15 +45,36 @@ func NewWorkloadsCommand(c types.Args, ioStreams cmdutil.IOStreams) *cobra.Comma
 	}
 	cmd.SetOut(ioStreams.Out)
 	cmd.Flags().BoolVarP(&syncCluster, "sync", "s", true, "Synchronize capabilities from cluster into local")
+	cmdutil.AddOutputFlag(cmd)
 	return cmd
 }
 
-func printWorkloadList(workloadList []types.Capability, ioStreams cmdutil.IOStreams) error {
+func printWorkloadList(workloadList []apis.WorkloadMeta, ioStreams cmdutil.IOStreams, p *cmdutil.Printer) error {
+	if p.Structured() {
+		return p.Print(workloadList)
+	}
 	table := uitable.New()
-	table.AddRow("NAME", "DESCRIPTION")
+	if p.Wide() {
+		table.AddRow("NAME", "DESCRIPTION", "PARAMETERS")
+	} else {
+		table.AddRow("NAME", "DESCRIPTION")
+	}
 	for _, r := range workloadList {
+		if p.Wide() {
+			table.AddRow(r.Name, r.Description, parameterNames(r.Parameters))
+			continue
+		}
 		table.AddRow(r.Name, r.Description)
 	}
 	ioStreams.Info(table.String())
 	return nil
 }
+
+// parameterNames joins names of parameters for wide tables
+func parameterNames(params []types.Parameter) string {
+	names := make([]string, 0, len(params))
+	for _, p := range params {
+		names = append(names, p.Name)
+	}
+	return strings.Join(names, ",")
+}
@@ -0,0 +1,259 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+
+	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	corev1alpha2 "github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam/discoverymapper"
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/appfile"
+	"github.com/oam-dev/kubevela/pkg/appfile/template"
+	"github.com/oam-dev/kubevela/pkg/application"
+	cmdutil "github.com/oam-dev/kubevela/pkg/commands/util"
+)
+
+const (
+	errRenderApplication = "failed to render the application"
+	errApplyApplication  = "failed to apply the application"
+	errGCApplication     = "failed to delete components removed from the application"
+)
+
+// Reconciler reconciles an Application object
+type Reconciler struct {
+	client.Client
+	dm     discoverymapper.DiscoveryMapper
+	log    logr.Logger
+	record event.Recorder
+	Scheme *runtime.Scheme
+}
+
+// Reconcile renders the Application like an Appfile and applies the Components, AppConfig and scopes
+// +kubebuilder:rbac:groups=core.oam.dev,resources=applications,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core.oam.dev,resources=applications/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=core.oam.dev,resources=components;applicationconfigurations;healthscopes,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core.oam.dev,resources=workloaddefinitions;traitdefinitions;scopedefinitions,verbs=get;list;watch
+func (r *Reconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	ctx := context.Background()
+	log := r.log.WithValues("application", req.NamespacedName)
+	log.Info("Reconcile application")
+
+	var app v1alpha2.Application
+	if err := r.Get(ctx, req.NamespacedName, &app); err != nil {
+		if apierrors.IsNotFound(err) {
+			log.Info("application is deleted")
+		}
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if app.Status.ObservedGeneration != app.Generation {
+		app.Status.Phase = v1alpha2.ApplicationRendering
+	}
+
+	comps, ac, scopes, err := r.render(ctx, &app)
+	if err != nil {
+		log.Error(err, errRenderApplication)
+		r.record.Event(&app, event.Warning(errRenderApplication, err))
+		return ctrl.Result{}, r.updateFailedStatus(ctx, &app, errors.Wrap(err, errRenderApplication))
+	}
+	if err = r.apply(ctx, &app, comps, ac, scopes); err != nil {
+		log.Error(err, errApplyApplication)
+		r.record.Event(&app, event.Warning(errApplyApplication, err))
+		return ctrl.Result{}, r.updateFailedStatus(ctx, &app, errors.Wrap(err, errApplyApplication))
+	}
+	if err = r.gcComponents(ctx, &app, comps); err != nil {
+		log.Error(err, errGCApplication)
+		r.record.Event(&app, event.Warning(errGCApplication, err))
+		return ctrl.Result{}, r.updateFailedStatus(ctx, &app, errors.Wrap(err, errGCApplication))
+	}
+	if app.Status.ObservedGeneration != app.Generation {
+		r.record.Event(&app, event.Normal("Application applied",
+			fmt.Sprintf("successfully rendered and applied %d services", len(comps))))
+	}
+
+	services, err := r.serviceStatus(ctx, &app)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	app.Status.Services = services
+	app.Status.Phase = v1alpha2.ApplicationRunning
+	app.Status.ObservedGeneration = app.Generation
+	app.Status.SetConditions(runtimev1alpha1.ReconcileSuccess())
+	return ctrl.Result{}, r.Status().Update(ctx, &app)
+}
+
+func (r *Reconciler) updateFailedStatus(ctx context.Context, app *v1alpha2.Application, err error) error {
+	app.Status.Phase = v1alpha2.ApplicationFailed
+	app.Status.SetConditions(runtimev1alpha1.ReconcileError(err))
+	if updateErr := r.Status().Update(ctx, app); updateErr != nil {
+		return updateErr
+	}
+	return err
+}
+
+// render converts the Application into an Appfile and renders it by definitions in the cluster
+func (r *Reconciler) render(ctx context.Context, app *v1alpha2.Application) (
+	[]*corev1alpha2.Component, *corev1alpha2.ApplicationConfiguration, []oam.Object, error) {
+	af, err := ToAppFile(app)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	tm, templateErrors, err := template.LoadFromCluster(ctx, r, r.dm, app.Namespace)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	for _, e := range templateErrors {
+		r.log.Info("skip definition which is not ready", "reason", e.Error())
+	}
+	io := cmdutil.IOStreams{Out: ioutil.Discard, ErrOut: ioutil.Discard}
+	return af.RenderOAM(app.Namespace, io, tm, true)
+}
+
+// ToAppFile converts the spec of Application into an Appfile, features depending on local env of the CLI,
+// e.g. configs and secrets, are not supported.
+func ToAppFile(app *v1alpha2.Application) (*appfile.AppFile, error) {
+	af := appfile.NewAppFile()
+	af.Name = app.Name
+	for name, raw := range app.Spec.Services {
+		svc := appfile.Service{}
+		if err := json.Unmarshal(raw.Raw, &svc); err != nil {
+			return nil, fmt.Errorf("parse service %s failed: %w", name, err)
+		}
+		if svc.GetUserConfigName() != "" {
+			return nil, fmt.Errorf("config of service %s is not supported by Application", name)
+		}
+		af.Services[name] = svc
+	}
+	af.SetEnv(&types.EnvMeta{Name: app.Namespace, Namespace: app.Namespace})
+	af.DisallowLocalSources()
+	return af, nil
+}
+
+// apply sets the Application as the controller of rendered objects and applies them
+func (r *Reconciler) apply(ctx context.Context, app *v1alpha2.Application, comps []*corev1alpha2.Component,
+	ac *corev1alpha2.ApplicationConfiguration, scopes []oam.Object) error {
+	ref := metav1.NewControllerRef(app, v1alpha2.SchemeGroupVersion.WithKind("Application"))
+	for _, comp := range comps {
+		comp.SetOwnerReferences([]metav1.OwnerReference{*ref})
+	}
+	for _, scope := range scopes {
+		scope.SetOwnerReferences([]metav1.OwnerReference{*ref})
+	}
+	ac.SetOwnerReferences([]metav1.OwnerReference{*ref})
+
+	if err := application.CheckRouteHostConflicts(ctx, r, ac); err != nil {
+		return err
+	}
+	for _, comp := range comps {
+		if err := application.CreateOrUpdateComponent(ctx, r, comp); err != nil {
+			return err
+		}
+	}
+	if err := application.CreateScopes(ctx, r, scopes); err != nil {
+		return err
+	}
+	if err := application.CreateOrUpdateAppConfig(ctx, r, ac); err != nil {
+		return err
+	}
+	return application.DeleteStaleSecrets(ctx, r, ac, scopes)
+}
+
+// gcComponents deletes Components controlled by the Application whose services are removed
+func (r *Reconciler) gcComponents(ctx context.Context, app *v1alpha2.Application, comps []*corev1alpha2.Component) error {
+	var existing corev1alpha2.ComponentList
+	if err := r.List(ctx, &existing, client.InNamespace(app.Namespace)); err != nil {
+		return err
+	}
+	wanted := make(map[string]bool, len(comps))
+	for _, comp := range comps {
+		wanted[comp.Name] = true
+	}
+	for i := range existing.Items {
+		comp := &existing.Items[i]
+		owner := metav1.GetControllerOf(comp)
+		if owner == nil || owner.UID != app.UID || wanted[comp.Name] {
+			continue
+		}
+		if err := r.Delete(ctx, comp); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// serviceStatus collects workloads and traits from the AppConfig, health is evaluated like `vela status` does, by the
+// status policy of the workload definition or the HealthScope
+func (r *Reconciler) serviceStatus(ctx context.Context, app *v1alpha2.Application) ([]v1alpha2.ServiceStatus, error) {
+	var ac corev1alpha2.ApplicationConfiguration
+	if err := r.Get(ctx, client.ObjectKey{Namespace: app.Namespace, Name: app.Name}, &ac); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	names := make([]string, 0, len(app.Spec.Services))
+	for name := range app.Spec.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	services := make([]v1alpha2.ServiceStatus, 0, len(names))
+	for _, name := range names {
+		status := v1alpha2.ServiceStatus{Name: name}
+		for _, w := range ac.Status.Workloads {
+			if w.ComponentName != name {
+				continue
+			}
+			ref := w.Reference
+			status.Workload = &ref
+			for _, tr := range w.Traits {
+				status.Traits = append(status.Traits, tr.Reference)
+			}
+		}
+		health, message, err := application.ComponentHealth(ctx, r, &ac, name)
+		if err != nil {
+			return nil, err
+		}
+		status.HealthStatus = string(health)
+		status.Message = message
+		services = append(services, status)
+	}
+	return services, nil
+}
+
+// SetupWithManager setup the controller with manager
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.record = event.NewAPIRecorder(mgr.GetEventRecorderFor("Application")).
+		WithAnnotations("controller", "Application")
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha2.Application{}).
+		Owns(&corev1alpha2.Component{}).
+		Owns(&corev1alpha2.ApplicationConfiguration{}).
+		Owns(&corev1alpha2.HealthScope{}).
+		Complete(r)
+}
+
+// Setup adds a controller that reconciles Application.
+func Setup(mgr ctrl.Manager) error {
+	dm, err := discoverymapper.New(mgr.GetConfig())
+	if err != nil {
+		return err
+	}
+	reconciler := Reconciler{
+		Client: mgr.GetClient(),
+		dm:     dm,
+		log:    ctrl.Log.WithName("Application"),
+		Scheme: mgr.GetScheme(),
+	}
+	return reconciler.SetupWithManager(mgr)
+}
@@ -0,0 +1,169 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	corev1alpha2 "github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam/mock"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+const webserviceTemplate = `output: {
+	apiVersion: "apps/v1"
+	kind:       "Deployment"
+	spec: template: spec: containers: [{
+		name:  context.name
+		image: parameter.image
+	}]
+}
+parameter: {
+	image: string
+}
+`
+
+const scalerTemplate = `output: {
+	apiVersion: "core.oam.dev/v1alpha2"
+	kind:       "ManualScalerTrait"
+	spec: replicaCount: parameter.replicas
+}
+parameter: {
+	replicas: *1 | int
+}
+`
+
+func extension(t *testing.T, tmpl string) *runtime.RawExtension {
+	raw, err := json.Marshal(map[string]string{"template": tmpl})
+	assert.NoError(t, err)
+	return &runtime.RawExtension{Raw: raw}
+}
+
+func rawService(t *testing.T, svc map[string]interface{}) runtime.RawExtension {
+	raw, err := json.Marshal(svc)
+	assert.NoError(t, err)
+	return runtime.RawExtension{Raw: raw}
+}
+
+func TestReconcile(t *testing.T) {
+	ctx := context.Background()
+	app := &v1alpha2.Application{
+		ObjectMeta: metav1.ObjectMeta{Name: "myapp", Namespace: "default", UID: "app-uid"},
+		Spec: v1alpha2.ApplicationSpec{Services: map[string]runtime.RawExtension{
+			"frontend": rawService(t, map[string]interface{}{
+				"type": "webservice", "image": "nginx", "scaler": map[string]interface{}{"replicas": 2},
+			}),
+		}},
+	}
+	stale := &corev1alpha2.Component{ObjectMeta: metav1.ObjectMeta{Name: "removed", Namespace: "default",
+		OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(app, v1alpha2.SchemeGroupVersion.WithKind("Application"))}}}
+	other := &corev1alpha2.Component{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default"}}
+	c := fake.NewFakeClientWithScheme(common.Scheme, app, stale, other,
+		&corev1alpha2.WorkloadDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: "webservice", Namespace: "vela-system"},
+			Spec: corev1alpha2.WorkloadDefinitionSpec{
+				Reference: corev1alpha2.DefinitionReference{Name: "deployments.apps"},
+				Extension: extension(t, webserviceTemplate),
+			},
+		},
+		&corev1alpha2.TraitDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: "scaler", Namespace: "vela-system"},
+			Spec: corev1alpha2.TraitDefinitionSpec{
+				Reference: corev1alpha2.DefinitionReference{Name: "manualscalertraits.core.oam.dev"},
+				Extension: extension(t, scalerTemplate),
+			},
+		},
+	)
+	dm := mock.NewMockDiscoveryMapper()
+	dm.MockKindsFor = func(gvr schema.GroupVersionResource) ([]schema.GroupVersionKind, error) {
+		kinds := map[string]string{"deployments": "Deployment", "manualscalertraits": "ManualScalerTrait"}
+		return []schema.GroupVersionKind{{Group: gvr.Group, Version: "v1", Kind: kinds[gvr.Resource]}}, nil
+	}
+	r := &Reconciler{Client: c, dm: dm, log: ctrl.Log.WithName("Application"), record: event.NewNopRecorder(), Scheme: common.Scheme}
+
+	req := ctrl.Request{NamespacedName: client.ObjectKey{Namespace: "default", Name: "myapp"}}
+	_, err := r.Reconcile(req)
+	assert.NoError(t, err)
+
+	var comp corev1alpha2.Component
+	assert.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "frontend"}, &comp))
+	assert.Equal(t, "myapp", metav1.GetControllerOf(&comp).Name)
+	var workload unstructured.Unstructured
+	assert.NoError(t, json.Unmarshal(comp.Spec.Workload.Raw, &workload.Object))
+	assert.Equal(t, "Deployment", workload.GetKind())
+
+	var ac corev1alpha2.ApplicationConfiguration
+	assert.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "myapp"}, &ac))
+	assert.Equal(t, "myapp", metav1.GetControllerOf(&ac).Name)
+	assert.Equal(t, 1, len(ac.Spec.Components))
+	assert.Equal(t, 1, len(ac.Spec.Components[0].Traits))
+
+	// components removed from the application are deleted, others are left
+	assert.Error(t, c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "removed"}, &comp))
+	assert.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "other"}, &comp))
+
+	var got v1alpha2.Application
+	assert.NoError(t, c.Get(ctx, req.NamespacedName, &got))
+	assert.Equal(t, v1alpha2.ApplicationRunning, got.Status.Phase)
+	assert.Equal(t, []v1alpha2.ServiceStatus{{Name: "frontend"}}, got.Status.Services)
+
+	// status of services comes from the AppConfig and HealthScope
+	ac.Status.Workloads = []corev1alpha2.WorkloadStatus{{
+		ComponentName: "frontend",
+		Reference:     runtimev1alpha1.TypedReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "frontend"},
+	}}
+	assert.NoError(t, c.Status().Update(ctx, &ac))
+	var health corev1alpha2.HealthScope
+	assert.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "myapp-default-health"}, &health))
+	health.Status.WorkloadHealthConditions = []*corev1alpha2.WorkloadHealthCondition{{
+		ComponentName: "frontend", HealthStatus: corev1alpha2.StatusHealthy, Diagnosis: "Ready: 2/2",
+	}}
+	assert.NoError(t, c.Status().Update(ctx, &health))
+
+	services, err := r.serviceStatus(ctx, &got)
+	assert.NoError(t, err)
+	assert.Equal(t, []v1alpha2.ServiceStatus{{
+		Name:         "frontend",
+		Workload:     &runtimev1alpha1.TypedReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "frontend"},
+		HealthStatus: string(corev1alpha2.StatusHealthy),
+		Message:      "Ready: 2/2",
+	}}, services)
+
+	// services of unknown types fail to render
+	got.Spec.Services["backend"] = rawService(t, map[string]interface{}{"type": "worker", "image": "busybox"})
+	assert.NoError(t, c.Update(ctx, &got))
+	_, err = r.Reconcile(req)
+	assert.Error(t, err)
+	assert.NoError(t, c.Get(ctx, req.NamespacedName, &got))
+	assert.Equal(t, v1alpha2.ApplicationFailed, got.Status.Phase)
+	assert.Equal(t, runtimev1alpha1.ReasonReconcileError, got.Status.GetCondition(runtimev1alpha1.TypeSynced).Reason)
+}
+
+func TestToAppFile(t *testing.T) {
+	app := &v1alpha2.Application{
+		ObjectMeta: metav1.ObjectMeta{Name: "myapp", Namespace: "default"},
+		Spec: v1alpha2.ApplicationSpec{Services: map[string]runtime.RawExtension{
+			"frontend": rawService(t, map[string]interface{}{"image": "nginx"}),
+		}},
+	}
+	af, err := ToAppFile(app)
+	assert.NoError(t, err)
+	assert.Equal(t, "myapp", af.Name)
+	assert.Equal(t, "webservice", af.Services["frontend"].GetType())
+
+	app.Spec.Services["backend"] = rawService(t, map[string]interface{}{"image": "busybox", "config": "demo"})
+	_, err = ToAppFile(app)
+	assert.EqualError(t, err, "config of service backend is not supported by Application")
+}
@@ -0,0 +1,346 @@
+package gitsource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/appfile"
+	"github.com/oam-dev/kubevela/pkg/utils/env"
+	"github.com/oam-dev/kubevela/pkg/utils/git"
+)
+
+// Labels and annotations of Applications synced from a GitSource
+const (
+	// LabelSourceName records the name of the GitSource which the Application is synced from
+	LabelSourceName = "gitsource.oam.dev/name"
+	// LabelSourceNamespace records the namespace of the GitSource which the Application is synced from
+	LabelSourceNamespace = "gitsource.oam.dev/namespace"
+	// AnnotationCommit records the commit which the Application is synced from
+	AnnotationCommit = "gitsource.oam.dev/commit"
+	// AnnotationFile records the path of the Appfile which the Application is synced from
+	AnnotationFile = "gitsource.oam.dev/file"
+)
+
+const (
+	errLocateEnv      = "failed to locate the env"
+	errSyncRepository = "failed to sync the repository"
+	errApplyAppfiles  = "failed to apply Appfiles"
+	errPruneApps      = "failed to delete applications removed from the repository"
+)
+
+// defaultInterval of polling the repository
+var defaultInterval = time.Minute
+
+// Reconciler reconciles a GitSource object
+type Reconciler struct {
+	client.Client
+	log    logr.Logger
+	record event.Recorder
+	Scheme *runtime.Scheme
+}
+
+// Reconcile polls the repository and syncs Appfiles of new commits into Applications
+// +kubebuilder:rbac:groups=core.oam.dev,resources=gitsources,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core.oam.dev,resources=gitsources/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=core.oam.dev,resources=applications,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
+func (r *Reconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	ctx := context.Background()
+	log := r.log.WithValues("gitsource", req.NamespacedName)
+
+	var src v1alpha2.GitSource
+	if err := r.Get(ctx, req.NamespacedName, &src); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	interval := defaultInterval
+	if src.Spec.Interval != nil && src.Spec.Interval.Duration > 0 {
+		interval = src.Spec.Interval.Duration
+	}
+	result := ctrl.Result{RequeueAfter: interval}
+	branch := src.Spec.Branch
+	if branch == "" {
+		branch = git.DefaultBranch
+	}
+
+	envMeta, err := r.getEnv(ctx, &src)
+	if err != nil {
+		log.Error(err, errLocateEnv)
+		r.record.Event(&src, event.Warning(errLocateEnv, err))
+		return result, r.updateFailedStatus(ctx, &src, errors.Wrap(err, errLocateEnv))
+	}
+
+	head, err := git.HeadCommit(ctx, src.Spec.Repository, branch)
+	if err != nil {
+		log.Error(err, errSyncRepository)
+		r.record.Event(&src, event.Warning(errSyncRepository, err))
+		return result, r.updateFailedStatus(ctx, &src, errors.Wrap(err, errSyncRepository))
+	}
+	now := metav1.Now()
+	src.Status.LastSyncTime = &now
+	if head == src.Status.Commit && src.Status.ObservedGeneration == src.Generation {
+		return result, r.Status().Update(ctx, &src)
+	}
+
+	log.Info("Sync new commit", "commit", head)
+	dir, err := ioutil.TempDir("", "vela-gitsource")
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	defer os.RemoveAll(dir)
+	repoDir := filepath.Join(dir, "repo")
+	commit, err := git.Clone(ctx, src.Spec.Repository, branch, repoDir)
+	if err != nil {
+		log.Error(err, errSyncRepository)
+		r.record.Event(&src, event.Warning(errSyncRepository, err))
+		return result, r.updateFailedStatus(ctx, &src, errors.Wrap(err, errSyncRepository))
+	}
+
+	apps, err := r.applyAppfiles(ctx, &src, envMeta, repoDir, commit)
+	if err != nil {
+		log.Error(err, errApplyAppfiles)
+		r.record.Event(&src, event.Warning(errApplyAppfiles, err))
+		return result, r.updateFailedStatus(ctx, &src, errors.Wrap(err, errApplyAppfiles))
+	}
+	var failed []string
+	for _, app := range apps {
+		if app.Error != "" {
+			failed = append(failed, app.File)
+		}
+	}
+	// an Appfile which can't be parsed may still have its Application, so only prune if all Appfiles are applied
+	if len(failed) == 0 {
+		if err = r.prune(ctx, &src, apps, envMeta.Namespace); err != nil {
+			log.Error(err, errPruneApps)
+			r.record.Event(&src, event.Warning(errPruneApps, err))
+			return result, r.updateFailedStatus(ctx, &src, errors.Wrap(err, errPruneApps))
+		}
+	}
+
+	src.Status.Commit = commit
+	src.Status.Applications = apps
+	src.Status.ObservedGeneration = src.Generation
+	if len(failed) > 0 {
+		err = fmt.Errorf("%s: %s", errApplyAppfiles, strings.Join(failed, ", "))
+		r.record.Event(&src, event.Warning(errApplyAppfiles, err))
+		src.Status.SetConditions(runtimev1alpha1.ReconcileError(err))
+	} else {
+		r.record.Event(&src, event.Normal("Commit synced",
+			fmt.Sprintf("successfully synced %d applications from commit %s", len(apps), commit)))
+		src.Status.SetConditions(runtimev1alpha1.ReconcileSuccess())
+	}
+	return result, r.Status().Update(ctx, &src)
+}
+
+func (r *Reconciler) updateFailedStatus(ctx context.Context, src *v1alpha2.GitSource, err error) error {
+	src.Status.SetConditions(runtimev1alpha1.ReconcileError(err))
+	return r.Status().Update(ctx, src)
+}
+
+// getEnv reads the env of the GitSource from the cluster, Applications are created in the namespace of the GitSource
+// if no env is specified. A GitSource can only sync into its own namespace, otherwise anyone able to create a
+// GitSource could create Applications in every namespace of the cluster.
+func (r *Reconciler) getEnv(ctx context.Context, src *v1alpha2.GitSource) (*types.EnvMeta, error) {
+	name := src.Spec.Env
+	if name == "" {
+		return &types.EnvMeta{Name: src.Namespace, Namespace: src.Namespace}, nil
+	}
+	envMeta, err := env.GetEnvFromCluster(ctx, r, name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("env %s not exist", name)
+		}
+		return nil, err
+	}
+	if envMeta.Namespace != src.Namespace {
+		return nil, fmt.Errorf("env %s is in namespace %s, only envs in namespace %s of the GitSource are allowed",
+			name, envMeta.Namespace, src.Namespace)
+	}
+	return envMeta, nil
+}
+
+// resolveInRepo returns the real path of path in the clone, it fails if the path or a symlink in it points outside
+// the clone, so files of the controller are never read as Appfiles
+func resolveInRepo(repoDir, path string) (string, error) {
+	if filepath.IsAbs(path) {
+		return "", fmt.Errorf("path %s must be relative to the root of repository", path)
+	}
+	root, err := filepath.EvalSymlinks(repoDir)
+	if err != nil {
+		return "", err
+	}
+	resolved, err := filepath.EvalSymlinks(filepath.Join(root, path))
+	if err != nil {
+		return "", err
+	}
+	if rel, err := filepath.Rel(root, resolved); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("path %s is outside of the repository", path)
+	}
+	return resolved, nil
+}
+
+// applyAppfiles applies every Appfile in the path of repository as an Application into the env namespace,
+// errors of Appfiles are recorded in the returned status rather than failing the others.
+func (r *Reconciler) applyAppfiles(ctx context.Context, src *v1alpha2.GitSource, envMeta *types.EnvMeta,
+	repoDir, commit string) ([]v1alpha2.SyncedApplication, error) {
+	dir, err := resolveInRepo(repoDir, src.Spec.Path)
+	if err != nil {
+		return nil, fmt.Errorf("read path %s of repository failed: %w", src.Spec.Path, err)
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read path %s of repository failed: %w", src.Spec.Path, err)
+	}
+	apps := make([]v1alpha2.SyncedApplication, 0)
+	claimed := make(map[string]string)
+	for _, f := range files {
+		switch filepath.Ext(f.Name()) {
+		case ".yaml", ".yml", ".json":
+		default:
+			continue
+		}
+		if f.IsDir() {
+			continue
+		}
+		file := filepath.Join(src.Spec.Path, f.Name())
+		synced := v1alpha2.SyncedApplication{File: file}
+		path, err := resolveInRepo(repoDir, file)
+		var app *v1alpha2.Application
+		if err == nil {
+			app, err = toApplication(path)
+		}
+		if err == nil {
+			synced.Name = app.Name
+			if other, ok := claimed[app.Name]; ok {
+				err = fmt.Errorf("application %s is also defined in %s", app.Name, other)
+			} else {
+				claimed[app.Name] = file
+				err = r.apply(ctx, src, app, envMeta.Namespace, file, commit)
+			}
+		}
+		if err != nil {
+			synced.Error = err.Error()
+		}
+		apps = append(apps, synced)
+	}
+	return apps, nil
+}
+
+// toApplication loads the Appfile as an Application
+func toApplication(path string) (*v1alpha2.Application, error) {
+	af, err := appfile.LoadFromFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if af.Name == "" {
+		return nil, errors.New("name of Appfile is required")
+	}
+	app := &v1alpha2.Application{
+		ObjectMeta: metav1.ObjectMeta{Name: af.Name},
+		Spec:       v1alpha2.ApplicationSpec{Services: make(map[string]runtime.RawExtension, len(af.Services))},
+	}
+	for name, svc := range af.Services {
+		raw, err := json.Marshal(svc)
+		if err != nil {
+			return nil, err
+		}
+		app.Spec.Services[name] = runtime.RawExtension{Raw: raw}
+	}
+	return app, nil
+}
+
+// apply creates or updates the Application, Applications not synced from the GitSource are never overwritten
+func (r *Reconciler) apply(ctx context.Context, src *v1alpha2.GitSource, app *v1alpha2.Application, namespace, file, commit string) error {
+	app.Namespace = namespace
+	app.Labels = map[string]string{LabelSourceName: src.Name, LabelSourceNamespace: src.Namespace}
+	app.Annotations = map[string]string{AnnotationCommit: commit, AnnotationFile: file}
+
+	var exist v1alpha2.Application
+	if err := r.Get(ctx, client.ObjectKey{Namespace: namespace, Name: app.Name}, &exist); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		return r.Create(ctx, app)
+	}
+	if !syncedFrom(&exist, src) {
+		return fmt.Errorf("application %s already exists in namespace %s and is not synced from this source", app.Name, namespace)
+	}
+	exist.Labels = app.Labels
+	exist.Annotations = app.Annotations
+	exist.Spec = app.Spec
+	return r.Update(ctx, &exist)
+}
+
+func syncedFrom(app *v1alpha2.Application, src *v1alpha2.GitSource) bool {
+	return app.Labels[LabelSourceName] == src.Name && app.Labels[LabelSourceNamespace] == src.Namespace
+}
+
+// prune deletes Applications synced from the GitSource which are not in the commit any more,
+// including those in the namespace of the previous env.
+func (r *Reconciler) prune(ctx context.Context, src *v1alpha2.GitSource, synced []v1alpha2.SyncedApplication, namespace string) error {
+	keep := make(map[string]bool, len(synced))
+	for _, app := range synced {
+		keep[app.Name] = true
+	}
+	var apps v1alpha2.ApplicationList
+	if err := r.List(ctx, &apps, client.MatchingLabels{LabelSourceName: src.Name, LabelSourceNamespace: src.Namespace}); err != nil {
+		return err
+	}
+	var pruned []string
+	for i := range apps.Items {
+		app := &apps.Items[i]
+		if app.Namespace == namespace && keep[app.Name] {
+			continue
+		}
+		if err := r.Delete(ctx, app); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		pruned = append(pruned, app.Namespace+"/"+app.Name)
+	}
+	if len(pruned) > 0 {
+		sort.Strings(pruned)
+		r.record.Event(src, event.Normal("Applications pruned",
+			fmt.Sprintf("deleted applications removed from the repository: %s", strings.Join(pruned, ", "))))
+	}
+	return nil
+}
+
+// SetupWithManager setup the controller with manager
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.record = event.NewAPIRecorder(mgr.GetEventRecorderFor("GitSource")).
+		WithAnnotations("controller", "GitSource")
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha2.GitSource{}).
+		// status updates of every poll must not trigger another poll
+		WithEventFilter(predicate.GenerationChangedPredicate{}).
+		Complete(r)
+}
+
+// Setup adds a controller that reconciles GitSource.
+func Setup(mgr ctrl.Manager) error {
+	reconciler := Reconciler{
+		Client: mgr.GetClient(),
+		log:    ctrl.Log.WithName("GitSource"),
+		Scheme: mgr.GetScheme(),
+	}
+	return reconciler.SetupWithManager(mgr)
+}
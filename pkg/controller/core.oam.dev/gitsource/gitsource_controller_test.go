@@ -0,0 +1,182 @@
+package gitsource
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+	"github.com/oam-dev/kubevela/pkg/utils/env"
+	"github.com/oam-dev/kubevela/pkg/utils/system"
+)
+
+// commit writes files into the repository and commits them, files with empty content are removed
+func commit(t *testing.T, repo string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(repo, name)
+		if content == "" {
+			assert.NoError(t, os.Remove(path))
+			continue
+		}
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0700))
+		assert.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+	}
+	for _, args := range [][]string{
+		{"add", "-A"},
+		{"-c", "user.name=vela", "-c", "user.email=vela@example.com", "commit", "--quiet", "-m", "update"},
+		{"branch", "-M", "master"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		out, err := cmd.CombinedOutput()
+		assert.NoError(t, err, string(out))
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	ctx := context.Background()
+	repo, err := ioutil.TempDir("", "vela-gitsource-repo")
+	assert.NoError(t, err)
+	defer os.RemoveAll(repo)
+	cmd := exec.Command("git", "init", "--quiet", repo)
+	assert.NoError(t, cmd.Run())
+	commit(t, repo, map[string]string{
+		"apps/frontend.yaml": "name: frontend\nservices:\n  web:\n    image: nginx:1.18\n",
+		"apps/backend.yml":   "name: backend\nservices:\n  worker:\n    type: worker\n    image: busybox\n",
+		"apps/README.md":     "not an Appfile",
+	})
+
+	envData, err := json.Marshal(&types.EnvMeta{Name: "prod", Namespace: "prod"})
+	assert.NoError(t, err)
+	otherEnvData, err := json.Marshal(&types.EnvMeta{Name: "system", Namespace: "kube-system"})
+	assert.NoError(t, err)
+	src := &v1alpha2.GitSource{
+		ObjectMeta: metav1.ObjectMeta{Name: "apps", Namespace: "prod"},
+		Spec:       v1alpha2.GitSourceSpec{Repository: "file://" + repo, Path: "apps", Env: "prod"},
+	}
+	c := fake.NewFakeClientWithScheme(common.Scheme, src,
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: env.FormatEnvConfigMapName("prod"), Namespace: types.DefaultKubeVelaNS},
+			Data:       map[string]string{system.EnvConfigName: string(envData)},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: env.FormatEnvConfigMapName("system"), Namespace: types.DefaultKubeVelaNS},
+			Data:       map[string]string{system.EnvConfigName: string(otherEnvData)},
+		},
+		&v1alpha2.Application{ObjectMeta: metav1.ObjectMeta{Name: "taken", Namespace: "prod"}},
+	)
+	r := &Reconciler{Client: c, log: ctrl.Log.WithName("GitSource"), record: event.NewNopRecorder(), Scheme: common.Scheme}
+	req := ctrl.Request{NamespacedName: client.ObjectKey{Namespace: "prod", Name: "apps"}}
+	reconcile := func() *v1alpha2.GitSource {
+		result, err := r.Reconcile(req)
+		assert.NoError(t, err)
+		assert.Equal(t, defaultInterval, result.RequeueAfter)
+		var got v1alpha2.GitSource
+		assert.NoError(t, c.Get(ctx, req.NamespacedName, &got))
+		return &got
+	}
+	head := func() string {
+		cmd := exec.Command("git", "rev-parse", "HEAD")
+		cmd.Dir = repo
+		out, err := cmd.Output()
+		assert.NoError(t, err)
+		return string(out[:40])
+	}
+
+	got := reconcile()
+	assert.Equal(t, head(), got.Status.Commit)
+	assert.NotNil(t, got.Status.LastSyncTime)
+	assert.Equal(t, []v1alpha2.SyncedApplication{
+		{Name: "backend", File: "apps/backend.yml"},
+		{Name: "frontend", File: "apps/frontend.yaml"},
+	}, got.Status.Applications)
+	assert.Equal(t, runtimev1alpha1.ReasonReconcileSuccess, got.Status.GetCondition(runtimev1alpha1.TypeSynced).Reason)
+	var app v1alpha2.Application
+	assert.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: "prod", Name: "frontend"}, &app))
+	assert.Equal(t, "apps", app.Labels[LabelSourceName])
+	assert.Equal(t, head(), app.Annotations[AnnotationCommit])
+	assert.JSONEq(t, `{"image":"nginx:1.18"}`, string(app.Spec.Services["web"].Raw))
+
+	// new commits are applied and applications removed from the repository are pruned
+	commit(t, repo, map[string]string{
+		"apps/frontend.yaml": "name: frontend\nservices:\n  web:\n    image: nginx:1.19\n",
+		"apps/backend.yml":   "",
+	})
+	got = reconcile()
+	assert.Equal(t, head(), got.Status.Commit)
+	assert.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: "prod", Name: "frontend"}, &app))
+	assert.JSONEq(t, `{"image":"nginx:1.19"}`, string(app.Spec.Services["web"].Raw))
+	assert.Error(t, c.Get(ctx, client.ObjectKey{Namespace: "prod", Name: "backend"}, &app))
+
+	// errors of Appfiles are recorded and nothing is pruned
+	commit(t, repo, map[string]string{
+		"apps/frontend.yaml": "name: [frontend\n",
+		"apps/taken.yaml":    "name: taken\nservices:\n  web:\n    image: nginx\n",
+	})
+	got = reconcile()
+	assert.Equal(t, head(), got.Status.Commit)
+	assert.Equal(t, 2, len(got.Status.Applications))
+	assert.NotEmpty(t, got.Status.Applications[0].Error)
+	assert.Equal(t, "application taken already exists in namespace prod and is not synced from this source",
+		got.Status.Applications[1].Error)
+	assert.Equal(t, runtimev1alpha1.ReasonReconcileError, got.Status.GetCondition(runtimev1alpha1.TypeSynced).Reason)
+	assert.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: "prod", Name: "frontend"}, &app))
+	var taken v1alpha2.Application
+	assert.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: "prod", Name: "taken"}, &taken))
+	assert.Empty(t, taken.Labels[LabelSourceName])
+
+	// Appfiles linked to files outside of the repository are never read
+	assert.NoError(t, os.Symlink("/etc/hostname", filepath.Join(repo, "apps", "host.yaml")))
+	commit(t, repo, map[string]string{"apps/frontend.yaml": "name: frontend\nservices:\n  web:\n    image: nginx:1.20\n"})
+	got = reconcile()
+	assert.Equal(t, 3, len(got.Status.Applications))
+	assert.Equal(t, "path apps/host.yaml is outside of the repository", got.Status.Applications[1].Error)
+
+	// the path must be in the repository
+	for path, msg := range map[string]string{
+		"../..":  "path ../.. is outside of the repository",
+		"/etc":   "path /etc must be relative to the root of repository",
+		"apps/.": "",
+	} {
+		got.Spec.Path = path
+		got.Generation++
+		assert.NoError(t, c.Update(ctx, got))
+		got = reconcile()
+		if msg == "" {
+			assert.Equal(t, 3, len(got.Status.Applications))
+			continue
+		}
+		assert.Equal(t, runtimev1alpha1.ReasonReconcileError, got.Status.GetCondition(runtimev1alpha1.TypeSynced).Reason)
+		assert.Contains(t, got.Status.GetCondition(runtimev1alpha1.TypeSynced).Message, msg)
+	}
+
+	// unknown env
+	got.Spec.Env = "staging"
+	assert.NoError(t, c.Update(ctx, got))
+	got = reconcile()
+	assert.Equal(t, runtimev1alpha1.ReasonReconcileError, got.Status.GetCondition(runtimev1alpha1.TypeSynced).Reason)
+	assert.Contains(t, got.Status.GetCondition(runtimev1alpha1.TypeSynced).Message, "env staging not exist")
+
+	// envs in other namespaces are rejected
+	got.Spec.Env = "system"
+	assert.NoError(t, c.Update(ctx, got))
+	got = reconcile()
+	assert.Equal(t, runtimev1alpha1.ReasonReconcileError, got.Status.GetCondition(runtimev1alpha1.TypeSynced).Reason)
+	assert.Contains(t, got.Status.GetCondition(runtimev1alpha1.TypeSynced).Message,
+		"env system is in namespace kube-system, only envs in namespace prod of the GitSource are allowed")
+}
@@ -19,7 +19,9 @@ package controller
 import (
 	ctrl "sigs.k8s.io/controller-runtime"
 
+	"github.com/oam-dev/kubevela/pkg/controller/core.oam.dev/application"
 	"github.com/oam-dev/kubevela/pkg/controller/core.oam.dev/applicationdeployment"
+	"github.com/oam-dev/kubevela/pkg/controller/core.oam.dev/gitsource"
 	autoscalers "github.com/oam-dev/kubevela/pkg/controller/standard.oam.dev/v1alpha1/autoscaler"
 	"github.com/oam-dev/kubevela/pkg/controller/standard.oam.dev/v1alpha1/metrics"
 	"github.com/oam-dev/kubevela/pkg/controller/standard.oam.dev/v1alpha1/podspecworkload"
@@ -30,7 +32,7 @@ import (
 func Setup(mgr ctrl.Manager) error {
 	for _, setup := range []func(ctrl.Manager) error{
 		metrics.Setup, podspecworkload.Setup, routes.Setup,
-		applicationdeployment.Setup, autoscalers.Setup,
+		applicationdeployment.Setup, autoscalers.Setup, application.Setup, gitsource.Setup,
 	} {
 		if err := setup(mgr); err != nil {
 			return err
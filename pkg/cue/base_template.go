@@ -4,10 +4,43 @@ package cue
 const BaseTemplate = `
 
 context: {
+  // name is the name of the service
   name: string
+  // appName is the name of the app which the service belongs to
+  appName: string
+  // namespace which the app is deployed to
+  namespace: string
+  // envName is the name of the env which the app is deployed to
+  envName?: string
+  // host is generated from the domain of env, routes without an explicit host use it
+  host?: string
+  // issuer is the certificate issuer of env and issuerType is Issuer or ClusterIssuer, routes without an explicit issuer use them
+  issuer?: string
+  issuerType?: string
+  // routeProvider is the ingress controller serving routes of env, routes without an explicit provider use it
+  routeProvider?: string
+  // output is the rendered workload of the service, it's only available to traits
+  output?: {...}
+  // ports are container ports of the workload, it's only available to traits of workloads with a pod template in spec.template
+  ports?: [...int]
+  // labels are labels of pods of the workload, it's only available to traits of workloads with a pod template in spec.template
+  labels?: [string]: string
+  // config is the config of service synced into a Secret, each item is an env var referring to a key of the Secret
   config?: [...{
     name: string
-    value: string
+    valueFrom: secretKeyRef: {
+      name: string
+      key: string
+    }
   }]
+  configRef?: {
+    name: string
+    keys: [...string]
+    hash: string
+  }
+  secrets?: [string]: {
+    name: string
+    keys: [...string]
+  }
 }
 `
@@ -0,0 +1,57 @@
+package cue
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"cuelang.org/go/cue"
+)
+
+const (
+	healthFieldName  = "health"
+	messageFieldName = "message"
+)
+
+// EvalStatus evaluates the health expression and the message template of a definition against a live object, which
+// is available as `context.output`. An object is healthy if health is empty. Expressions referring to fields which the
+// object doesn't have yet, e.g. its status, are not available: the object is not healthy and the message is empty.
+func EvalStatus(health, message string, obj map[string]interface{}) (bool, string, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return false, "", err
+	}
+	src := fmt.Sprintf("context: %s: %s\n", OutputFieldName, data)
+	if health != "" {
+		src += fmt.Sprintf("%s: %s\n", healthFieldName, health)
+	}
+	if message != "" {
+		src += fmt.Sprintf("%s: %s\n", messageFieldName, message)
+	}
+	r := cue.Runtime{}
+	inst, err := r.Compile("", src)
+	if err != nil {
+		return false, "", fmt.Errorf("compile health and message err %w", err)
+	}
+
+	healthy := true
+	if health != "" {
+		v := inst.Lookup(healthFieldName)
+		if healthy, err = v.Bool(); err != nil {
+			if v.Kind() != cue.BottomKind {
+				return false, "", fmt.Errorf("evaluate health err %w", err)
+			}
+			healthy = false
+		}
+	}
+	var msg string
+	if message != "" {
+		v := inst.Lookup(messageFieldName)
+		if msg, err = v.String(); err != nil {
+			if v.Kind() != cue.BottomKind {
+				return false, "", fmt.Errorf("evaluate message err %w", err)
+			}
+			msg = ""
+		}
+	}
+	return healthy, msg, nil
+}
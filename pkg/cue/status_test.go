@@ -0,0 +1,66 @@
+package cue
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvalStatus(t *testing.T) {
+	health := `context.output.status.readyReplicas == context.output.spec.replicas`
+	message := `"Ready: \(context.output.status.readyReplicas)/\(context.output.spec.replicas)"`
+	cases := map[string]struct {
+		health  string
+		message string
+		obj     map[string]interface{}
+		healthy bool
+		msg     string
+		err     bool
+	}{
+		"healthy": {
+			health: health, message: message,
+			obj:     map[string]interface{}{"spec": map[string]interface{}{"replicas": 2}, "status": map[string]interface{}{"readyReplicas": 2}},
+			healthy: true, msg: "Ready: 2/2",
+		},
+		"unhealthy": {
+			health: health, message: message,
+			obj:     map[string]interface{}{"spec": map[string]interface{}{"replicas": 2}, "status": map[string]interface{}{"readyReplicas": 1}},
+			healthy: false, msg: "Ready: 1/2",
+		},
+		"status not available": {
+			health: health, message: message,
+			obj:     map[string]interface{}{"spec": map[string]interface{}{"replicas": 2}},
+			healthy: false, msg: "",
+		},
+		"healthy without health": {
+			message: `"replicas: \(context.output.spec.replicas)"`,
+			obj:     map[string]interface{}{"spec": map[string]interface{}{"replicas": 2}},
+			healthy: true, msg: "replicas: 2",
+		},
+		"health is not a bool": {
+			health: `"yes"`,
+			obj:    map[string]interface{}{},
+			err:    true,
+		},
+		"message is not a string": {
+			message: `context.output.spec.replicas`,
+			obj:     map[string]interface{}{"spec": map[string]interface{}{"replicas": 2}},
+			err:     true,
+		},
+		"invalid expression": {
+			health: `context.output.spec.replicas ==`,
+			obj:    map[string]interface{}{},
+			err:    true,
+		},
+	}
+	for name, c := range cases {
+		healthy, msg, err := EvalStatus(c.health, c.message, c.obj)
+		if c.err {
+			assert.Error(t, err, name)
+			continue
+		}
+		assert.NoError(t, err, name)
+		assert.Equal(t, c.healthy, healthy, name)
+		assert.Equal(t, c.msg, msg, name)
+	}
+}
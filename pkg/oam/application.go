@@ -9,6 +9,7 @@ import (
 	"github.com/AlecAivazis/survey/v2"
 	corev1alpha2 "github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
 	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -64,6 +65,8 @@ type DeleteOptions struct {
 	CompName string
 	Client   client.Client
 	Env      *types.EnvMeta
+	// DisallowLocalSources rejects `file:` and `env:` secrets when the app is re-rendered, servers must set it
+	DisallowLocalSources bool
 }
 
 // ListApplications lists all applications
@@ -158,10 +161,11 @@ func RetrieveApplicationStatusByName(ctx context.Context, c client.Client, appli
 		}
 
 		applicationMeta.Components = append(applicationMeta.Components, apis.ComponentMeta{
-			Name:     componentName,
-			Status:   status,
-			Workload: component.Spec.Workload,
-			Traits:   com.Traits,
+			Name:            componentName,
+			Status:          status,
+			Workload:        component.Spec.Workload,
+			ResourceVersion: component.ResourceVersion,
+			Traits:          com.Traits,
 		})
 		applicationMeta.Status = status
 
@@ -169,12 +173,31 @@ func RetrieveApplicationStatusByName(ctx context.Context, c client.Client, appli
 	return applicationMeta, nil
 }
 
+// UpdateApplication will deploy the application to cluster and save its appfile, it keeps the create time if the app already exists
+func UpdateApplication(ctx context.Context, c client.Client, env *types.EnvMeta, app *application.Application, io cmdutil.IOStreams) (apis.ApplicationMeta, error) {
+	old, err := application.Load(env.Name, app.Name)
+	if err != nil {
+		return apis.ApplicationMeta{}, err
+	}
+	app.CreateTime = old.CreateTime
+	if err := app.BuildRun(ctx, c, env, io); err != nil {
+		return apis.ApplicationMeta{}, err
+	}
+	if err := app.Save(env.Name); err != nil {
+		return apis.ApplicationMeta{}, err
+	}
+	return RetrieveApplicationStatusByName(ctx, c, app.Name, env.Namespace)
+}
+
 // DeleteApp will delete app including server side
 func (o *DeleteOptions) DeleteApp() (string, error) {
 	if err := application.Delete(o.Env.Name, o.AppName); err != nil && !os.IsNotExist(err) {
 		return "", err
 	}
 	ctx := context.Background()
+	if err := application.DeleteRevisions(ctx, o.Client, o.Env.Namespace, o.AppName); err != nil {
+		return "", err
+	}
 	var appConfig corev1alpha2.ApplicationConfiguration
 	err := o.Client.Get(ctx, client.ObjectKey{Name: o.AppName, Namespace: o.Env.Namespace}, &appConfig)
 	if err != nil {
@@ -204,6 +227,11 @@ func (o *DeleteOptions) DeleteApp() (string, error) {
 	if err != nil && !apierrors.IsNotFound(err) {
 		return "", fmt.Errorf("delete health scope %s err %w", healthScope.Name, err)
 	}
+	err = o.Client.DeleteAllOf(ctx, &corev1.Secret{}, client.InNamespace(o.Env.Namespace),
+		application.AppLabelSelector(o.AppName, appfile.LabelAppfileSecret))
+	if err != nil {
+		return "", fmt.Errorf("delete secrets of app %s err %w", o.AppName, err)
+	}
 
 	return fmt.Sprintf("delete apps succeed %s from %s", o.AppName, o.Env.Name), nil
 }
@@ -221,6 +249,9 @@ func (o *DeleteOptions) DeleteComponent(io cmdutil.IOStreams) (string, error) {
 		return "", err
 	}
 
+	if o.DisallowLocalSources {
+		app.DisallowLocalSources()
+	}
 	if len(app.GetComponents()) <= 1 {
 		return o.DeleteApp()
 	}
@@ -146,7 +146,27 @@ func InstallCapability(client client.Client, mapper discoverymapper.DiscoveryMap
 			return err
 		}
 	case types.TypeScope:
-		// TODO(wonderflow): support install scope here
+		var sd v1alpha2.ScopeDefinition
+		scopeData, err := ioutil.ReadFile(filepath.Clean(filepath.Join(repoDir, tp.CrdName+".yaml")))
+		if err != nil {
+			return nil
+		}
+		if err = yaml.Unmarshal(scopeData, &sd); err != nil {
+			return err
+		}
+		sd.Namespace = types.DefaultKubeVelaNS
+		ioStreams.Info("Installing scope capability " + sd.Name)
+		gvk, err := util.GetGVKFromDefinition(mapper, sd.Spec.Reference)
+		if err != nil {
+			return err
+		}
+		tp.CrdInfo = &types.CRDInfo{
+			APIVersion: gvk.GroupVersion().String(),
+			Kind:       gvk.Kind,
+		}
+		if err = client.Create(context.Background(), &sd); err != nil && !apierrors.IsAlreadyExists(err) {
+			return err
+		}
 	}
 
 	success := plugins.SinkTemp2Local([]types.Capability{tp}, defDir)
@@ -261,7 +281,7 @@ func uninstallCap(client client.Client, cap types.Capability, ioStreams cmdutil.
 	case types.TypeWorkload:
 		obj = &v1alpha2.WorkloadDefinition{ObjectMeta: v1.ObjectMeta{Name: cap.Name, Namespace: types.DefaultKubeVelaNS}}
 	case types.TypeScope:
-		return fmt.Errorf("uninstall scope capability was not supported yet")
+		obj = &v1alpha2.ScopeDefinition{ObjectMeta: v1.ObjectMeta{Name: cap.Name, Namespace: types.DefaultKubeVelaNS}}
 	}
 	if err := client.Delete(ctx, obj); err != nil {
 		return err
@@ -285,7 +305,7 @@ func uninstallCap(client client.Client, cap types.Capability, ioStreams cmdutil.
 	case types.TypeWorkload:
 		return os.Remove(filepath.Join(capdir, "workloads", cap.Name))
 	case types.TypeScope:
-		// TODO(wonderflow): add scope remove here.
+		return os.Remove(filepath.Join(capdir, "scopes", cap.Name))
 	}
 	ioStreams.Infof("%s removed successfully", cap.Name)
 	return nil
@@ -2,10 +2,18 @@ package oam
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sort"
 
-	"github.com/oam-dev/kubevela/pkg/server/apis"
-
+	"github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/application"
+	cmdutil "github.com/oam-dev/kubevela/pkg/commands/util"
+	"github.com/oam-dev/kubevela/pkg/server/apis"
 )
 
 // RetrieveComponent will get component status
@@ -24,3 +32,121 @@ func RetrieveComponent(ctx context.Context, c client.Client, applicationName, co
 	}
 	return componentMeta, nil
 }
+
+// ComponentErrorReason tells why updating a component failed
+type ComponentErrorReason string
+
+// Reasons of ComponentError, the API server maps them to HTTP status codes
+const (
+	// ComponentNotFound means the app or the component doesn't exist
+	ComponentNotFound ComponentErrorReason = "NotFound"
+	// ComponentInvalid means the update of the component is invalid
+	ComponentInvalid ComponentErrorReason = "Invalid"
+	// ComponentConflict means the component has been modified since the version of the update
+	ComponentConflict ComponentErrorReason = "Conflict"
+)
+
+// ComponentError is an error of updating a component caused by the request, so it's told from server failures
+type ComponentError struct {
+	Reason ComponentErrorReason
+	Err    error
+}
+
+func (e *ComponentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ComponentError) Unwrap() error {
+	return e.Err
+}
+
+// GetComponentErrorReason returns the reason of err if it's a ComponentError, otherwise it's empty
+func GetComponentErrorReason(err error) ComponentErrorReason {
+	var e *ComponentError
+	if errors.As(err, &e) {
+		return e.Reason
+	}
+	return ""
+}
+
+// UpdateComponent will patch workload fields and traits of one service in the appfile and deploy it.
+// If ResourceVersion is set in body, the update is rejected when the component in cluster has a different one.
+func UpdateComponent(ctx context.Context, c client.Client, env *types.EnvMeta, appName, compName string,
+	body apis.ComponentBody, io cmdutil.IOStreams) (apis.ComponentMeta, error) {
+	app, err := application.Load(env.Name, appName)
+	if err != nil {
+		return apis.ComponentMeta{}, err
+	}
+	if app.Name == "" {
+		return apis.ComponentMeta{}, &ComponentError{Reason: ComponentNotFound,
+			Err: fmt.Errorf("app %s not found in env %s", appName, env.Name)}
+	}
+	// it's only called by the API server, which can't read files or env vars of its own into secrets
+	app.DisallowLocalSources()
+	workloadType, _ := app.GetWorkload(compName)
+	if workloadType == "" {
+		return apis.ComponentMeta{}, &ComponentError{Reason: ComponentNotFound,
+			Err: fmt.Errorf("service %s not found in app %s", compName, appName)}
+	}
+	if err := app.SetWorkload(compName, workloadType, body.Workload); err != nil {
+		return apis.ComponentMeta{}, &ComponentError{Reason: ComponentInvalid, Err: err}
+	}
+	for traitType, traitData := range body.Traits {
+		if err := app.SetTrait(compName, traitType, traitData); err != nil {
+			return apis.ComponentMeta{}, &ComponentError{Reason: ComponentInvalid, Err: err}
+		}
+	}
+
+	// check the version before writing anything so that a conflict doesn't leave the app partially applied
+	if body.ResourceVersion != "" {
+		var current v1alpha2.Component
+		if err := c.Get(ctx, client.ObjectKey{Namespace: env.Namespace, Name: compName}, &current); err != nil &&
+			!apierrors.IsNotFound(err) {
+			return apis.ComponentMeta{}, err
+		}
+		if current.ResourceVersion != body.ResourceVersion {
+			return apis.ComponentMeta{}, errComponentModified(compName)
+		}
+	}
+
+	// the app rendered before the update, so failing to render it is caused by the update
+	comps, appConfig, scopes, err := app.OAM(env, io, true)
+	if err != nil {
+		return apis.ComponentMeta{}, &ComponentError{Reason: ComponentInvalid, Err: err}
+	}
+	// the target component is written first, the apiserver still rejects it if it's changed after the check above
+	sort.SliceStable(comps, func(i, j int) bool { return comps[i].Name == compName && comps[j].Name != compName })
+	for _, comp := range comps {
+		if comp.Name != compName || body.ResourceVersion == "" {
+			if err := application.CreateOrUpdateComponent(ctx, c, comp); err != nil {
+				return apis.ComponentMeta{}, err
+			}
+			continue
+		}
+		comp.ResourceVersion = body.ResourceVersion
+		if err := c.Update(ctx, comp); err != nil {
+			if apierrors.IsConflict(err) {
+				return apis.ComponentMeta{}, errComponentModified(compName)
+			}
+			return apis.ComponentMeta{}, err
+		}
+	}
+	if err := application.CreateScopes(ctx, c, scopes); err != nil {
+		return apis.ComponentMeta{}, err
+	}
+	if err := application.CreateOrUpdateAppConfig(ctx, c, appConfig); err != nil {
+		return apis.ComponentMeta{}, err
+	}
+	if err := application.DeleteStaleSecrets(ctx, c, appConfig, scopes); err != nil {
+		return apis.ComponentMeta{}, err
+	}
+	if err := app.Save(env.Name); err != nil {
+		return apis.ComponentMeta{}, err
+	}
+	return RetrieveComponent(ctx, c, appName, compName, env.Namespace)
+}
+
+func errComponentModified(compName string) error {
+	return &ComponentError{Reason: ComponentConflict,
+		Err: fmt.Errorf("component %s has been modified, please get the latest version and try again", compName)}
+}
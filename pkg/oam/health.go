@@ -0,0 +1,33 @@
+package oam
+
+import (
+	"context"
+
+	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	"github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/pkg/application"
+)
+
+// DefinitionChecker checks traits by the status policy of their definitions
+type DefinitionChecker struct {
+	c      client.Client
+	policy *application.StatusPolicy
+}
+
+// Check is done once the trait is healthy, the message is the evaluated message template of the definition
+func (d *DefinitionChecker) Check(ctx context.Context, reference runtimev1alpha1.TypedReference, _ string, appConfig *v1alpha2.ApplicationConfiguration, _ *application.Application) (CheckStatus, string, error) {
+	tr, err := GetUnstructured(ctx, d.c, appConfig.Namespace, reference)
+	if err != nil {
+		return StatusChecking, "", err
+	}
+	healthy, message, err := d.policy.Eval(tr)
+	if err != nil {
+		return StatusChecking, "", err
+	}
+	if !healthy {
+		return StatusChecking, message, nil
+	}
+	return StatusDone, message, nil
+}
@@ -0,0 +1,126 @@
+package oam
+
+import (
+	"context"
+	"testing"
+
+	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	"github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/apis/standard.oam.dev/v1alpha1"
+	"github.com/oam-dev/kubevela/pkg/application"
+	"github.com/oam-dev/kubevela/pkg/server/apis"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+func TestDefinitionStatus(t *testing.T) {
+	ctx := context.Background()
+	c := fake.NewFakeClientWithScheme(common.Scheme,
+		&v1alpha2.WorkloadDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: "webservice"},
+			Spec: v1alpha2.WorkloadDefinitionSpec{
+				Reference: v1alpha2.DefinitionReference{Name: "deployments.apps"},
+				Extension: &runtime.RawExtension{Raw: []byte(`{
+"health": "context.output.status.readyReplicas == context.output.spec.replicas",
+"message": "\"Ready: \\(context.output.status.readyReplicas)/\\(context.output.spec.replicas)\""}`)},
+			},
+		},
+		&v1alpha2.TraitDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: "metrics"},
+			Spec: v1alpha2.TraitDefinitionSpec{
+				Reference: v1alpha2.DefinitionReference{Name: "metricstraits.standard.oam.dev"},
+				Extension: &runtime.RawExtension{Raw: []byte(`{
+"health": "len(context.output.status.conditions) > 0 && context.output.status.conditions[0].status == \"True\"",
+"message": "\"Monitoring path: \\(context.output.spec.scrapeService.path)\""}`)},
+			},
+		},
+		&v1alpha2.TraitDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: "scaler"},
+			Spec: v1alpha2.TraitDefinitionSpec{
+				Reference: v1alpha2.DefinitionReference{Name: "manualscalertraits.core.oam.dev"},
+				Extension: &runtime.RawExtension{Raw: []byte(`{"template": "output: {}"}`)},
+			},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default",
+				Labels: map[string]string{"workload.oam.dev/type": "webservice"}},
+			Spec:   appsv1.DeploymentSpec{Replicas: pointer.Int32Ptr(2)},
+			Status: appsv1.DeploymentStatus{ReadyReplicas: 1},
+		},
+		&v1alpha1.MetricsTrait{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-metrics", Namespace: "default",
+				Labels: map[string]string{"trait.oam.dev/type": "metrics"}},
+			Spec: v1alpha1.MetricsTraitSpec{ScrapeService: v1alpha1.ScapeServiceEndPoint{Path: "/metrics"}},
+			Status: v1alpha1.MetricsTraitStatus{ConditionedStatus: runtimev1alpha1.ConditionedStatus{
+				Conditions: []runtimev1alpha1.Condition{{Type: runtimev1alpha1.TypeSynced, Status: corev1.ConditionTrue}},
+			}},
+		},
+	)
+
+	policy, err := application.GetStatusPolicy(ctx, c, "trait", "scaler")
+	assert.NoError(t, err)
+	assert.Nil(t, policy)
+	policy, err = application.GetStatusPolicy(ctx, c, "workload", "unknown")
+	assert.NoError(t, err)
+	assert.Nil(t, policy)
+	metricsRef := runtimev1alpha1.TypedReference{APIVersion: "standard.oam.dev/v1alpha1", Kind: "MetricsTrait", Name: "web-metrics"}
+	routeRef := runtimev1alpha1.TypedReference{APIVersion: "standard.oam.dev/v1alpha1", Kind: "Route", Name: "web-route"}
+	scalerRef := runtimev1alpha1.TypedReference{APIVersion: "core.oam.dev/v1alpha2", Kind: "ManualScalerTrait", Name: "web-scaler"}
+	assert.IsType(t, &DefinitionChecker{}, GetChecker(ctx, "metrics", metricsRef, c))
+	// built-in checkers are found by kind when definitions have no status policy, whatever their names are
+	assert.IsType(t, &MetricChecker{}, GetChecker(ctx, "monitoring", metricsRef, c))
+	assert.IsType(t, &RouteChecker{}, GetChecker(ctx, "route", routeRef, c))
+	assert.IsType(t, &RouteChecker{}, GetChecker(ctx, "ingress", routeRef, c))
+	assert.IsType(t, &DefaultChecker{}, GetChecker(ctx, "scaler", scalerRef, c))
+
+	appConfig := &v1alpha2.ApplicationConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "myapp", Namespace: "default"},
+		Status: v1alpha2.ApplicationConfigurationStatus{Workloads: []v1alpha2.WorkloadStatus{{
+			ComponentName: "web",
+			Reference:     runtimev1alpha1.TypedReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "web"},
+			Traits: []v1alpha2.WorkloadTrait{{Reference: runtimev1alpha1.TypedReference{
+				APIVersion: "standard.oam.dev/v1alpha1", Kind: "MetricsTrait", Name: "web-metrics"}}},
+		}}},
+	}
+	status := buildApplicationStatus(ctx, c, &application.Application{}, appConfig)
+	assert.Equal(t, 1, len(status.Components))
+	comp := status.Components[0]
+	assert.Equal(t, string(v1alpha2.StatusUnhealthy), comp.HealthStatus)
+	assert.Equal(t, "Ready: 1/2", comp.Diagnosis)
+	assert.Equal(t, []apis.TraitStatus{{Component: "web", Type: "metrics", Name: "web-metrics", Status: StatusDone,
+		Message: "Monitoring path: /metrics"}}, comp.Traits)
+}
+
+func TestMetricChecker(t *testing.T) {
+	ctx := context.Background()
+	metrics := &v1alpha1.MetricsTrait{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-metrics", Namespace: "default"},
+		Spec:       v1alpha1.MetricsTraitSpec{ScrapeService: v1alpha1.ScapeServiceEndPoint{Path: "/metrics", Enabled: pointer.BoolPtr(false)}},
+		Status: v1alpha1.MetricsTraitStatus{ConditionedStatus: runtimev1alpha1.ConditionedStatus{
+			Conditions: []runtimev1alpha1.Condition{{Type: runtimev1alpha1.TypeSynced, Status: corev1.ConditionFalse, Message: "service not found"}},
+		}},
+	}
+	c := fake.NewFakeClientWithScheme(common.Scheme, metrics)
+	ref := runtimev1alpha1.TypedReference{APIVersion: "standard.oam.dev/v1alpha1", Kind: "MetricsTrait", Name: "web-metrics"}
+	appConfig := &v1alpha2.ApplicationConfiguration{ObjectMeta: metav1.ObjectMeta{Name: "myapp", Namespace: "default"}}
+	checker := GetChecker(ctx, "metrics", ref, c)
+
+	check, message, err := checker.Check(ctx, ref, "web", appConfig, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, CheckStatus(StatusChecking), check)
+	assert.Equal(t, "service not found", message)
+
+	metrics.Status.Conditions[0].Status = corev1.ConditionTrue
+	assert.NoError(t, c.Update(ctx, metrics))
+	check, message, err = checker.Check(ctx, ref, "web", appConfig, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, CheckStatus(StatusDone), check)
+	assert.Equal(t, "Monitoring disabled", message)
+}
@@ -0,0 +1,171 @@
+package oam
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/plugins"
+	"github.com/oam-dev/kubevela/pkg/server/apis"
+)
+
+// CreateScope will create a scope object of the installed scope capability in the namespace
+func CreateScope(ctx context.Context, c client.Client, namespace string, body apis.ScopeBody) (string, error) {
+	scopeCap, err := plugins.GetInstalledCapabilityWithCapName(types.TypeScope, body.Type)
+	if err != nil {
+		return "", err
+	}
+	obj, err := newScopeObject(scopeCap, body.Name, namespace, body.Spec)
+	if err != nil {
+		return "", err
+	}
+	if err = c.Create(ctx, obj); err != nil {
+		if apierrors.IsAlreadyExists(err) {
+			return "", fmt.Errorf("scope %s already exists in namespace %s", body.Name, namespace)
+		}
+		return "", err
+	}
+	return fmt.Sprintf("scope %s created", body.Name), nil
+}
+
+// ListScopes will list scope objects of all installed scope capabilities in the namespace
+func ListScopes(ctx context.Context, c client.Reader, namespace string) ([]apis.ScopeMeta, error) {
+	scopeCaps, err := plugins.LoadInstalledCapabilityWithType(types.TypeScope)
+	if err != nil {
+		return nil, err
+	}
+	var scopeList []apis.ScopeMeta
+	for _, scopeCap := range scopeCaps {
+		if scopeCap.CrdInfo == nil {
+			continue
+		}
+		var objs unstructured.UnstructuredList
+		objs.SetGroupVersionKind(scopeGVK(scopeCap).GroupVersion().WithKind(scopeCap.CrdInfo.Kind + "List"))
+		if err := c.List(ctx, &objs, client.InNamespace(namespace)); err != nil {
+			return nil, fmt.Errorf("list scope %s err: %w", scopeCap.Name, err)
+		}
+		for i := range objs.Items {
+			scopeList = append(scopeList, toScopeMeta(scopeCap.Name, &objs.Items[i]))
+		}
+	}
+	return scopeList, nil
+}
+
+// GetScope will get a scope object by name from the namespace
+func GetScope(ctx context.Context, c client.Reader, namespace, name string) (apis.ScopeMeta, error) {
+	scopeType, obj, err := findScope(ctx, c, namespace, name)
+	if err != nil {
+		return apis.ScopeMeta{}, err
+	}
+	return toScopeMeta(scopeType, obj), nil
+}
+
+// UpdateScope will replace the spec of an existing scope object, the workload references managed by OAM runtime are retained
+func UpdateScope(ctx context.Context, c client.Client, namespace, name string, spec map[string]interface{}) (string, error) {
+	scopeType, obj, err := findScope(ctx, c, namespace, name)
+	if err != nil {
+		return "", err
+	}
+	scopeCap, err := plugins.GetInstalledCapabilityWithCapName(types.TypeScope, scopeType)
+	if err != nil {
+		return "", err
+	}
+	newSpec := make(map[string]interface{}, len(spec))
+	for k, v := range spec {
+		newSpec[k] = v
+	}
+	// workload references are managed by OAM runtime, keep them as they are
+	if refsPath := strings.Split(scopeCap.WorkloadRefsPath, "."); len(refsPath) > 1 && refsPath[0] == "spec" {
+		if refs, found, _ := unstructured.NestedFieldCopy(obj.Object, refsPath...); found {
+			if err = unstructured.SetNestedField(newSpec, refs, refsPath[1:]...); err != nil {
+				return "", err
+			}
+		}
+	}
+	if err = unstructured.SetNestedField(obj.Object, newSpec, "spec"); err != nil {
+		return "", err
+	}
+	if err = c.Update(ctx, obj); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("scope %s updated", name), nil
+}
+
+// DeleteScope will delete a scope object by name from the namespace
+func DeleteScope(ctx context.Context, c client.Client, namespace, name string) (string, error) {
+	_, obj, err := findScope(ctx, c, namespace, name)
+	if err != nil {
+		return "", err
+	}
+	if err = c.Delete(ctx, obj); err != nil && !apierrors.IsNotFound(err) {
+		return "", err
+	}
+	return fmt.Sprintf("scope %s deleted", name), nil
+}
+
+func findScope(ctx context.Context, c client.Reader, namespace, name string) (string, *unstructured.Unstructured, error) {
+	scopeCaps, err := plugins.LoadInstalledCapabilityWithType(types.TypeScope)
+	if err != nil {
+		return "", nil, err
+	}
+	for _, scopeCap := range scopeCaps {
+		if scopeCap.CrdInfo == nil {
+			continue
+		}
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(scopeGVK(scopeCap))
+		err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, obj)
+		if err == nil {
+			return scopeCap.Name, obj, nil
+		}
+		if !apierrors.IsNotFound(err) {
+			return "", nil, err
+		}
+	}
+	return "", nil, fmt.Errorf("scope %s not found in namespace %s", name, namespace)
+}
+
+func newScopeObject(scopeCap types.Capability, name, namespace string, spec map[string]interface{}) (*unstructured.Unstructured, error) {
+	if scopeCap.CrdInfo == nil {
+		return nil, fmt.Errorf("scope %s was not ready, its CRD info is missing", scopeCap.Name)
+	}
+	if spec == nil {
+		spec = make(map[string]interface{})
+	}
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	obj.SetGroupVersionKind(scopeGVK(scopeCap))
+	obj.SetName(name)
+	obj.SetNamespace(namespace)
+	// workload references will be filled by OAM runtime, but the field may be required by the CRD
+	if scopeCap.WorkloadRefsPath != "" {
+		path := strings.Split(scopeCap.WorkloadRefsPath, ".")
+		if _, found, _ := unstructured.NestedFieldNoCopy(obj.Object, path...); !found {
+			if err := unstructured.SetNestedSlice(obj.Object, []interface{}{}, path...); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return obj, nil
+}
+
+func scopeGVK(scopeCap types.Capability) schema.GroupVersionKind {
+	return schema.FromAPIVersionAndKind(scopeCap.CrdInfo.APIVersion, scopeCap.CrdInfo.Kind)
+}
+
+func toScopeMeta(scopeType string, obj *unstructured.Unstructured) apis.ScopeMeta {
+	spec, _, _ := unstructured.NestedMap(obj.Object, "spec")
+	status, _, _ := unstructured.NestedMap(obj.Object, "status")
+	return apis.ScopeMeta{
+		Name:        obj.GetName(),
+		Type:        scopeType,
+		Spec:        spec,
+		Status:      status,
+		CreatedTime: obj.GetCreationTimestamp().String(),
+	}
+}
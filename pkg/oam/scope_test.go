@@ -0,0 +1,39 @@
+package oam
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/oam-dev/kubevela/apis/types"
+)
+
+func TestNewScopeObject(t *testing.T) {
+	healthScope := types.Capability{
+		Name:             "healthscopes.core.oam.dev",
+		Type:             types.TypeScope,
+		WorkloadRefsPath: "spec.workloadRefs",
+		CrdInfo:          &types.CRDInfo{APIVersion: "core.oam.dev/v1alpha2", Kind: "HealthScope"},
+	}
+
+	obj, err := newScopeObject(healthScope, "my-health", "default", map[string]interface{}{"probe-timeout": int64(5)})
+	assert.NoError(t, err)
+	assert.Equal(t, "HealthScope", obj.GetKind())
+	assert.Equal(t, "core.oam.dev/v1alpha2", obj.GetAPIVersion())
+	assert.Equal(t, "my-health", obj.GetName())
+	assert.Equal(t, "default", obj.GetNamespace())
+	refs, found, err := unstructured.NestedSlice(obj.Object, "spec", "workloadRefs")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Empty(t, refs)
+
+	meta := toScopeMeta(healthScope.Name, obj)
+	assert.Equal(t, "my-health", meta.Name)
+	assert.Equal(t, healthScope.Name, meta.Type)
+	assert.Equal(t, int64(5), meta.Spec["probe-timeout"])
+
+	healthScope.CrdInfo = nil
+	_, err = newScopeObject(healthScope, "my-health", "default", nil)
+	assert.Error(t, err)
+}
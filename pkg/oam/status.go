@@ -0,0 +1,127 @@
+package oam
+
+import (
+	"context"
+	"fmt"
+
+	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	"github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/pkg/application"
+	"github.com/oam-dev/kubevela/pkg/server/apis"
+)
+
+// GetApplicationStatus gets the status snapshot of an application, including health of each component and
+// check results of its traits
+func GetApplicationStatus(ctx context.Context, c client.Client, app *application.Application, namespace string) (apis.ApplicationStatus, error) {
+	var appConfig v1alpha2.ApplicationConfiguration
+	if err := c.Get(ctx, client.ObjectKey{Name: app.Name, Namespace: namespace}, &appConfig); err != nil {
+		return apis.ApplicationStatus{}, err
+	}
+	return buildApplicationStatus(ctx, c, app, &appConfig), nil
+}
+
+func buildApplicationStatus(ctx context.Context, c client.Client, app *application.Application,
+	appConfig *v1alpha2.ApplicationConfiguration) apis.ApplicationStatus {
+	status := apis.ApplicationStatus{Name: appConfig.Name, Status: "Unknown"}
+	if len(appConfig.Status.Conditions) != 0 {
+		status.Status = string(appConfig.Status.Conditions[0].Status)
+		status.Message = appConfig.Status.Conditions[0].Message
+	}
+	for _, wl := range appConfig.Status.Workloads {
+		comp := getComponentHealth(ctx, c, appConfig, wl)
+		for _, tr := range wl.Traits {
+			comp.Traits = append(comp.Traits, checkTrait(ctx, c, wl.ComponentName, tr.Reference, appConfig, app))
+		}
+		status.Components = append(status.Components, comp)
+	}
+	return status
+}
+
+func getComponentHealth(ctx context.Context, c client.Client, appConfig *v1alpha2.ApplicationConfiguration, wl v1alpha2.WorkloadStatus) apis.ComponentStatus {
+	comp := apis.ComponentStatus{Name: wl.ComponentName}
+	workload, err := GetUnstructured(ctx, c, appConfig.Namespace, wl.Reference)
+	if err != nil {
+		comp.WorkloadStatus = err.Error()
+	} else {
+		if comp.WorkloadStatus, err = GetStatusFromObject(workload); err != nil {
+			comp.WorkloadStatus = err.Error()
+		}
+		comp.Replicas = getReplicas(workload)
+	}
+	// the same evaluation as dependencies and Applications, the status policy of the workload definition or the HealthScope
+	health, diagnosis, err := application.ComponentHealth(ctx, c, appConfig, wl.ComponentName)
+	if err != nil {
+		comp.Diagnosis = err.Error()
+		return comp
+	}
+	comp.HealthStatus = string(health)
+	comp.Diagnosis = diagnosis
+	return comp
+}
+
+// getReplicas describes ready replicas against desired replicas of workloads like Deployments, it's empty if the
+// workload has no replicas
+func getReplicas(workload *unstructured.Unstructured) string {
+	desired, found, err := unstructured.NestedInt64(workload.Object, "spec", "replicas")
+	if err != nil || !found {
+		return ""
+	}
+	ready, _, _ := unstructured.NestedInt64(workload.Object, "status", "readyReplicas")
+	return fmt.Sprintf("%d/%d", ready, desired)
+}
+
+func checkTrait(ctx context.Context, c client.Client, compName string, ref runtimev1alpha1.TypedReference,
+	appConfig *v1alpha2.ApplicationConfiguration, app *application.Application) apis.TraitStatus {
+	status := apis.TraitStatus{Component: compName, Name: ref.Name, Status: StatusChecking}
+	tr, err := GetUnstructured(ctx, c, appConfig.Namespace, ref)
+	if err != nil {
+		status.Message = err.Error()
+		return status
+	}
+	status.Type = tr.GetLabels()[oam.TraitTypeLabel]
+	check, message, err := GetChecker(ctx, status.Type, ref, c).Check(ctx, ref, compName, appConfig, app)
+	if err != nil {
+		status.Message = err.Error()
+		return status
+	}
+	status.Status = string(check)
+	status.Message = message
+	return status
+}
+
+// DiffApplicationStatus returns events for what changed from old to cur, every part of cur is reported if old is nil
+func DiffApplicationStatus(old *apis.ApplicationStatus, cur apis.ApplicationStatus) []apis.AppEvent {
+	var events []apis.AppEvent
+	if old == nil || old.Status != cur.Status || old.Message != cur.Message {
+		events = append(events, apis.AppEvent{Type: apis.AppEventStatus,
+			Data: apis.ApplicationStatus{Name: cur.Name, Status: cur.Status, Message: cur.Message}})
+	}
+	oldComps := make(map[string]apis.ComponentStatus)
+	oldTraits := make(map[string]apis.TraitStatus)
+	if old != nil {
+		for _, comp := range old.Components {
+			oldComps[comp.Name] = comp
+			for _, tr := range comp.Traits {
+				oldTraits[comp.Name+"/"+tr.Name] = tr
+			}
+		}
+	}
+	for _, comp := range cur.Components {
+		health := comp
+		health.Traits = nil
+		if o, ok := oldComps[comp.Name]; !ok || o.WorkloadStatus != comp.WorkloadStatus ||
+			o.HealthStatus != comp.HealthStatus || o.Diagnosis != comp.Diagnosis || o.Replicas != comp.Replicas {
+			events = append(events, apis.AppEvent{Type: apis.AppEventHealth, Data: health})
+		}
+		for _, tr := range comp.Traits {
+			if o, ok := oldTraits[comp.Name+"/"+tr.Name]; !ok || o != tr {
+				events = append(events, apis.AppEvent{Type: apis.AppEventTrait, Data: tr})
+			}
+		}
+	}
+	return events
+}
@@ -0,0 +1,88 @@
+package oam
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/oam-dev/kubevela/pkg/server/apis"
+)
+
+func TestDiffApplicationStatus(t *testing.T) {
+	old := apis.ApplicationStatus{
+		Name:   "myapp",
+		Status: "True",
+		Components: []apis.ComponentStatus{{
+			Name:         "web",
+			HealthStatus: "HEALTHY",
+			Traits:       []apis.TraitStatus{{Component: "web", Type: "route", Name: "web-route", Status: StatusChecking}},
+		}},
+	}
+	cases := map[string]struct {
+		old    *apis.ApplicationStatus
+		cur    apis.ApplicationStatus
+		expect []apis.AppEvent
+	}{
+		"report everything at first": {
+			old: nil,
+			cur: old,
+			expect: []apis.AppEvent{
+				{Type: apis.AppEventStatus, Data: apis.ApplicationStatus{Name: "myapp", Status: "True"}},
+				{Type: apis.AppEventHealth, Data: apis.ComponentStatus{Name: "web", HealthStatus: "HEALTHY"}},
+				{Type: apis.AppEventTrait, Data: apis.TraitStatus{Component: "web", Type: "route", Name: "web-route", Status: StatusChecking}},
+			},
+		},
+		"nothing changed": {
+			old: &old,
+			cur: old,
+		},
+		"health and trait changed": {
+			old: &old,
+			cur: apis.ApplicationStatus{
+				Name:   "myapp",
+				Status: "True",
+				Components: []apis.ComponentStatus{{
+					Name:         "web",
+					HealthStatus: "UNHEALTHY",
+					Diagnosis:    "pod crashed",
+					Traits:       []apis.TraitStatus{{Component: "web", Type: "route", Name: "web-route", Status: StatusDone, Message: "Visiting URL: https://web.example.com"}},
+				}},
+			},
+			expect: []apis.AppEvent{
+				{Type: apis.AppEventHealth, Data: apis.ComponentStatus{Name: "web", HealthStatus: "UNHEALTHY", Diagnosis: "pod crashed"}},
+				{Type: apis.AppEventTrait, Data: apis.TraitStatus{Component: "web", Type: "route", Name: "web-route", Status: StatusDone, Message: "Visiting URL: https://web.example.com"}},
+			},
+		},
+		"replicas changed": {
+			old: &old,
+			cur: apis.ApplicationStatus{
+				Name:   "myapp",
+				Status: "True",
+				Components: []apis.ComponentStatus{{
+					Name:         "web",
+					HealthStatus: "HEALTHY",
+					Replicas:     "1/2",
+					Traits:       []apis.TraitStatus{{Component: "web", Type: "route", Name: "web-route", Status: StatusChecking}},
+				}},
+			},
+			expect: []apis.AppEvent{
+				{Type: apis.AppEventHealth, Data: apis.ComponentStatus{Name: "web", HealthStatus: "HEALTHY", Replicas: "1/2"}},
+			},
+		},
+	}
+	for name, c := range cases {
+		assert.Equal(t, c.expect, DiffApplicationStatus(c.old, c.cur), name)
+	}
+}
+
+func TestGetReplicas(t *testing.T) {
+	deploy := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec":   map[string]interface{}{"replicas": int64(3)},
+		"status": map[string]interface{}{"readyReplicas": int64(2)},
+	}}
+	assert.Equal(t, "2/3", getReplicas(deploy))
+	unstructured.RemoveNestedField(deploy.Object, "status")
+	assert.Equal(t, "0/3", getReplicas(deploy))
+	assert.Equal(t, "", getReplicas(&unstructured.Unstructured{Object: map[string]interface{}{}}))
+}
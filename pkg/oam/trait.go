@@ -118,26 +118,28 @@ func Parse(applyTo string) string {
 func ValidateAndMutateForCore(traitType, workloadName string, flags *pflag.FlagSet, env *types.EnvMeta) error {
 	switch traitType {
 	case "route":
+		// the host is generated from the domain of env when the route is rendered if it's not specified
 		domain, _ := flags.GetString("domain")
-		if domain == "" {
-			if env.Domain == "" {
-				return fmt.Errorf("--domain is required if not contain in environment")
-			}
-			if strings.HasPrefix(env.Domain, "https://") {
-				env.Domain = strings.TrimPrefix(env.Domain, "https://")
-			}
-			if strings.HasPrefix(env.Domain, "http://") {
-				env.Domain = strings.TrimPrefix(env.Domain, "http://")
-			}
-			if err := flags.Set("domain", workloadName+"."+env.Domain); err != nil {
-				return fmt.Errorf("set flag for vela-core trait('route') err %w, please make sure your template is right", err)
-			}
+		if domain == "" && env.Domain == "" {
+			return fmt.Errorf("--domain is required if not contain in environment")
 		}
 		issuer, _ := flags.GetString("issuer")
 		if issuer == "" && env.Issuer != "" {
 			if err := flags.Set("issuer", env.Issuer); err != nil {
 				return fmt.Errorf("set flag for vela-core trait('route') err %w, please make sure your template is right", err)
 			}
+			// route templates installed by older versions don't have issuerType and provider
+			if env.IssuerType != "" && flags.Lookup("issuerType") != nil {
+				if err := flags.Set("issuerType", env.IssuerType); err != nil {
+					return fmt.Errorf("set flag for vela-core trait('route') err %w, please make sure your template is right", err)
+				}
+			}
+		}
+		provider, _ := flags.GetString("provider")
+		if provider == "" && env.RouteProvider != "" && flags.Lookup("provider") != nil {
+			if err := flags.Set("provider", env.RouteProvider); err != nil {
+				return fmt.Errorf("set flag for vela-core trait('route') err %w, please make sure your template is right", err)
+			}
 		}
 	default:
 		// extend other trait here in the future
@@ -12,9 +12,11 @@ import (
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/api/networking/v1beta1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/oam-dev/kubevela/apis/standard.oam.dev/v1alpha1"
+	"github.com/oam-dev/kubevela/apis/types"
 	"github.com/oam-dev/kubevela/pkg/application"
 	autoscalers "github.com/oam-dev/kubevela/pkg/controller/standard.oam.dev/v1alpha1/autoscaler"
 )
@@ -29,17 +31,29 @@ const (
 	StatusDone = "done"
 )
 
-// GetChecker will get Trait checker for 'vela status'
-func GetChecker(traitType string, c client.Client) Checker {
-	switch traitType {
-	case "route":
+// builtinCheckers check traits implemented by KubeVela, they're found by the kind of the trait object rather than
+// the name of the trait definition, which platform builders may change
+var builtinCheckers = map[schema.GroupKind]func(c client.Client) Checker{
+	v1alpha1.SchemeGroupVersion.WithKind("Route").GroupKind(): func(c client.Client) Checker {
 		return &RouteChecker{c: c}
-	case "metrics":
+	},
+	v1alpha1.SchemeGroupVersion.WithKind("MetricsTrait").GroupKind(): func(c client.Client) Checker {
 		return &MetricChecker{c: c}
-	case "autoscale":
+	},
+	v1alpha1.SchemeGroupVersion.WithKind("Autoscaler").GroupKind(): func(c client.Client) Checker {
 		return &AutoscalerChecker{c: c}
-	}
+	},
+}
 
+// GetChecker will get Trait checker for 'vela status'. Traits whose definitions declare health or message are checked
+// by them, the built-in checkers of the trait kind are used for the other traits.
+func GetChecker(ctx context.Context, traitType string, reference runtimev1alpha1.TypedReference, c client.Client) Checker {
+	if policy, err := application.GetStatusPolicy(ctx, c, types.TypeTrait, traitType); err == nil && policy != nil {
+		return &DefinitionChecker{c: c, policy: policy}
+	}
+	if newChecker, ok := builtinCheckers[reference.GroupVersionKind().GroupKind()]; ok {
+		return newChecker(c)
+	}
 	return &DefaultChecker{c: c}
 }
 
@@ -0,0 +1,227 @@
+package oam
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam/discoverymapper"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/pkg/application"
+	"github.com/oam-dev/kubevela/pkg/server/apis"
+)
+
+// statusResyncPeriod makes status recomputed periodically, as trait checkers may look into objects which are not watched
+const statusResyncPeriod = 30 * time.Second
+
+var (
+	appConfigResource   = v1alpha2.SchemeGroupVersion.WithResource("applicationconfigurations")
+	healthScopeResource = v1alpha2.SchemeGroupVersion.WithResource("healthscopes")
+	eventResource       = corev1.SchemeGroupVersion.WithResource("events")
+)
+
+type appWatcher struct {
+	c         client.Client
+	dm        discoverymapper.DiscoveryMapper
+	app       *application.Application
+	namespace string
+	out       chan<- apis.AppEvent
+	changed   chan struct{}
+
+	// ownedFactory watches workloads and traits labeled with the app name, it grows with the kinds in AppConfig
+	ownedFactory dynamicinformer.DynamicSharedInformerFactory
+	watched      map[schema.GroupVersionResource]bool
+
+	mu sync.RWMutex
+	// objects are names of workloads and traits in the app, used to filter Kubernetes Events
+	objects []string
+}
+
+// WatchApplication pushes status changes and Kubernetes Events of the application into out until ctx is done.
+// Informers on the AppConfig, its workloads, traits, health scopes and Events trigger recomputing the status,
+// only the parts that changed are pushed, see DiffApplicationStatus.
+func WatchApplication(ctx context.Context, cfg *rest.Config, c client.Client, dm discoverymapper.DiscoveryMapper,
+	app *application.Application, namespace string, out chan<- apis.AppEvent) error {
+	dynClient, err := dynamic.NewForConfig(cfg)
+	if err != nil {
+		return err
+	}
+	w := &appWatcher{
+		c:         c,
+		dm:        dm,
+		app:       app,
+		namespace: namespace,
+		out:       out,
+		changed:   make(chan struct{}, 1),
+		watched:   make(map[schema.GroupVersionResource]bool),
+		ownedFactory: dynamicinformer.NewFilteredDynamicSharedInformerFactory(dynClient, 0, namespace,
+			func(o *metav1.ListOptions) {
+				o.LabelSelector = oam.LabelAppName + "=" + app.Name
+			}),
+	}
+	appFactory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(dynClient, statusResyncPeriod, namespace,
+		func(o *metav1.ListOptions) {
+			o.FieldSelector = "metadata.name=" + app.Name
+		})
+	nsFactory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(dynClient, 0, namespace, nil)
+
+	notify := cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(interface{}) { w.notify() },
+		UpdateFunc: func(interface{}, interface{}) { w.notify() },
+		DeleteFunc: func(interface{}) { w.notify() },
+	}
+	appFactory.ForResource(appConfigResource).Informer().AddEventHandler(notify)
+	nsFactory.ForResource(healthScopeResource).Informer().AddEventHandler(notify)
+	nsFactory.ForResource(eventResource).Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { w.handleKubeEvent(ctx, obj) },
+		UpdateFunc: func(_, obj interface{}) { w.handleKubeEvent(ctx, obj) },
+	})
+
+	appFactory.Start(ctx.Done())
+	appFactory.WaitForCacheSync(ctx.Done())
+
+	var last *apis.ApplicationStatus
+	var lastErr string
+	var nsStarted bool
+	w.notify()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-w.changed:
+		}
+		status, err := w.status(ctx)
+		// Events are filtered by objects of the app, so start watching them after objects are known
+		if !nsStarted {
+			nsFactory.Start(ctx.Done())
+			nsStarted = true
+		}
+		if err != nil {
+			// report the same error only once, e.g. when the app hasn't been deployed yet
+			if err.Error() != lastErr {
+				lastErr = err.Error()
+				w.send(ctx, apis.AppEvent{Type: apis.AppEventError, Data: lastErr})
+			}
+			continue
+		}
+		lastErr = ""
+		for _, e := range DiffApplicationStatus(last, status) {
+			w.send(ctx, e)
+		}
+		last = &status
+	}
+}
+
+func (w *appWatcher) notify() {
+	select {
+	case w.changed <- struct{}{}:
+	default:
+	}
+}
+
+func (w *appWatcher) send(ctx context.Context, e apis.AppEvent) {
+	select {
+	case w.out <- e:
+	case <-ctx.Done():
+	}
+}
+
+func (w *appWatcher) status(ctx context.Context) (apis.ApplicationStatus, error) {
+	var appConfig v1alpha2.ApplicationConfiguration
+	if err := w.c.Get(ctx, client.ObjectKey{Name: w.app.Name, Namespace: w.namespace}, &appConfig); err != nil {
+		if apierrors.IsNotFound(err) {
+			return apis.ApplicationStatus{}, fmt.Errorf("app %s is not deployed in namespace %s", w.app.Name, w.namespace)
+		}
+		return apis.ApplicationStatus{}, err
+	}
+	w.watchReferences(ctx, &appConfig)
+	return buildApplicationStatus(ctx, w.c, w.app, &appConfig), nil
+}
+
+// watchReferences starts informers for kinds of workloads and traits that are newly referenced by the AppConfig
+func (w *appWatcher) watchReferences(ctx context.Context, appConfig *v1alpha2.ApplicationConfiguration) {
+	objects := []string{appConfig.Name}
+	var started bool
+	watch := func(apiVersion, kind, name string) {
+		if name == "" {
+			return
+		}
+		objects = append(objects, name)
+		gv, err := schema.ParseGroupVersion(apiVersion)
+		if err != nil {
+			return
+		}
+		mapping, err := w.dm.RESTMapping(gv.WithKind(kind).GroupKind(), gv.Version)
+		if err != nil || w.watched[mapping.Resource] {
+			return
+		}
+		w.watched[mapping.Resource] = true
+		w.ownedFactory.ForResource(mapping.Resource).Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc:    func(interface{}) { w.notify() },
+			UpdateFunc: func(interface{}, interface{}) { w.notify() },
+			DeleteFunc: func(interface{}) { w.notify() },
+		})
+		started = true
+	}
+	for _, wl := range appConfig.Status.Workloads {
+		watch(wl.Reference.APIVersion, wl.Reference.Kind, wl.Reference.Name)
+		for _, tr := range wl.Traits {
+			watch(tr.Reference.APIVersion, tr.Reference.Kind, tr.Reference.Name)
+		}
+	}
+	w.mu.Lock()
+	w.objects = objects
+	w.mu.Unlock()
+	if started {
+		w.ownedFactory.Start(ctx.Done())
+	}
+}
+
+// handleKubeEvent pushes Events of objects in the app, objects like Pods and ReplicaSets are named after the workload
+func (w *appWatcher) handleKubeEvent(ctx context.Context, obj interface{}) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return
+	}
+	var event corev1.Event
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, &event); err != nil {
+		return
+	}
+	if !w.ownsObject(event.InvolvedObject.Name) {
+		return
+	}
+	w.send(ctx, apis.AppEvent{Type: apis.AppEventKube, Data: apis.KubeEvent{
+		Type:          event.Type,
+		Reason:        event.Reason,
+		Object:        strings.ToLower(event.InvolvedObject.Kind) + "/" + event.InvolvedObject.Name,
+		Message:       event.Message,
+		Count:         event.Count,
+		LastTimestamp: event.LastTimestamp.String(),
+	}})
+}
+
+func (w *appWatcher) ownsObject(name string) bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	for _, o := range w.objects {
+		if name == o || strings.HasPrefix(name, o+"-") {
+			return true
+		}
+	}
+	return false
+}
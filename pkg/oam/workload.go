@@ -15,6 +15,7 @@ import (
 	"github.com/oam-dev/kubevela/pkg/commands/util"
 	cmdutil "github.com/oam-dev/kubevela/pkg/commands/util"
 	"github.com/oam-dev/kubevela/pkg/plugins"
+	"github.com/oam-dev/kubevela/pkg/server/apis"
 )
 
 // RunOptions include all options for run
@@ -133,3 +134,21 @@ func BaseRun(staging bool, app *application.Application, kubeClient client.Clien
 	}
 	return fmt.Sprintf("App %s deployed", app.Name), nil
 }
+
+// ListWorkloads lists installed workload types
+func ListWorkloads() ([]apis.WorkloadMeta, error) {
+	workloads, err := plugins.LoadInstalledCapabilityWithType(types.TypeWorkload)
+	if err != nil {
+		return nil, err
+	}
+	workloadList := make([]apis.WorkloadMeta, 0, len(workloads))
+	for _, w := range workloads {
+		workloadList = append(workloadList, apis.WorkloadMeta{
+			Name:        w.Name,
+			Description: w.Description,
+			Parameters:  w.Parameters,
+			AppliesTo:   w.AppliesTo,
+		})
+	}
+	return workloadList, nil
+}
@@ -184,7 +184,12 @@ func ParseAndSyncCapability(data []byte, syncDir string) (types.Capability, erro
 		}
 		return HandleDefinition(td.Name, syncDir, td.Spec.Reference.Name, td.Annotations, td.Spec.Extension, types.TypeTrait, td.Spec.AppliesToWorkloads)
 	case "ScopeDefinition":
-		// TODO(wonderflow): support scope definition here.
+		var sd v1alpha2.ScopeDefinition
+		err = yaml.Unmarshal(data, &sd)
+		if err != nil {
+			return types.Capability{}, err
+		}
+		return HandleScopeDefinition(sd.Name, sd.Spec.Reference.Name, sd.Spec.WorkloadRefsPath, sd.Annotations), nil
 	}
 	return types.Capability{}, fmt.Errorf("unknown definition Type %s", obj.GetKind())
 }
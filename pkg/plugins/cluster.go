@@ -37,7 +37,12 @@ func GetCapabilitiesFromCluster(ctx context.Context, namespace string, c types.A
 	if err != nil {
 		return nil, err
 	}
+	scopes, _, err := GetScopesFromCluster(ctx, namespace, c, selector)
+	if err != nil {
+		return nil, err
+	}
 	workloads = append(workloads, traits...)
+	workloads = append(workloads, scopes...)
 	return workloads, nil
 }
 
@@ -130,6 +135,51 @@ func GetTraitsFromCluster(ctx context.Context, namespace string, c types.Args, s
 	return templates, templateErrors, nil
 }
 
+// GetScopesFromCluster will get scope capability from K8s cluster
+func GetScopesFromCluster(ctx context.Context, namespace string, c types.Args, selector labels.Selector) ([]types.Capability, []error, error) {
+	newClient, err := client.New(c.Config, client.Options{Scheme: c.Schema})
+	if err != nil {
+		return nil, nil, err
+	}
+	dm, err := discoverymapper.New(c.Config)
+	if err != nil {
+		return nil, nil, err
+	}
+	var templates []types.Capability
+	var scopeDefs corev1alpha2.ScopeDefinitionList
+	err = newClient.List(ctx, &scopeDefs, &client.ListOptions{Namespace: namespace, LabelSelector: selector})
+	if err != nil {
+		return nil, nil, fmt.Errorf("list ScopeDefinition err: %w", err)
+	}
+
+	var templateErrors []error
+	for _, sd := range scopeDefs.Items {
+		tmp := HandleScopeDefinition(sd.Name, sd.Spec.Reference.Name, sd.Spec.WorkloadRefsPath, sd.Annotations)
+		gvk, err := util.GetGVKFromDefinition(dm, sd.Spec.Reference)
+		if err != nil {
+			templateErrors = append(templateErrors, errors.Wrapf(err, "scope capability `%s` was not ready", sd.Name))
+			continue
+		}
+		tmp.CrdInfo = &types.CRDInfo{
+			APIVersion: gvk.GroupVersion().String(),
+			Kind:       gvk.Kind,
+		}
+		templates = append(templates, tmp)
+	}
+	return templates, templateErrors, nil
+}
+
+// HandleScopeDefinition will handle scope definition to capability, scopes have no CUE template.
+func HandleScopeDefinition(name, crdName, workloadRefsPath string, annotation map[string]string) types.Capability {
+	return types.Capability{
+		Name:             name,
+		Type:             types.TypeScope,
+		CrdName:          crdName,
+		WorkloadRefsPath: workloadRefsPath,
+		Description:      GetDescription(annotation),
+	}
+}
+
 // HandleDefinition will handle definition to capability
 func HandleDefinition(name, syncDir, crdName string, annotation map[string]string, extension *runtime.RawExtension, tp types.CapType, applyTo []string) (types.Capability, error) {
 	var tmp types.Capability
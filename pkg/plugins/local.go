@@ -37,7 +37,12 @@ func LoadAllInstalledCapability() ([]types.Capability, error) {
 	if err != nil {
 		return nil, err
 	}
+	scopes, err := LoadInstalledCapabilityWithType(types.TypeScope)
+	if err != nil {
+		return nil, err
+	}
 	workloads = append(workloads, traits...)
+	workloads = append(workloads, scopes...)
 	return workloads, nil
 }
 
@@ -152,7 +157,7 @@ func SinkTemp2Local(templates []types.Capability, dir string) int {
 func RemoveLegacyTemps(retainedTemps []types.Capability, dir string) int {
 	success := 0
 	var retainedFiles []string
-	subDirs := []string{GetSubDir(dir, types.TypeWorkload), GetSubDir(dir, types.TypeTrait)}
+	subDirs := []string{GetSubDir(dir, types.TypeWorkload), GetSubDir(dir, types.TypeTrait), GetSubDir(dir, types.TypeScope)}
 	for _, tmp := range retainedTemps {
 		subDir := GetSubDir(dir, tmp.Type)
 		tmpFilePath := filepath.Join(subDir, tmp.Name)
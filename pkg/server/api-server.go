@@ -3,15 +3,23 @@ package server
 import (
 	"context"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/server/auth"
+	"github.com/oam-dev/kubevela/pkg/server/util"
 
 	"github.com/pkg/errors"
 
 	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam/discoverymapper"
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 )
 
 // APIServer run a restful API server for dashboard
@@ -19,10 +27,19 @@ type APIServer struct {
 	server     *http.Server
 	KubeClient client.Client
 	dm         discoverymapper.DiscoveryMapper
+	// operations are the documented routes, used to serve the OpenAPI document
+	operations []Operation
+
+	// below are used to authenticate requests and impersonate the caller, authn is nil if authentication is disabled
+	authn      auth.Authenticator
+	policy     *auth.Policy
+	restConfig *rest.Config
+	scheme     *runtime.Scheme
+	mapper     meta.RESTMapper
 }
 
 // New will create APIServer
-func New(c types.Args, port, staticPath string) (*APIServer, error) {
+func New(c types.Args, port, staticPath string, authOpts auth.Options) (*APIServer, error) {
 	newClient, err := client.New(c.Config, client.Options{Scheme: c.Schema})
 	if err != nil {
 		return nil, err
@@ -34,12 +51,30 @@ func New(c types.Args, port, staticPath string) (*APIServer, error) {
 	s := &APIServer{
 		KubeClient: newClient,
 		dm:         dm,
+		restConfig: c.Config,
+		scheme:     c.Schema,
+	}
+	if authOpts.Enabled() {
+		if s.authn, err = auth.NewAuthenticator(authOpts, newClient); err != nil {
+			return nil, err
+		}
+		if authOpts.PolicyFile != "" {
+			if s.policy, err = auth.LoadPolicy(authOpts.PolicyFile); err != nil {
+				return nil, err
+			}
+		} else {
+			ctrl.Log.Info("WARNING: no authorization policy file is specified, authenticated users are only allowed to read")
+		}
+		if s.mapper, err = apiutil.NewDynamicRESTMapper(c.Config); err != nil {
+			return nil, err
+		}
 	}
 	server := &http.Server{
 		Addr:         port,
-		Handler:      s.setupRoute(staticPath),
+		Handler:      withoutWriteDeadline(s.setupRoute(staticPath), isEventStream),
 		ReadTimeout:  5 * time.Second,
 		WriteTimeout: 10 * time.Second,
+		IdleTimeout:  60 * time.Second,
 	}
 	server.SetKeepAlivesEnabled(true)
 	s.server = server
@@ -62,3 +97,62 @@ func (s *APIServer) Shutdown(ctx context.Context) error {
 	ctrl.Log.Info("sever shutting down")
 	return s.server.Shutdown(ctx)
 }
+
+// keys to set/get the Kubernetes client and config impersonating the caller in gin context
+const (
+	clientKey = "kubeClient"
+	configKey = "kubeConfig"
+)
+
+// impersonate creates a Kubernetes client acting as the authenticated user, so cluster RBAC applies to the caller
+func (s *APIServer) impersonate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := auth.UserFrom(c)
+		if !ok {
+			return
+		}
+		cfg := rest.CopyConfig(s.restConfig)
+		cfg.Impersonate = rest.ImpersonationConfig{UserName: user.Name, Groups: user.Groups}
+		kubeClient, err := client.New(cfg, client.Options{Scheme: s.scheme, Mapper: s.mapper})
+		if err != nil {
+			util.SetErrorAndAbort(c, util.StatusInternalServerError, err.Error())
+			return
+		}
+		c.Set(clientKey, kubeClient)
+		c.Set(configKey, cfg)
+	}
+}
+
+// isEventStream matches the event streams of apps, which are long-lived responses
+func isEventStream(r *http.Request) bool {
+	return r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, util.RootPath+util.EnvironmentPath+"/") &&
+		strings.HasSuffix(r.URL.Path, "/events")
+}
+
+// withoutWriteDeadline lifts WriteTimeout of the server for requests matched, other requests are still bound by it
+func withoutWriteDeadline(h http.Handler, match func(r *http.Request) bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if match(r) {
+			if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+				ctrl.Log.Error(err, "failed to lift write deadline of event stream", "path", r.URL.Path)
+			}
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// kubeClient returns the Kubernetes client of the request, it impersonates the caller if authentication is enabled
+func (s *APIServer) kubeClient(c *gin.Context) client.Client {
+	if v, ok := c.Get(clientKey); ok {
+		return v.(client.Client)
+	}
+	return s.KubeClient
+}
+
+// kubeConfig returns the Kubernetes rest config of the request, it impersonates the caller if authentication is enabled
+func (s *APIServer) kubeConfig(c *gin.Context) *rest.Config {
+	if v, ok := c.Get(configKey); ok {
+		return v.(*rest.Config)
+	}
+	return s.restConfig
+}
@@ -0,0 +1,37 @@
+package server
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithoutWriteDeadline(t *testing.T) {
+	slow := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(300 * time.Millisecond)
+		_, _ = w.Write([]byte("done"))
+	})
+	ts := httptest.NewUnstartedServer(withoutWriteDeadline(slow, isEventStream))
+	ts.Config.WriteTimeout = 100 * time.Millisecond
+	ts.Start()
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/api/envs/default/apps/myapp/events")
+	assert.NoError(t, err)
+	body, err := ioutil.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, "done", string(body))
+
+	// other requests are still bound by WriteTimeout
+	resp, err = http.Get(ts.URL + "/api/envs/default/apps/myapp")
+	if err == nil {
+		body, err = ioutil.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+	}
+	assert.True(t, err != nil || string(body) != "done")
+}
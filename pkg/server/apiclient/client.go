@@ -0,0 +1,187 @@
+/*
+Package apiclient is a typed Go client of the vela API server.
+
+Methods of Client are generated from the routes of the API server by `make api-client`, one for each operation in
+the OpenAPI document served at `/api/openapi.json`.
+*/
+package apiclient
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/oam-dev/kubevela/pkg/server/apis"
+)
+
+// Client talks to a vela API server
+type Client struct {
+	// BaseURL is the address of API server, e.g. http://127.0.0.1:38081
+	BaseURL string
+	// Token is sent as bearer token if it's not empty
+	Token      string
+	HTTPClient *http.Client
+}
+
+// New creates a Client
+func New(baseURL, token string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		Token:      token,
+		HTTPClient: http.DefaultClient,
+	}
+}
+
+// Error is returned when API server responds a failure
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("API server responded %d: %s", e.StatusCode, e.Message)
+}
+
+// response is apis.Response with data undecoded, error is set by middlewares when the request is rejected
+type response struct {
+	Code  int             `json:"code"`
+	Data  json.RawMessage `json:"data"`
+	Error string          `json:"error"`
+}
+
+func (c *Client) newRequest(ctx context.Context, method, path string, body interface{}) (*http.Request, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	return req, nil
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body, out interface{}) error {
+	req, err := c.newRequest(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck
+	defer resp.Body.Close()
+	var r response
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil && err != io.EOF {
+		return fmt.Errorf("decode response of %s %s err %w", method, path, err)
+	}
+	if resp.StatusCode != http.StatusOK || r.Code != http.StatusOK {
+		return &Error{StatusCode: resp.StatusCode, Message: r.message()}
+	}
+	if out == nil || len(r.Data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(r.Data, out)
+}
+
+func (r response) message() string {
+	if r.Error != "" {
+		return r.Error
+	}
+	var msg string
+	if err := json.Unmarshal(r.Data, &msg); err == nil {
+		return msg
+	}
+	return string(r.Data)
+}
+
+// stream reads Server-Sent Events until the server closes the stream, ctx is done or handler returns an error.
+// Heartbeat `ping` events are not passed to handler.
+func (c *Client) stream(ctx context.Context, path string, handler func(event string, data json.RawMessage) error) error {
+	req, err := c.newRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		var r response
+		_ = json.NewDecoder(resp.Body).Decode(&r)
+		return &Error{StatusCode: resp.StatusCode, Message: r.message()}
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	var event string
+	var data []string
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if event != "" && event != "ping" {
+				if err := handler(event, json.RawMessage(strings.Join(data, "\n"))); err != nil {
+					return err
+				}
+			}
+			event, data = "", nil
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	return scanner.Err()
+}
+
+// DecodeAppEvent decodes an event of WatchApp, its data is decoded into the type of the event, e.g.
+// apis.ComponentStatus for `health` events
+func DecodeAppEvent(event string, data json.RawMessage) (apis.AppEvent, error) {
+	e := apis.AppEvent{Type: event}
+	var err error
+	switch event {
+	case apis.AppEventStatus:
+		var d apis.ApplicationStatus
+		err = json.Unmarshal(data, &d)
+		e.Data = d
+	case apis.AppEventHealth:
+		var d apis.ComponentStatus
+		err = json.Unmarshal(data, &d)
+		e.Data = d
+	case apis.AppEventTrait:
+		var d apis.TraitStatus
+		err = json.Unmarshal(data, &d)
+		e.Data = d
+	case apis.AppEventKube:
+		var d apis.KubeEvent
+		err = json.Unmarshal(data, &d)
+		e.Data = d
+	default:
+		// error messages are plain strings
+		e.Data = string(data)
+	}
+	return e, err
+}
@@ -0,0 +1,58 @@
+package apiclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/oam-dev/kubevela/pkg/server/apis"
+)
+
+func TestClient(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		switch r.URL.EscapedPath() {
+		case "/api/envs/default/apps/my%20app/components/web":
+			var body apis.ComponentBody
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, "1", body.ResourceVersion)
+			fmt.Fprint(w, `{"code":200,"data":{"name":"web","resourceVersion":"2"}}`)
+		case "/api/envs/default/apps/my%20app/events":
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, "event:ping\ndata:\n\n")
+			fmt.Fprint(w, "event:health\ndata:{\"name\":\"web\",\"healthStatus\":\"HEALTHY\"}\n\n")
+			fmt.Fprint(w, "event:error\ndata:app my app is not deployed\n\n")
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, `{"code":500,"data":"app not found"}`)
+		}
+	}))
+	defer ts.Close()
+	c := New(ts.URL+"/", "secret")
+	ctx := context.Background()
+
+	comp, err := c.UpdateComponent(ctx, "default", "my app", "web", apis.ComponentBody{ResourceVersion: "1"})
+	assert.NoError(t, err)
+	assert.Equal(t, "web", comp.Name)
+	assert.Equal(t, "2", comp.ResourceVersion)
+
+	_, err = c.GetApp(ctx, "default", "unknown")
+	assert.Equal(t, &Error{StatusCode: http.StatusInternalServerError, Message: "app not found"}, err)
+
+	var events []apis.AppEvent
+	err = c.WatchApp(ctx, "default", "my app", func(event string, data json.RawMessage) error {
+		e, err := DecodeAppEvent(event, data)
+		events = append(events, e)
+		return err
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []apis.AppEvent{
+		{Type: apis.AppEventHealth, Data: apis.ComponentStatus{Name: "web", HealthStatus: "HEALTHY"}},
+		{Type: apis.AppEventError, Data: "app my app is not deployed"},
+	}, events)
+}
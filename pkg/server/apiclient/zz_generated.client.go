@@ -0,0 +1,271 @@
+// Code generated by hack/apiclient/gen.go. DO NOT EDIT.
+
+package apiclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/appfile"
+	"github.com/oam-dev/kubevela/pkg/plugins"
+	"github.com/oam-dev/kubevela/pkg/server/apis"
+)
+
+// ListCapabilities calls `GET /api/capabilities`: List capabilities of all capability centers
+func (c *Client) ListCapabilities(ctx context.Context) ([]types.Capability, error) {
+	var out []types.Capability
+	err := c.do(ctx, http.MethodGet, "/api/capabilities", nil, &out)
+	return out, err
+}
+
+// RemoveCapabilityWithoutName calls `DELETE /api/capabilities`: Same as RemoveCapabilityFromCluster with an empty name
+func (c *Client) RemoveCapabilityWithoutName(ctx context.Context) (string, error) {
+	var out string
+	err := c.do(ctx, http.MethodDelete, "/api/capabilities", nil, &out)
+	return out, err
+}
+
+// RemoveCapabilityFromCluster calls `DELETE /api/capabilities/:capabilityName`: Uninstall a capability from cluster
+func (c *Client) RemoveCapabilityFromCluster(ctx context.Context, capabilityName string) (string, error) {
+	var out string
+	err := c.do(ctx, http.MethodDelete, fmt.Sprintf("/api/capabilities/%s", url.PathEscape(capabilityName)), nil, &out)
+	return out, err
+}
+
+// ListCapabilityCenters calls `GET /api/capability-centers`: List capability centers
+func (c *Client) ListCapabilityCenters(ctx context.Context) ([]apis.CapabilityCenterMeta, error) {
+	var out []apis.CapabilityCenterMeta
+	err := c.do(ctx, http.MethodGet, "/api/capability-centers", nil, &out)
+	return out, err
+}
+
+// AddCapabilityCenter calls `PUT /api/capability-centers`: Add a capability center and sync capabilities from it
+func (c *Client) AddCapabilityCenter(ctx context.Context, body plugins.CapCenterConfig) (string, error) {
+	var out string
+	err := c.do(ctx, http.MethodPut, "/api/capability-centers", body, &out)
+	return out, err
+}
+
+// DeleteCapabilityCenter calls `DELETE /api/capability-centers/:capabilityCenterName`: Delete a capability center
+func (c *Client) DeleteCapabilityCenter(ctx context.Context, capabilityCenterName string) (string, error) {
+	var out string
+	err := c.do(ctx, http.MethodDelete, fmt.Sprintf("/api/capability-centers/%s", url.PathEscape(capabilityCenterName)), nil, &out)
+	return out, err
+}
+
+// SyncCapabilityCenter calls `PUT /api/capability-centers/:capabilityCenterName/capabilities`: Sync capabilities from a capability center
+func (c *Client) SyncCapabilityCenter(ctx context.Context, capabilityCenterName string) (string, error) {
+	var out string
+	err := c.do(ctx, http.MethodPut, fmt.Sprintf("/api/capability-centers/%s/capabilities", url.PathEscape(capabilityCenterName)), nil, &out)
+	return out, err
+}
+
+// AddCapabilityIntoCluster calls `PUT /api/capability-centers/:capabilityCenterName/capabilities/:capabilityName`: Install a capability from a capability center into cluster
+func (c *Client) AddCapabilityIntoCluster(ctx context.Context, capabilityCenterName string, capabilityName string) (string, error) {
+	var out string
+	err := c.do(ctx, http.MethodPut, fmt.Sprintf("/api/capability-centers/%s/capabilities/%s", url.PathEscape(capabilityCenterName), url.PathEscape(capabilityName)), nil, &out)
+	return out, err
+}
+
+// ListEnv calls `GET /api/envs`: List all environments
+func (c *Client) ListEnv(ctx context.Context) ([]apis.Environment, error) {
+	var out []apis.Environment
+	err := c.do(ctx, http.MethodGet, "/api/envs", nil, &out)
+	return out, err
+}
+
+// CreateEnv calls `POST /api/envs`: Create an environment
+func (c *Client) CreateEnv(ctx context.Context, body apis.Environment) (string, error) {
+	var out string
+	err := c.do(ctx, http.MethodPost, "/api/envs", body, &out)
+	return out, err
+}
+
+// GetEnv calls `GET /api/envs/:envName`: Get an environment
+func (c *Client) GetEnv(ctx context.Context, envName string) ([]apis.Environment, error) {
+	var out []apis.Environment
+	err := c.do(ctx, http.MethodGet, fmt.Sprintf("/api/envs/%s", url.PathEscape(envName)), nil, &out)
+	return out, err
+}
+
+// UpdateEnv calls `PUT /api/envs/:envName`: Update namespace of an environment
+func (c *Client) UpdateEnv(ctx context.Context, envName string, body apis.EnvironmentBody) (string, error) {
+	var out string
+	err := c.do(ctx, http.MethodPut, fmt.Sprintf("/api/envs/%s", url.PathEscape(envName)), body, &out)
+	return out, err
+}
+
+// SetEnv calls `PATCH /api/envs/:envName`: Set an environment as the current one
+func (c *Client) SetEnv(ctx context.Context, envName string) (string, error) {
+	var out string
+	err := c.do(ctx, http.MethodPatch, fmt.Sprintf("/api/envs/%s", url.PathEscape(envName)), nil, &out)
+	return out, err
+}
+
+// DeleteEnv calls `DELETE /api/envs/:envName`: Delete an environment
+func (c *Client) DeleteEnv(ctx context.Context, envName string) (string, error) {
+	var out string
+	err := c.do(ctx, http.MethodDelete, fmt.Sprintf("/api/envs/%s", url.PathEscape(envName)), nil, &out)
+	return out, err
+}
+
+// ListApps calls `GET /api/envs/:envName/apps`: List applications in an environment
+func (c *Client) ListApps(ctx context.Context, envName string) ([]apis.ApplicationMeta, error) {
+	var out []apis.ApplicationMeta
+	err := c.do(ctx, http.MethodGet, fmt.Sprintf("/api/envs/%s/apps", url.PathEscape(envName)), nil, &out)
+	return out, err
+}
+
+// GetApp calls `GET /api/envs/:envName/apps/:appName`: Get an application with status of its components
+func (c *Client) GetApp(ctx context.Context, envName string, appName string) (apis.ApplicationMeta, error) {
+	var out apis.ApplicationMeta
+	err := c.do(ctx, http.MethodGet, fmt.Sprintf("/api/envs/%s/apps/%s", url.PathEscape(envName), url.PathEscape(appName)), nil, &out)
+	return out, err
+}
+
+// UpdateApp calls `PUT /api/envs/:envName/apps/:appName`: Deploy an application with an Appfile in YAML or JSON
+func (c *Client) UpdateApp(ctx context.Context, envName string, appName string, body appfile.AppFile) (apis.ApplicationMeta, error) {
+	var out apis.ApplicationMeta
+	err := c.do(ctx, http.MethodPut, fmt.Sprintf("/api/envs/%s/apps/%s", url.PathEscape(envName), url.PathEscape(appName)), body, &out)
+	return out, err
+}
+
+// DeleteApp calls `DELETE /api/envs/:envName/apps/:appName`: Delete an application
+func (c *Client) DeleteApp(ctx context.Context, envName string, appName string) (string, error) {
+	var out string
+	err := c.do(ctx, http.MethodDelete, fmt.Sprintf("/api/envs/%s/apps/%s", url.PathEscape(envName), url.PathEscape(appName)), nil, &out)
+	return out, err
+}
+
+// ListComponents calls `GET /api/envs/:envName/apps/:appName/components`: Get an application with its components
+func (c *Client) ListComponents(ctx context.Context, envName string, appName string) (apis.ApplicationMeta, error) {
+	var out apis.ApplicationMeta
+	err := c.do(ctx, http.MethodGet, fmt.Sprintf("/api/envs/%s/apps/%s/components", url.PathEscape(envName), url.PathEscape(appName)), nil, &out)
+	return out, err
+}
+
+// GetComponent calls `GET /api/envs/:envName/apps/:appName/components/:compName`: Get a component of an application
+func (c *Client) GetComponent(ctx context.Context, envName string, appName string, compName string) (apis.ComponentMeta, error) {
+	var out apis.ComponentMeta
+	err := c.do(ctx, http.MethodGet, fmt.Sprintf("/api/envs/%s/apps/%s/components/%s", url.PathEscape(envName), url.PathEscape(appName), url.PathEscape(compName)), nil, &out)
+	return out, err
+}
+
+// UpdateComponent calls `PUT /api/envs/:envName/apps/:appName/components/:compName`: Update workload fields and traits of a component
+func (c *Client) UpdateComponent(ctx context.Context, envName string, appName string, compName string, body apis.ComponentBody) (apis.ComponentMeta, error) {
+	var out apis.ComponentMeta
+	err := c.do(ctx, http.MethodPut, fmt.Sprintf("/api/envs/%s/apps/%s/components/%s", url.PathEscape(envName), url.PathEscape(appName), url.PathEscape(compName)), body, &out)
+	return out, err
+}
+
+// DeleteComponent calls `DELETE /api/envs/:envName/apps/:appName/components/:compName`: Delete a component of an application
+func (c *Client) DeleteComponent(ctx context.Context, envName string, appName string, compName string) (string, error) {
+	var out string
+	err := c.do(ctx, http.MethodDelete, fmt.Sprintf("/api/envs/%s/apps/%s/components/%s", url.PathEscape(envName), url.PathEscape(appName), url.PathEscape(compName)), nil, &out)
+	return out, err
+}
+
+// AttachTrait calls `POST /api/envs/:envName/apps/:appName/components/:compName/traits`: Attach a trait to a component
+func (c *Client) AttachTrait(ctx context.Context, envName string, appName string, compName string, body apis.TraitBody) (string, error) {
+	var out string
+	err := c.do(ctx, http.MethodPost, fmt.Sprintf("/api/envs/%s/apps/%s/components/%s/traits", url.PathEscape(envName), url.PathEscape(appName), url.PathEscape(compName)), body, &out)
+	return out, err
+}
+
+// DetachTrait calls `DELETE /api/envs/:envName/apps/:appName/components/:compName/traits/:traitName`: Detach a trait from a component
+func (c *Client) DetachTrait(ctx context.Context, envName string, appName string, compName string, traitName string) (string, error) {
+	var out string
+	err := c.do(ctx, http.MethodDelete, fmt.Sprintf("/api/envs/%s/apps/%s/components/%s/traits/%s", url.PathEscape(envName), url.PathEscape(appName), url.PathEscape(compName), url.PathEscape(traitName)), nil, &out)
+	return out, err
+}
+
+// WatchApp calls `GET /api/envs/:envName/apps/:appName/events`: Stream status changes and Kubernetes Events of an application
+func (c *Client) WatchApp(ctx context.Context, envName string, appName string, handler func(event string, data json.RawMessage) error) error {
+	return c.stream(ctx, fmt.Sprintf("/api/envs/%s/apps/%s/events", url.PathEscape(envName), url.PathEscape(appName)), handler)
+}
+
+// ListScopes calls `GET /api/envs/:envName/scopes`: List scopes in an environment
+func (c *Client) ListScopes(ctx context.Context, envName string) ([]apis.ScopeMeta, error) {
+	var out []apis.ScopeMeta
+	err := c.do(ctx, http.MethodGet, fmt.Sprintf("/api/envs/%s/scopes", url.PathEscape(envName)), nil, &out)
+	return out, err
+}
+
+// CreateScope calls `POST /api/envs/:envName/scopes`: Create a scope
+func (c *Client) CreateScope(ctx context.Context, envName string, body apis.ScopeBody) (string, error) {
+	var out string
+	err := c.do(ctx, http.MethodPost, fmt.Sprintf("/api/envs/%s/scopes", url.PathEscape(envName)), body, &out)
+	return out, err
+}
+
+// GetScope calls `GET /api/envs/:envName/scopes/:scopeName`: Get a scope
+func (c *Client) GetScope(ctx context.Context, envName string, scopeName string) (apis.ScopeMeta, error) {
+	var out apis.ScopeMeta
+	err := c.do(ctx, http.MethodGet, fmt.Sprintf("/api/envs/%s/scopes/%s", url.PathEscape(envName), url.PathEscape(scopeName)), nil, &out)
+	return out, err
+}
+
+// UpdateScope calls `PUT /api/envs/:envName/scopes/:scopeName`: Update spec of a scope
+func (c *Client) UpdateScope(ctx context.Context, envName string, scopeName string, body apis.ScopeBody) (string, error) {
+	var out string
+	err := c.do(ctx, http.MethodPut, fmt.Sprintf("/api/envs/%s/scopes/%s", url.PathEscape(envName), url.PathEscape(scopeName)), body, &out)
+	return out, err
+}
+
+// DeleteScope calls `DELETE /api/envs/:envName/scopes/:scopeName`: Delete a scope
+func (c *Client) DeleteScope(ctx context.Context, envName string, scopeName string) (string, error) {
+	var out string
+	err := c.do(ctx, http.MethodDelete, fmt.Sprintf("/api/envs/%s/scopes/%s", url.PathEscape(envName), url.PathEscape(scopeName)), nil, &out)
+	return out, err
+}
+
+// ListTraits calls `GET /api/traits`: List installed traits
+func (c *Client) ListTraits(ctx context.Context) ([]types.Capability, error) {
+	var out []types.Capability
+	err := c.do(ctx, http.MethodGet, "/api/traits", nil, &out)
+	return out, err
+}
+
+// GetTrait calls `GET /api/traits/:traitName`: Get an installed trait
+func (c *Client) GetTrait(ctx context.Context, traitName string) (types.Capability, error) {
+	var out types.Capability
+	err := c.do(ctx, http.MethodGet, fmt.Sprintf("/api/traits/%s", url.PathEscape(traitName)), nil, &out)
+	return out, err
+}
+
+// GetVersion calls `GET /api/version`: Get version of the API server
+func (c *Client) GetVersion(ctx context.Context) (map[string]string, error) {
+	var out map[string]string
+	err := c.do(ctx, http.MethodGet, "/api/version", nil, &out)
+	return out, err
+}
+
+// ListWorkloads calls `GET /api/workloads`: List installed workload types
+func (c *Client) ListWorkloads(ctx context.Context) ([]apis.WorkloadMeta, error) {
+	var out []apis.WorkloadMeta
+	err := c.do(ctx, http.MethodGet, "/api/workloads", nil, &out)
+	return out, err
+}
+
+// CreateWorkload calls `POST /api/workloads`: Run a workload
+func (c *Client) CreateWorkload(ctx context.Context, body apis.WorkloadRunBody) (string, error) {
+	var out string
+	err := c.do(ctx, http.MethodPost, "/api/workloads", body, &out)
+	return out, err
+}
+
+// GetWorkload calls `GET /api/workloads/:workloadName`: Get an installed workload type
+func (c *Client) GetWorkload(ctx context.Context, workloadName string) (types.Capability, error) {
+	var out types.Capability
+	err := c.do(ctx, http.MethodGet, fmt.Sprintf("/api/workloads/%s", url.PathEscape(workloadName)), nil, &out)
+	return out, err
+}
+
+// UpdateWorkload calls `PUT /api/workloads/:workloadName`: Not implemented yet
+func (c *Client) UpdateWorkload(ctx context.Context, workloadName string) error {
+	return c.do(ctx, http.MethodPut, fmt.Sprintf("/api/workloads/%s", url.PathEscape(workloadName)), nil, nil)
+}
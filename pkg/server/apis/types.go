@@ -14,6 +14,9 @@ type Environment struct {
 	Email     string `json:"email"`
 	Domain    string `json:"domain"`
 	Current   string `json:"current,omitempty"`
+	// Cluster and Quota are only returned when listing environments
+	Cluster string `json:"cluster,omitempty"`
+	Quota   string `json:"quota,omitempty"`
 }
 
 // EnvironmentBody used for restful API in dashboard server
@@ -46,9 +49,10 @@ type WorkloadRunBody struct {
 
 // WorkloadMeta store workload metadata for dashboard restful API server
 type WorkloadMeta struct {
-	Name       string            `json:"name"`
-	Parameters []types.Parameter `json:"parameters,omitempty"`
-	AppliesTo  []string          `json:"appliesTo,omitempty"`
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Parameters  []types.Parameter `json:"parameters,omitempty"`
+	AppliesTo   []string          `json:"appliesTo,omitempty"`
 }
 
 // TraitBody used to present trait which is to be attached and, of which parameters are set
@@ -66,6 +70,8 @@ type ComponentMeta struct {
 	Name     string               `json:"name"`
 	Status   string               `json:"status,omitempty"`
 	Workload runtime.RawExtension `json:"workload,omitempty"`
+	// ResourceVersion of the Component, used for optimistic concurrency on update
+	ResourceVersion string `json:"resourceVersion,omitempty"`
 	// WorkloadName for `vela comp ls`
 	WorkloadName string                        `json:"workloadName,omitempty"`
 	Traits       []corev1alpha2.ComponentTrait `json:"traits,omitempty"`
@@ -77,6 +83,14 @@ type ComponentMeta struct {
 	Component   corev1alpha2.Component                `json:"-"`
 }
 
+// ComponentBody used for restful API to update a component in dashboard server
+type ComponentBody struct {
+	// ResourceVersion should be the one last read, the update will be rejected if the component has changed since then
+	ResourceVersion string                            `json:"resourceVersion,omitempty"`
+	Workload        map[string]interface{}            `json:"workload,omitempty"`
+	Traits          map[string]map[string]interface{} `json:"traits,omitempty"`
+}
+
 // ApplicationMeta used for dashboard restful API server
 type ApplicationMeta struct {
 	Name        string          `json:"name"`
@@ -85,6 +99,98 @@ type ApplicationMeta struct {
 	CreatedTime string          `json:"createdTime,omitempty"`
 }
 
+// Types of AppEvent
+const (
+	AppEventStatus = "status"
+	AppEventHealth = "health"
+	AppEventTrait  = "trait"
+	AppEventKube   = "event"
+	AppEventError  = "error"
+)
+
+// AppEvent is pushed by the application event stream of dashboard restful API server
+type AppEvent struct {
+	Type string      `json:"type"`
+	Data interface{} `json:"data"`
+}
+
+// ApplicationStatus is the status snapshot of an application, it's sent as `status` event without components
+type ApplicationStatus struct {
+	Name       string            `json:"name"`
+	Status     string            `json:"status"`
+	Message    string            `json:"message,omitempty"`
+	Components []ComponentStatus `json:"components,omitempty"`
+}
+
+// ComponentStatus is the workload and health status of a component, it's sent as `health` event without traits
+type ComponentStatus struct {
+	Name           string `json:"name"`
+	WorkloadStatus string `json:"workloadStatus,omitempty"`
+	HealthStatus   string `json:"healthStatus,omitempty"`
+	Diagnosis      string `json:"diagnosis,omitempty"`
+	// Replicas is ready replicas against desired replicas of the workload, e.g. `2/3`
+	Replicas string        `json:"replicas,omitempty"`
+	Traits   []TraitStatus `json:"traits,omitempty"`
+}
+
+// TraitStatus is the check result of a trait, it's sent as `trait` event
+type TraitStatus struct {
+	Component string `json:"component"`
+	Type      string `json:"type"`
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	Message   string `json:"message,omitempty"`
+}
+
+// KubeEvent is a Kubernetes Event of objects in an application, it's sent as `event` event
+type KubeEvent struct {
+	Type          string `json:"type"`
+	Reason        string `json:"reason"`
+	Object        string `json:"object"`
+	Message       string `json:"message"`
+	Count         int32  `json:"count,omitempty"`
+	LastTimestamp string `json:"lastTimestamp,omitempty"`
+}
+
+// ApplicationDetail is the Appfile of an application shown by `vela show`
+type ApplicationDetail struct {
+	Name        string          `json:"name"`
+	Namespace   string          `json:"namespace"`
+	CreatedTime string          `json:"createdTime,omitempty"`
+	UpdatedTime string          `json:"updatedTime,omitempty"`
+	Services    []ServiceDetail `json:"services,omitempty"`
+}
+
+// ServiceDetail is a service in the Appfile with its workload arguments and traits
+type ServiceDetail struct {
+	Name         string                            `json:"name"`
+	WorkloadType string                            `json:"workloadType"`
+	Arguments    map[string]interface{}            `json:"arguments,omitempty"`
+	Traits       map[string]map[string]interface{} `json:"traits,omitempty"`
+}
+
+// ConfigMeta is a config of an environment listed by `vela config ls`, values are never returned
+type ConfigMeta struct {
+	Name string   `json:"name"`
+	Keys []string `json:"keys,omitempty"`
+}
+
+// ScopeBody used for restful API to create or update a scope in dashboard server
+type ScopeBody struct {
+	Name string                 `json:"name"`
+	Type string                 `json:"type"`
+	Spec map[string]interface{} `json:"spec,omitempty"`
+}
+
+// ScopeMeta store scope info for dashboard restful API server
+type ScopeMeta struct {
+	Name        string                 `json:"name"`
+	Type        string                 `json:"type"`
+	Spec        map[string]interface{} `json:"spec,omitempty"`
+	Status      map[string]interface{} `json:"status,omitempty"`
+	CreatedTime string                 `json:"createdTime,omitempty"`
+}
+
 // CapabilityMeta used for dashboard restful API server
 type CapabilityMeta struct {
 	CapabilityName       string `json:"capabilityName"`
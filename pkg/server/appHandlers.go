@@ -1,15 +1,55 @@
 package server
 
 import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/oam-dev/kubevela/pkg/application"
+	cmdutil "github.com/oam-dev/kubevela/pkg/commands/util"
 	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/server/apis"
 	"github.com/oam-dev/kubevela/pkg/server/util"
 	"github.com/oam-dev/kubevela/pkg/utils/env"
 
 	"github.com/gin-gonic/gin"
+	ctrl "sigs.k8s.io/controller-runtime"
 )
 
-// UpdateApps is placeholder for updating applications
+// UpdateApps updates an application with the Appfile (YAML or JSON) in the request body
 func (s *APIServer) UpdateApps(c *gin.Context) {
+	envName := c.Param("envName")
+	envMeta, err := env.GetEnvByName(envName)
+	if err != nil {
+		util.HandleError(c, util.StatusInternalServerError, err)
+		return
+	}
+	appName := c.Param("appName")
+	body, err := c.GetRawData()
+	if err != nil {
+		util.HandleError(c, util.InvalidArgument, "the appfile is unrecognizable")
+		return
+	}
+	app, err := application.LoadFromBytes(body)
+	if err != nil {
+		util.HandleError(c, util.InvalidArgument, fmt.Sprintf("invalid appfile: %v", err))
+		return
+	}
+	if app.Name != appName {
+		util.HandleError(c, util.InvalidArgument,
+			fmt.Sprintf("name '%s' in appfile doesn't match the app '%s' to update", app.Name, appName))
+		return
+	}
+	// the appfile comes from the request, it can't read files or env vars of the server
+	app.DisallowLocalSources()
+	ctrl.Log.Info("Update app request", "env", envName, "app", appName)
+
+	ctx := util.GetContext(c)
+	applicationMeta, err := oam.UpdateApplication(ctx, s.kubeClient(c), envMeta, app,
+		cmdutil.IOStreams{In: os.Stdin, Out: os.Stdout, ErrOut: os.Stderr})
+	util.AssembleResponse(c, applicationMeta, err)
 }
 
 // GetApp requests an application by the namespacedname in the gin.Context
@@ -23,7 +63,7 @@ func (s *APIServer) GetApp(c *gin.Context) {
 	namespace := envMeta.Namespace
 	appName := c.Param("appName")
 	ctx := util.GetContext(c)
-	applicationMeta, err := oam.RetrieveApplicationStatusByName(ctx, s.KubeClient, appName, namespace)
+	applicationMeta, err := oam.RetrieveApplicationStatusByName(ctx, s.kubeClient(c), appName, namespace)
 	if err != nil {
 		util.HandleError(c, util.StatusInternalServerError, err)
 		return
@@ -42,7 +82,7 @@ func (s *APIServer) ListApps(c *gin.Context) {
 	namespace := envMeta.Namespace
 
 	ctx := util.GetContext(c)
-	applicationMetaList, err := oam.ListApplications(ctx, s.KubeClient, oam.Option{Namespace: namespace})
+	applicationMetaList, err := oam.ListApplications(ctx, s.kubeClient(c), oam.Option{Namespace: namespace})
 	if err != nil {
 		util.HandleError(c, util.StatusInternalServerError, err.Error())
 		return
@@ -61,10 +101,64 @@ func (s *APIServer) DeleteApps(c *gin.Context) {
 	appName := c.Param("appName")
 
 	o := oam.DeleteOptions{
-		Client:  s.KubeClient,
+		Client:  s.kubeClient(c),
 		Env:     envMeta,
 		AppName: appName,
 	}
 	message, err := o.DeleteApp()
 	util.AssembleResponse(c, message, err)
 }
+
+// eventStreamHeartbeat is the interval of ping events, which keep the event stream alive through proxies
+const eventStreamHeartbeat = 15 * time.Second
+
+// WatchApp streams status changes, health changes, trait check results and Kubernetes Events of an application
+// as Server-Sent Events
+func (s *APIServer) WatchApp(c *gin.Context) {
+	envName := c.Param("envName")
+	envMeta, err := env.GetEnvByName(envName)
+	if err != nil {
+		util.HandleError(c, util.StatusInternalServerError, err)
+		return
+	}
+	appName := c.Param("appName")
+	app, err := application.Load(envName, appName)
+	if err != nil {
+		util.HandleError(c, util.StatusInternalServerError, err)
+		return
+	}
+	if app.Name == "" {
+		util.HandleError(c, util.StatusInternalServerError, fmt.Sprintf("app %s not found in env %s", appName, envName))
+		return
+	}
+	ctrl.Log.Info("Watch app request", "env", envName, "app", appName)
+
+	ctx, cancel := context.WithCancel(util.GetContext(c))
+	defer cancel()
+	events := make(chan apis.AppEvent)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- oam.WatchApplication(ctx, s.kubeConfig(c), s.kubeClient(c), s.dm, app, envMeta.Namespace, events)
+	}()
+	heartbeat := time.NewTicker(eventStreamHeartbeat)
+	defer heartbeat.Stop()
+
+	c.Header("Cache-Control", "no-cache")
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case e := <-events:
+			c.SSEvent(e.Type, e.Data)
+			return true
+		case <-heartbeat.C:
+			c.SSEvent("ping", time.Now().Format(time.RFC3339))
+			return true
+		case err := <-errCh:
+			if err != nil {
+				c.SSEvent(apis.AppEventError, err.Error())
+			}
+			return false
+		case <-ctx.Done():
+			return false
+		}
+	})
+}
@@ -0,0 +1,174 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/plugins"
+	"github.com/oam-dev/kubevela/pkg/server/apis"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+	"github.com/oam-dev/kubevela/pkg/utils/system"
+)
+
+const testWebservice = `parameter: #webservice
+#webservice: {
+  image: string
+  port: *80 | int
+}
+
+output: {
+  apiVersion: "apps/v1"
+  kind: "Deployment"
+  metadata: name: context.name
+  spec: template: spec: containers: [{
+    name: context.name
+    image: parameter.image
+    ports: [{containerPort: parameter.port}]
+  }]
+}
+`
+
+// newTestServer returns a server with a fake cluster, the default env and a webservice workload installed in a
+// temporary VELA_HOME
+func newTestServer(t *testing.T) (*APIServer, http.Handler, func()) {
+	home, err := ioutil.TempDir("", "vela-server")
+	assert.NoError(t, err)
+	assert.NoError(t, os.Setenv(system.VelaHomeEnv, home))
+	assert.NoError(t, system.InitDefaultEnv())
+	capDir, err := system.GetCapabilityDir()
+	assert.NoError(t, err)
+	plugins.SinkTemp2Local([]types.Capability{{Name: "webservice", Type: types.TypeWorkload,
+		CueTemplate: testWebservice}}, capDir)
+
+	s := &APIServer{KubeClient: fake.NewFakeClientWithScheme(common.Scheme)}
+	return s, s.setupRoute(""), func() {
+		_ = os.Unsetenv(system.VelaHomeEnv)
+		_ = os.RemoveAll(home)
+	}
+}
+
+func doRequest(handler http.Handler, method, path, body string) (int, apis.Response) {
+	req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	var resp apis.Response
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	return w.Code, resp
+}
+
+func TestUpdateApps(t *testing.T) {
+	s, handler, cleanup := newTestServer(t)
+	defer cleanup()
+
+	code, resp := doRequest(handler, http.MethodPut, "/api/envs/default/apps/myapp", `name: myapp
+services:
+  web:
+    type: webservice
+    image: nginx:1.19
+`)
+	assert.Equal(t, http.StatusOK, code, resp.Data)
+	var comp v1alpha2.Component
+	assert.NoError(t, s.KubeClient.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "web"}, &comp))
+
+	code, resp = doRequest(handler, http.MethodPut, "/api/envs/default/apps/other", `name: myapp
+services:
+  web:
+    type: webservice
+    image: nginx:1.19
+`)
+	assert.Equal(t, http.StatusInternalServerError, code)
+	assert.Equal(t, "name 'myapp' in appfile doesn't match the app 'other' to update", resp.Data)
+}
+
+func TestUpdateComponent(t *testing.T) {
+	s, handler, cleanup := newTestServer(t)
+	defer cleanup()
+	code, resp := doRequest(handler, http.MethodPut, "/api/envs/default/apps/myapp", `name: myapp
+services:
+  web:
+    type: webservice
+    image: nginx:1.19
+  api:
+    type: webservice
+    image: api:v1
+`)
+	assert.Equal(t, http.StatusOK, code, resp.Data)
+	var web, api v1alpha2.Component
+	assert.NoError(t, s.KubeClient.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "web"}, &web))
+	assert.NoError(t, s.KubeClient.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "api"}, &api))
+
+	code, resp = doRequest(handler, http.MethodPut, "/api/envs/default/apps/myapp/components/web",
+		`{"workload":{"image":"nginx:1.20"},"resourceVersion":"`+web.ResourceVersion+`"}`)
+	assert.Equal(t, http.StatusOK, code, resp.Data)
+	var updated v1alpha2.Component
+	assert.NoError(t, s.KubeClient.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "web"}, &updated))
+	assert.NotEqual(t, web.ResourceVersion, updated.ResourceVersion)
+	assert.Contains(t, string(updated.Spec.Workload.Raw), "nginx:1.20")
+
+	// the stale version is rejected before anything is written, including other components of the app
+	assert.NoError(t, s.KubeClient.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "api"}, &api))
+	code, resp = doRequest(handler, http.MethodPut, "/api/envs/default/apps/myapp/components/web",
+		`{"workload":{"image":"nginx:1.21"},"resourceVersion":"`+web.ResourceVersion+`"}`)
+	assert.Equal(t, http.StatusConflict, code)
+	assert.Equal(t, "component web has been modified, please get the latest version and try again", resp.Data)
+	var unchanged v1alpha2.Component
+	assert.NoError(t, s.KubeClient.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "api"}, &unchanged))
+	assert.Equal(t, api.ResourceVersion, unchanged.ResourceVersion)
+	assert.NoError(t, s.KubeClient.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "web"}, &unchanged))
+	assert.Equal(t, updated.ResourceVersion, unchanged.ResourceVersion)
+
+	code, resp = doRequest(handler, http.MethodPut, "/api/envs/default/apps/myapp/components/db",
+		`{"workload":{"image":"mysql"}}`)
+	assert.Equal(t, http.StatusNotFound, code)
+	assert.Equal(t, "service db not found in app myapp", resp.Data)
+	code, resp = doRequest(handler, http.MethodPut, "/api/envs/default/apps/other/components/web",
+		`{"workload":{"image":"mysql"}}`)
+	assert.Equal(t, http.StatusNotFound, code)
+	assert.Equal(t, "app other not found in env default", resp.Data)
+
+	code, resp = doRequest(handler, http.MethodPut, "/api/envs/default/apps/myapp/components/web", `{"workload":`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, "the component update request body is invalid", resp.Data)
+	code, resp = doRequest(handler, http.MethodPut, "/api/envs/default/apps/myapp/components/web",
+		`{"workload":{"port":"http"}}`)
+	assert.Equal(t, http.StatusBadRequest, code, resp.Data)
+	assert.NoError(t, s.KubeClient.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "web"}, &unchanged))
+	assert.Equal(t, updated.ResourceVersion, unchanged.ResourceVersion)
+}
+
+func TestUpdateAppsRejectsLocalSecrets(t *testing.T) {
+	s, handler, cleanup := newTestServer(t)
+	defer cleanup()
+
+	for source, msg := range map[string]string{
+		"env:HOME":         "secret token: env sources are only allowed when deploying from the CLI",
+		"file:/etc/passwd": "secret token: file sources are only allowed when deploying from the CLI",
+	} {
+		code, resp := doRequest(handler, http.MethodPut, "/api/envs/default/apps/myapp", `name: myapp
+services:
+  web:
+    type: webservice
+    image: nginx:1.19
+secrets:
+  token: `+source+`
+`)
+		assert.Equal(t, http.StatusInternalServerError, code)
+		assert.Equal(t, msg, resp.Data)
+		var secrets corev1.SecretList
+		assert.NoError(t, s.KubeClient.List(context.Background(), &secrets))
+		assert.Empty(t, secrets.Items)
+	}
+}
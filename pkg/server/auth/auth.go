@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DefaultUsernameClaim is the JWT claim used as user name if not specified
+const DefaultUsernameClaim = "sub"
+
+// DefaultGroupsClaim is the JWT claim used as user groups if not specified
+const DefaultGroupsClaim = "groups"
+
+// ErrTokenNotRecognized means the authenticator doesn't know the token, the next one should have a try
+var ErrTokenNotRecognized = errors.New("token not recognized")
+
+// UserInfo is the identity of the caller of API server
+type UserInfo struct {
+	Name   string
+	Groups []string
+}
+
+// Authenticator authenticates a bearer token and returns the user it belongs to
+type Authenticator interface {
+	AuthenticateToken(ctx context.Context, token string) (*UserInfo, error)
+}
+
+// Options configures authentication and authorization of the API server
+type Options struct {
+	// TokenFile is a csv file of static bearer tokens, in format of `token,user,uid,"group1,group2"`
+	TokenFile string
+	// TokenReview will validate bearer tokens against the Kubernetes TokenReview API
+	TokenReview bool
+	// OIDCIssuerURL is the issuer the OIDC ID tokens must be issued by
+	OIDCIssuerURL string
+	// OIDCClientID is the audience the OIDC ID tokens must be issued for
+	OIDCClientID string
+	// OIDCJWKSFile is a JSON Web Key Set file containing the keys to verify OIDC ID tokens
+	OIDCJWKSFile string
+	// OIDCUsernameClaim is the claim used as user name, defaults to `sub`
+	OIDCUsernameClaim string
+	// OIDCGroupsClaim is the claim used as user groups, defaults to `groups`
+	OIDCGroupsClaim string
+	// PolicyFile maps users and groups to envs and verbs they are allowed to access
+	PolicyFile string
+}
+
+// Enabled returns whether any authenticator is configured
+func (o Options) Enabled() bool {
+	return o.TokenFile != "" || o.TokenReview || o.OIDCJWKSFile != ""
+}
+
+// NewAuthenticator builds the authenticators configured in options, tokens will be checked by them in order
+func NewAuthenticator(o Options, c client.Client) (Authenticator, error) {
+	var authenticators unionAuthenticator
+	if o.TokenFile != "" {
+		a, err := NewStaticTokenAuthenticator(o.TokenFile)
+		if err != nil {
+			return nil, err
+		}
+		authenticators = append(authenticators, a)
+	}
+	if o.OIDCJWKSFile != "" {
+		if o.OIDCIssuerURL == "" || o.OIDCClientID == "" {
+			return nil, errors.New("OIDC issuer URL and client ID are required to validate OIDC tokens")
+		}
+		a, err := NewOIDCAuthenticator(o.OIDCIssuerURL, o.OIDCClientID, o.OIDCJWKSFile, o.OIDCUsernameClaim, o.OIDCGroupsClaim)
+		if err != nil {
+			return nil, err
+		}
+		authenticators = append(authenticators, a)
+	}
+	if o.TokenReview {
+		authenticators = append(authenticators, NewTokenReviewAuthenticator(c))
+	}
+	if len(authenticators) == 0 {
+		return nil, errors.New("no authenticator is configured")
+	}
+	return authenticators, nil
+}
+
+type unionAuthenticator []Authenticator
+
+// AuthenticateToken tries authenticators one by one until one of them recognizes the token
+func (u unionAuthenticator) AuthenticateToken(ctx context.Context, token string) (*UserInfo, error) {
+	var errs []error
+	for _, a := range u {
+		user, err := a.AuthenticateToken(ctx, token)
+		if err == nil {
+			return user, nil
+		}
+		if !errors.Is(err, ErrTokenNotRecognized) {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) != 0 {
+		return nil, fmt.Errorf("authenticate token err %v", errs)
+	}
+	return nil, ErrTokenNotRecognized
+}
@@ -0,0 +1,127 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStaticTokenAuthenticator(t *testing.T) {
+	a, err := loadStaticTokens(strings.NewReader(`# comment
+token-a,alice,1001,"dev,ops"
+token-b,bob,1002
+`))
+	assert.NoError(t, err)
+
+	user, err := a.AuthenticateToken(context.Background(), "token-a")
+	assert.NoError(t, err)
+	assert.Equal(t, &UserInfo{Name: "alice", Groups: []string{"dev", "ops"}}, user)
+
+	user, err = a.AuthenticateToken(context.Background(), "token-b")
+	assert.NoError(t, err)
+	assert.Equal(t, &UserInfo{Name: "bob"}, user)
+
+	_, err = a.AuthenticateToken(context.Background(), "token-c")
+	assert.Equal(t, ErrTokenNotRecognized, err)
+
+	_, err = loadStaticTokens(strings.NewReader("token-a,alice\n"))
+	assert.Error(t, err)
+	_, err = loadStaticTokens(strings.NewReader("token-a,alice,1\ntoken-a,bob,2\n"))
+	assert.Error(t, err)
+}
+
+func TestOIDCAuthenticator(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	jwks, err := json.Marshal(jsonWebKeySet{Keys: []jsonWebKey{{
+		Kid: "key1",
+		Kty: "RSA",
+		Use: "sig",
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}}})
+	assert.NoError(t, err)
+	dir, err := ioutil.TempDir("", "oidc")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	jwksFile := filepath.Join(dir, "jwks.json")
+	assert.NoError(t, ioutil.WriteFile(jwksFile, jwks, 0600))
+
+	a, err := NewOIDCAuthenticator("https://issuer.example.com", "vela", jwksFile, "email", "")
+	assert.NoError(t, err)
+
+	sign := func(claims jwt.MapClaims, kid string) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = kid
+		s, err := token.SignedString(key)
+		assert.NoError(t, err)
+		return s
+	}
+	valid := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":    "https://issuer.example.com",
+			"aud":    []string{"vela", "other"},
+			"email":  "alice@example.com",
+			"groups": []string{"dev"},
+			"exp":    time.Now().Add(time.Hour).Unix(),
+		}
+	}
+
+	user, err := a.AuthenticateToken(context.Background(), sign(valid(), "key1"))
+	assert.NoError(t, err)
+	assert.Equal(t, &UserInfo{Name: "alice@example.com", Groups: []string{"dev"}}, user)
+
+	_, err = a.AuthenticateToken(context.Background(), "opaque-token")
+	assert.Equal(t, ErrTokenNotRecognized, err)
+
+	cases := map[string]func(jwt.MapClaims){
+		"expired":        func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Hour).Unix() },
+		"wrong issuer":   func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" },
+		"wrong audience": func(c jwt.MapClaims) { c["aud"] = "other" },
+		"no user name":   func(c jwt.MapClaims) { delete(c, "email") },
+		"no expiry":      func(c jwt.MapClaims) { delete(c, "exp") },
+	}
+	for name, modify := range cases {
+		claims := valid()
+		modify(claims)
+		_, err = a.AuthenticateToken(context.Background(), sign(claims, "key1"))
+		assert.Error(t, err, name)
+	}
+	_, err = a.AuthenticateToken(context.Background(), sign(valid(), "key2"))
+	assert.Error(t, err, "unknown key")
+}
+
+func TestPolicy(t *testing.T) {
+	p := &Policy{Rules: []PolicyRule{
+		{Users: []string{"alice"}, Envs: []string{Wildcard}, Verbs: []string{Wildcard}},
+		{Groups: []string{"dev"}, Envs: []string{"test"}, Verbs: []string{VerbGet, VerbCreate, VerbUpdate}},
+	}}
+	alice := &UserInfo{Name: "alice"}
+	bob := &UserInfo{Name: "bob", Groups: []string{"dev"}}
+	carol := &UserInfo{Name: "carol"}
+
+	assert.True(t, p.Allowed(alice, "prod", VerbDelete))
+	assert.True(t, p.Allowed(alice, "", VerbCreate))
+	assert.True(t, p.Allowed(bob, "test", VerbUpdate))
+	assert.False(t, p.Allowed(bob, "test", VerbDelete))
+	assert.False(t, p.Allowed(bob, "prod", VerbGet))
+	assert.False(t, p.Allowed(bob, "", VerbGet))
+	assert.False(t, p.Allowed(carol, "test", VerbGet))
+
+	var nilPolicy *Policy
+	assert.True(t, nilPolicy.Allowed(carol, "prod", VerbGet))
+	assert.False(t, nilPolicy.Allowed(carol, "prod", VerbDelete))
+	assert.False(t, nilPolicy.Allowed(carol, "prod", VerbCreate))
+}
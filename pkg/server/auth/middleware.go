@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/oam-dev/kubevela/pkg/server/util"
+)
+
+// UserKey is used as key to set/get the authenticated user in gin context
+const UserKey = "user"
+
+// Middleware authenticates the bearer token of requests and checks whether the user is allowed by policy
+func Middleware(authn Authenticator, policy *Policy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := bearerToken(c.Request.Header.Get("Authorization"))
+		if token == "" {
+			util.SetErrorAndAbort(c, util.Unauthorized, "bearer token is required")
+			return
+		}
+		user, err := authn.AuthenticateToken(util.GetContext(c), token)
+		if err != nil {
+			ctrl.Log.Info("authenticate request failed", "path", c.Request.URL.Path, "err", err.Error())
+			util.SetErrorAndAbort(c, util.Unauthorized, "invalid bearer token")
+			return
+		}
+		env := c.Param("envName")
+		verb := VerbOf(c.Request.Method)
+		if !policy.Allowed(user, env, verb) {
+			util.SetErrorAndAbort(c, util.Forbidden, user.Name, verb, env)
+			return
+		}
+		c.Set(UserKey, user)
+	}
+}
+
+// UserFrom returns the authenticated user of the request
+func UserFrom(c *gin.Context) (*UserInfo, bool) {
+	v, ok := c.Get(UserKey)
+	if !ok {
+		return nil, false
+	}
+	user, ok := v.(*UserInfo)
+	return user, ok
+}
+
+func bearerToken(header string) string {
+	parts := strings.SplitN(header, " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(parts[1])
+}
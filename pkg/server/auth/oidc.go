@@ -0,0 +1,209 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// OIDCAuthenticator validates OIDC ID tokens with the keys in a JSON Web Key Set file
+type OIDCAuthenticator struct {
+	issuer        string
+	clientID      string
+	usernameClaim string
+	groupsClaim   string
+	keys          map[string]interface{}
+}
+
+type jsonWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	// RSA keys
+	N string `json:"n"`
+	E string `json:"e"`
+	// EC keys
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+// NewOIDCAuthenticator creates an OIDCAuthenticator, the public keys are loaded from jwksFile
+func NewOIDCAuthenticator(issuer, clientID, jwksFile, usernameClaim, groupsClaim string) (*OIDCAuthenticator, error) {
+	data, err := ioutil.ReadFile(filepath.Clean(jwksFile))
+	if err != nil {
+		return nil, err
+	}
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse JWKS file %s err %w", jwksFile, err)
+	}
+	if usernameClaim == "" {
+		usernameClaim = DefaultUsernameClaim
+	}
+	if groupsClaim == "" {
+		groupsClaim = DefaultGroupsClaim
+	}
+	return &OIDCAuthenticator{
+		issuer:        issuer,
+		clientID:      clientID,
+		usernameClaim: usernameClaim,
+		groupsClaim:   groupsClaim,
+		keys:          keys,
+	}, nil
+}
+
+func parseJWKS(data []byte) (map[string]interface{}, error) {
+	var set jsonWebKeySet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+	keys := make(map[string]interface{})
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", k.Kid, err)
+		}
+		keys[k.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no signing key found")
+	}
+	return keys, nil
+}
+
+func (k jsonWebKey) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %s", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %s", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// AuthenticateToken verifies signature, issuer, audience and expiry of the ID token
+func (a *OIDCAuthenticator) AuthenticateToken(_ context.Context, token string) (*UserInfo, error) {
+	// opaque tokens are left to other authenticators
+	if strings.Count(token, ".") != 2 {
+		return nil, ErrTokenNotRecognized
+	}
+	claims := jwt.MapClaims{}
+	parser := &jwt.Parser{ValidMethods: []string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}}
+	_, err := parser.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		if key, ok := a.keys[kid]; ok {
+			return key, nil
+		}
+		// a single key can be used without kid
+		if kid == "" && len(a.keys) == 1 {
+			for _, key := range a.keys {
+				return key, nil
+			}
+		}
+		return nil, fmt.Errorf("signing key %q not found", kid)
+	})
+	if err != nil {
+		return nil, err
+	}
+	// the parser only checks `exp` if it's present, a token without expiry would be valid forever
+	if !claims.VerifyExpiresAt(time.Now().Unix(), true) {
+		return nil, errors.New("token has no valid expiry")
+	}
+	if iss, _ := claims["iss"].(string); iss != a.issuer {
+		return nil, fmt.Errorf("token issuer %q doesn't match %q", iss, a.issuer)
+	}
+	if !hasAudience(claims["aud"], a.clientID) {
+		return nil, fmt.Errorf("token is not issued for %s", a.clientID)
+	}
+	name, _ := claims[a.usernameClaim].(string)
+	if name == "" {
+		return nil, fmt.Errorf("claim %s is missing in token", a.usernameClaim)
+	}
+	// prefix user which isn't identified by email with issuer, so it can't collide with users of other authenticators
+	if a.usernameClaim != "email" {
+		name = a.issuer + "#" + name
+	}
+	user := &UserInfo{Name: name}
+	switch groups := claims[a.groupsClaim].(type) {
+	case string:
+		user.Groups = []string{groups}
+	case []interface{}:
+		for _, g := range groups {
+			if s, ok := g.(string); ok {
+				user.Groups = append(user.Groups, s)
+			}
+		}
+	}
+	return user, nil
+}
+
+// hasAudience checks the `aud` claim which can be either a string or an array
+func hasAudience(aud interface{}, clientID string) bool {
+	switch aud := aud.(type) {
+	case string:
+		return aud == clientID
+	case []interface{}:
+		for _, a := range aud {
+			if a == clientID {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+
+	"github.com/ghodss/yaml"
+)
+
+// Verbs of API server requests
+const (
+	VerbGet    = "get"
+	VerbCreate = "create"
+	VerbUpdate = "update"
+	VerbDelete = "delete"
+	// Wildcard matches all verbs, envs, users or groups in a policy rule
+	Wildcard = "*"
+)
+
+// Policy decides which envs and verbs users are allowed to access
+type Policy struct {
+	Rules []PolicyRule `json:"rules"`
+}
+
+// PolicyRule allows the listed users and groups to perform verbs in the listed envs.
+// Requests which are not bound to an env, e.g. capabilities, are only allowed by rules with env `*`.
+type PolicyRule struct {
+	Users  []string `json:"users,omitempty"`
+	Groups []string `json:"groups,omitempty"`
+	Envs   []string `json:"envs"`
+	Verbs  []string `json:"verbs"`
+}
+
+// LoadPolicy reads policy from a yaml file
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := ioutil.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	var p Policy
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Allowed returns whether the user can perform the verb in env, a nil policy only allows reading
+func (p *Policy) Allowed(user *UserInfo, env, verb string) bool {
+	if p == nil {
+		return verb == VerbGet
+	}
+	for _, r := range p.Rules {
+		if r.matchSubject(user) && contains(r.Envs, env) && contains(r.Verbs, verb) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r PolicyRule) matchSubject(user *UserInfo) bool {
+	if contains(r.Users, user.Name) {
+		return true
+	}
+	for _, g := range user.Groups {
+		if contains(r.Groups, g) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, l := range list {
+		if l == Wildcard || l == s {
+			return true
+		}
+	}
+	return false
+}
+
+// VerbOf maps HTTP method to verb of the request
+func VerbOf(method string) string {
+	switch method {
+	case http.MethodPost:
+		return VerbCreate
+	case http.MethodPut, http.MethodPatch:
+		return VerbUpdate
+	case http.MethodDelete:
+		return VerbDelete
+	default:
+		return VerbGet
+	}
+}
@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// StaticTokenAuthenticator authenticates bearer tokens listed in a csv file
+type StaticTokenAuthenticator struct {
+	tokens map[string]*UserInfo
+}
+
+// NewStaticTokenAuthenticator loads tokens from a csv file, each line is in format of `token,user,uid,"group1,group2"`,
+// groups are optional.
+func NewStaticTokenAuthenticator(path string) (*StaticTokenAuthenticator, error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	//nolint:errcheck
+	defer f.Close()
+	return loadStaticTokens(f)
+}
+
+func loadStaticTokens(r io.Reader) (*StaticTokenAuthenticator, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.Comment = '#'
+	tokens := make(map[string]*UserInfo)
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(record) < 3 {
+			return nil, fmt.Errorf("token file line %d: token, user and uid are required", line)
+		}
+		token := strings.TrimSpace(record[0])
+		if token == "" {
+			return nil, fmt.Errorf("token file line %d: token is empty", line)
+		}
+		if _, ok := tokens[token]; ok {
+			return nil, fmt.Errorf("token file line %d: duplicate token", line)
+		}
+		user := &UserInfo{Name: strings.TrimSpace(record[1])}
+		if len(record) > 3 {
+			for _, g := range strings.Split(record[3], ",") {
+				if g = strings.TrimSpace(g); g != "" {
+					user.Groups = append(user.Groups, g)
+				}
+			}
+		}
+		tokens[token] = user
+	}
+	return &StaticTokenAuthenticator{tokens: tokens}, nil
+}
+
+// AuthenticateToken looks up the user of the token
+func (a *StaticTokenAuthenticator) AuthenticateToken(_ context.Context, token string) (*UserInfo, error) {
+	for t, user := range a.tokens {
+		if subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+			return user, nil
+		}
+	}
+	return nil, ErrTokenNotRecognized
+}
@@ -0,0 +1,33 @@
+package auth
+
+import (
+	"context"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// TokenReviewAuthenticator authenticates bearer tokens, e.g. service account tokens, by the Kubernetes TokenReview API
+type TokenReviewAuthenticator struct {
+	c client.Client
+}
+
+// NewTokenReviewAuthenticator creates a TokenReviewAuthenticator
+func NewTokenReviewAuthenticator(c client.Client) *TokenReviewAuthenticator {
+	return &TokenReviewAuthenticator{c: c}
+}
+
+// AuthenticateToken asks Kubernetes whom the token belongs to
+func (a *TokenReviewAuthenticator) AuthenticateToken(ctx context.Context, token string) (*UserInfo, error) {
+	review := &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{Token: token},
+	}
+	if err := a.c.Create(ctx, review); err != nil {
+		return nil, err
+	}
+	// tokens which are not issued for Kubernetes also end up with an error in status, so just skip them
+	if !review.Status.Authenticated {
+		return nil, ErrTokenNotRecognized
+	}
+	return &UserInfo{Name: review.Status.User.Username, Groups: review.Status.User.Groups}, nil
+}
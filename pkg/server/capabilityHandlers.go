@@ -45,7 +45,7 @@ func (s *APIServer) SyncCapabilityCenter(c *gin.Context) {
 // AddCapabilityIntoCluster adds specific capability into cluster
 func (s *APIServer) AddCapabilityIntoCluster(c *gin.Context) {
 	cap := c.Param("capabilityCenterName") + "/" + c.Param("capabilityName")
-	msg, err := oam.AddCapabilityIntoCluster(s.KubeClient, s.dm, cap)
+	msg, err := oam.AddCapabilityIntoCluster(s.kubeClient(c), s.dm, cap)
 	if err != nil {
 		util.HandleError(c, util.StatusInternalServerError)
 		return
@@ -63,7 +63,7 @@ func (s *APIServer) DeleteCapabilityCenter(c *gin.Context) {
 // RemoveCapabilityFromCluster remove a specific capability from cluster
 func (s *APIServer) RemoveCapabilityFromCluster(c *gin.Context) {
 	capabilityCenterName := c.Param("capabilityName")
-	msg, err := oam.RemoveCapabilityFromCluster(s.KubeClient, capabilityCenterName)
+	msg, err := oam.RemoveCapabilityFromCluster(s.kubeClient(c), capabilityCenterName)
 	if err != nil {
 		util.HandleError(c, util.StatusInternalServerError, err.Error())
 		return
@@ -4,9 +4,11 @@ import (
 	"os"
 
 	"github.com/gin-gonic/gin"
+	ctrl "sigs.k8s.io/controller-runtime"
 
 	cmdutil "github.com/oam-dev/kubevela/pkg/commands/util"
 	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/server/apis"
 	"github.com/oam-dev/kubevela/pkg/server/util"
 	"github.com/oam-dev/kubevela/pkg/utils/env"
 )
@@ -23,7 +25,7 @@ func (s *APIServer) GetComponent(c *gin.Context) {
 	applicationName := c.Param("appName")
 	componentName := c.Param("compName")
 	ctx := util.GetContext(c)
-	componentMeta, err := oam.RetrieveComponent(ctx, s.KubeClient, applicationName, componentName, namespace)
+	componentMeta, err := oam.RetrieveComponent(ctx, s.kubeClient(c), applicationName, componentName, namespace)
 	if err != nil {
 		util.HandleError(c, util.StatusInternalServerError, err)
 		return
@@ -31,6 +33,39 @@ func (s *APIServer) GetComponent(c *gin.Context) {
 	util.AssembleResponse(c, componentMeta, nil)
 }
 
+// UpdateComponent updates workload fields and traits of a component
+func (s *APIServer) UpdateComponent(c *gin.Context) {
+	envName := c.Param("envName")
+	envMeta, err := env.GetEnvByName(envName)
+	if err != nil {
+		util.HandleError(c, util.StatusInternalServerError, err)
+		return
+	}
+	appName := c.Param("appName")
+	componentName := c.Param("compName")
+	var body apis.ComponentBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		util.HandleErrorWithStatus(c, util.InvalidArgument, "the component update request body is invalid")
+		return
+	}
+	ctrl.Log.Info("Update component request", "env", envName, "app", appName, "component", componentName)
+
+	ctx := util.GetContext(c)
+	componentMeta, err := oam.UpdateComponent(ctx, s.kubeClient(c), envMeta, appName, componentName, body,
+		cmdutil.IOStreams{In: os.Stdin, Out: os.Stdout, ErrOut: os.Stderr})
+	// conflicts, invalid updates and unknown components are told from server failures by status codes
+	switch oam.GetComponentErrorReason(err) {
+	case oam.ComponentConflict:
+		util.HandleErrorWithStatus(c, util.Conflict, err.Error())
+	case oam.ComponentInvalid:
+		util.HandleErrorWithStatus(c, util.InvalidArgument, err.Error())
+	case oam.ComponentNotFound:
+		util.HandleErrorWithStatus(c, util.NotFound, err.Error())
+	default:
+		util.AssembleResponse(c, componentMeta, err)
+	}
+}
+
 // DeleteComponent deletes a component from cluster
 func (s *APIServer) DeleteComponent(c *gin.Context) {
 	envName := c.Param("envName")
@@ -43,10 +78,12 @@ func (s *APIServer) DeleteComponent(c *gin.Context) {
 	componentName := c.Param("compName")
 
 	o := oam.DeleteOptions{
-		Client:   s.KubeClient,
+		Client:   s.kubeClient(c),
 		Env:      envMeta,
 		AppName:  appName,
-		CompName: componentName}
+		CompName: componentName,
+		// the API server can't read files or env vars of its own into secrets of the app
+		DisallowLocalSources: true}
 
 	message, err := o.DeleteComponent(
 		cmdutil.IOStreams{In: os.Stdin, Out: os.Stdout, ErrOut: os.Stderr})
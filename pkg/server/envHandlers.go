@@ -25,7 +25,7 @@ func (s *APIServer) CreateEnv(c *gin.Context) {
 	}
 
 	ctx := util.GetContext(c)
-	message, err := env.CreateEnv(ctx, s.KubeClient, name, &types.EnvMeta{
+	message, err := env.CreateEnv(ctx, s.kubeClient(c), name, &types.EnvMeta{
 		Name:      name,
 		Current:   environment.Current,
 		Namespace: namespace,
@@ -45,7 +45,7 @@ func (s *APIServer) UpdateEnv(c *gin.Context) {
 		return
 	}
 	ctx := util.GetContext(c)
-	message, err := env.UpdateEnv(ctx, s.KubeClient, envName, environmentBody.Namespace)
+	message, err := env.UpdateEnv(ctx, s.kubeClient(c), envName, environmentBody.Namespace)
 	util.AssembleResponse(c, message, err)
 }
 
@@ -53,15 +53,13 @@ func (s *APIServer) UpdateEnv(c *gin.Context) {
 func (s *APIServer) GetEnv(c *gin.Context) {
 	envName := c.Param("envName")
 	ctrl.Log.Info("Get a get environment request", "envName", envName)
-	envList, err := env.ListEnvs(envName)
+	ctx := util.GetContext(c)
+	kubeClient := s.kubeClient(c)
+	envList, err := env.ListEnvs(ctx, kubeClient, envName)
 
 	environmentList := make([]apis.Environment, 0)
 	for _, envMeta := range envList {
-		environmentList = append(environmentList, apis.Environment{
-			EnvName:   envMeta.Name,
-			Namespace: envMeta.Namespace,
-			Current:   envMeta.Current,
-		})
+		environmentList = append(environmentList, env.DescribeEnv(ctx, kubeClient, envMeta))
 	}
 	util.AssembleResponse(c, environmentList, err)
 }
@@ -75,7 +73,7 @@ func (s *APIServer) ListEnv(c *gin.Context) {
 func (s *APIServer) DeleteEnv(c *gin.Context) {
 	envName := c.Param("envName")
 	ctrl.Log.Info("Delete a delete environment request", "envName", envName)
-	msg, err := env.DeleteEnv(envName)
+	msg, err := env.DeleteEnv(util.GetContext(c), s.kubeClient(c), envName)
 	util.AssembleResponse(c, msg, err)
 }
 
@@ -11,6 +11,7 @@ import (
 	"github.com/oam-dev/kubevela/pkg/utils/common"
 
 	"github.com/oam-dev/kubevela/pkg/server"
+	"github.com/oam-dev/kubevela/pkg/server/auth"
 	"github.com/oam-dev/kubevela/pkg/server/util"
 
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -33,7 +34,7 @@ func main() {
 		ctrl.Log.Error(err, "failed to init Kubernetes Config")
 		os.Exit(1)
 	}
-	apiServer, err := server.New(c, util.DefaultAPIServerPort, "")
+	apiServer, err := server.New(c, util.DefaultAPIServerPort, "", auth.Options{})
 	if err != nil {
 		ctrl.Log.Error(err, "failed to init dashboard server")
 		os.Exit(1)
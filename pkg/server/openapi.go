@@ -0,0 +1,282 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/oam-dev/kubevela/version"
+)
+
+// OpenAPIVersion is the version of OpenAPI specification the document follows
+const OpenAPIVersion = "3.0.3"
+
+// OpenAPI is the OpenAPI document of the API server, only the parts in use are defined
+type OpenAPI struct {
+	OpenAPI    string                                 `json:"openapi"`
+	Info       OpenAPIInfo                            `json:"info"`
+	Paths      map[string]map[string]OpenAPIOperation `json:"paths"`
+	Components OpenAPIComponents                      `json:"components"`
+}
+
+// OpenAPIInfo is the metadata of API
+type OpenAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// OpenAPIOperation describes one API operation on a path
+type OpenAPIOperation struct {
+	OperationID string                     `json:"operationId"`
+	Summary     string                     `json:"summary,omitempty"`
+	Tags        []string                   `json:"tags,omitempty"`
+	Parameters  []OpenAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *OpenAPIRequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]OpenAPIResponse `json:"responses"`
+}
+
+// OpenAPIParameter describes a path parameter
+type OpenAPIParameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *Schema `json:"schema"`
+}
+
+// OpenAPIRequestBody describes the request body of an operation
+type OpenAPIRequestBody struct {
+	Required bool                        `json:"required"`
+	Content  map[string]OpenAPIMediaType `json:"content"`
+}
+
+// OpenAPIResponse describes a response of an operation
+type OpenAPIResponse struct {
+	Description string                      `json:"description"`
+	Content     map[string]OpenAPIMediaType `json:"content,omitempty"`
+}
+
+// OpenAPIMediaType holds the schema of a media type
+type OpenAPIMediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// OpenAPIComponents holds reusable schemas
+type OpenAPIComponents struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+// Schema is a JSON schema object of OpenAPI
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"`
+}
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	metaTimeType  = reflect.TypeOf(metav1.Time{})
+	marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// BuildOpenAPI generates the OpenAPI document from operations, schemas are reflected from the Go types
+func BuildOpenAPI(ops []Operation) *OpenAPI {
+	doc := &OpenAPI{
+		OpenAPI:    OpenAPIVersion,
+		Info:       OpenAPIInfo{Title: "KubeVela API", Version: version.VelaVersion},
+		Paths:      make(map[string]map[string]OpenAPIOperation),
+		Components: OpenAPIComponents{Schemas: make(map[string]*Schema)},
+	}
+	g := &schemaGenerator{schemas: doc.Components.Schemas, names: make(map[reflect.Type]string)}
+	for _, op := range ops {
+		o := OpenAPIOperation{
+			OperationID: op.ID,
+			Summary:     op.Summary,
+			Tags:        []string{op.Tag},
+			Responses:   make(map[string]OpenAPIResponse),
+		}
+		var path []string
+		for _, seg := range strings.Split(op.Path, "/") {
+			if strings.HasPrefix(seg, ":") {
+				name := strings.TrimPrefix(seg, ":")
+				o.Parameters = append(o.Parameters, OpenAPIParameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"}})
+				seg = "{" + name + "}"
+			}
+			path = append(path, seg)
+		}
+		if op.Body != nil {
+			schema := g.schemaOf(op.Body)
+			content := make(map[string]OpenAPIMediaType)
+			for _, ct := range op.BodyContentTypes {
+				content[ct] = OpenAPIMediaType{Schema: schema}
+			}
+			o.RequestBody = &OpenAPIRequestBody{Required: true, Content: content}
+		}
+		switch {
+		case op.Raw:
+			o.Responses["200"] = OpenAPIResponse{Description: "OK",
+				Content: map[string]OpenAPIMediaType{"application/json": {Schema: &Schema{Type: "object"}}}}
+		case op.Stream:
+			o.Responses["200"] = OpenAPIResponse{Description: "Server-Sent Events, each data is in the schema",
+				Content: map[string]OpenAPIMediaType{"text/event-stream": {Schema: g.schemaOf(op.Response)}}}
+		default:
+			o.Responses["200"] = OpenAPIResponse{Description: "OK",
+				Content: map[string]OpenAPIMediaType{"application/json": {Schema: responseSchema(g, op.Response)}}}
+		}
+		o.Responses["500"] = OpenAPIResponse{Description: "Failed, data is the error message",
+			Content: map[string]OpenAPIMediaType{"application/json": {Schema: responseSchema(g, reflect.TypeOf(""))}}}
+		for _, status := range op.ErrorStatuses {
+			o.Responses[strconv.Itoa(status)] = OpenAPIResponse{Description: http.StatusText(status) + ", data is the error message",
+				Content: map[string]OpenAPIMediaType{"application/json": {Schema: responseSchema(g, reflect.TypeOf(""))}}}
+		}
+		p := strings.Join(path, "/")
+		if doc.Paths[p] == nil {
+			doc.Paths[p] = make(map[string]OpenAPIOperation)
+		}
+		doc.Paths[p][strings.ToLower(op.Method)] = o
+	}
+	return doc
+}
+
+// responseSchema is the schema of apis.Response whose data is in type t
+func responseSchema(g *schemaGenerator, t reflect.Type) *Schema {
+	s := &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"code": {Type: "integer"},
+		},
+		Required: []string{"code", "data"},
+	}
+	if t != nil {
+		s.Properties["data"] = g.schemaOf(t)
+	} else {
+		s.Properties["data"] = &Schema{}
+	}
+	return s
+}
+
+type schemaGenerator struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+}
+
+func (g *schemaGenerator) schemaOf(t reflect.Type) *Schema {
+	if t == timeType || t == metaTimeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+	if t.Kind() != reflect.Interface && (t.Implements(marshalerType) || reflect.PtrTo(t).Implements(marshalerType)) {
+		// types with customized json format, e.g. runtime.RawExtension, can be any value
+		return &Schema{}
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return g.schemaOf(t.Elem())
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		name := g.nameOf(t)
+		if _, ok := g.schemas[name]; !ok {
+			// placeholder to stop recursion of self-referenced types
+			g.schemas[name] = &Schema{}
+			*g.schemas[name] = *g.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	default:
+		// interface{} can be any value
+		return &Schema{}
+	}
+}
+
+func (g *schemaGenerator) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" && !f.Anonymous {
+			continue
+		}
+		name, opts := parseJSONTag(f.Tag.Get("json"))
+		if name == "-" {
+			continue
+		}
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		// fields of embedded or inlined structs are promoted
+		if (f.Anonymous && name == "" || strings.Contains(opts, "inline")) && ft.Kind() == reflect.Struct {
+			embedded := g.structSchema(ft)
+			for k, v := range embedded.Properties {
+				s.Properties[k] = v
+			}
+			s.Required = append(s.Required, embedded.Required...)
+			continue
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		s.Properties[name] = g.schemaOf(f.Type)
+		if !strings.Contains(opts, "omitempty") && f.Type.Kind() != reflect.Ptr {
+			s.Required = append(s.Required, name)
+		}
+	}
+	return s
+}
+
+// nameOf names the schema of type t by its type name, the package name is prefixed if names conflict
+func (g *schemaGenerator) nameOf(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+	name := t.Name()
+	for other, n := range g.names {
+		if n == name && other != t {
+			pkg := t.PkgPath()
+			name = pkg[strings.LastIndex(pkg, "/")+1:] + "." + name
+			break
+		}
+	}
+	g.names[t] = name
+	return name
+}
+
+func parseJSONTag(tag string) (string, string) {
+	if idx := strings.Index(tag, ","); idx != -1 {
+		return tag[:idx], tag[idx+1:]
+	}
+	return tag, ""
+}
+
+// GetOpenAPI returns the OpenAPI document of all routes
+func (s *APIServer) GetOpenAPI(c *gin.Context) {
+	c.JSON(http.StatusOK, BuildOpenAPI(s.operations))
+}
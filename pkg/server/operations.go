@@ -0,0 +1,183 @@
+package server
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/appfile"
+	"github.com/oam-dev/kubevela/pkg/plugins"
+	"github.com/oam-dev/kubevela/pkg/server/apis"
+	"github.com/oam-dev/kubevela/pkg/server/util"
+)
+
+// Operation describes one route of the API server, it's the source of both the OpenAPI document and the Go client
+type Operation struct {
+	// ID is unique among operations, it's used as the operationId in OpenAPI and method name in Go client
+	ID      string
+	Method  string
+	Path    string
+	Summary string
+	Tag     string
+	// Body is the type of request body, nil if there's none
+	Body reflect.Type
+	// BodyContentTypes are media types the body can be encoded in
+	BodyContentTypes []string
+	// Response is the type of `data` in the response
+	Response reflect.Type
+	// Stream means the response is a Server-Sent Events stream of Response
+	Stream bool
+	// Raw means the response is not wrapped in apis.Response
+	Raw bool
+	// ErrorStatuses are HTTP status codes of errors caused by the request, other errors are 500
+	ErrorStatuses []int
+}
+
+type operationDoc struct {
+	id      string
+	summary string
+	body    interface{}
+	// contentTypes of body, defaults to json
+	contentTypes []string
+	response     interface{}
+	stream       bool
+	raw          bool
+	errors       []int
+}
+
+// operationDocs documents every route registered in setupRoute, keyed by method and path without trailing slash.
+// TestOperationsDocumented makes sure it's in sync with the routes.
+var operationDocs = map[string]operationDoc{
+	"POST /api/envs":              {id: "CreateEnv", summary: "Create an environment", body: apis.Environment{}, response: ""},
+	"PUT /api/envs/:envName":      {id: "UpdateEnv", summary: "Update namespace of an environment", body: apis.EnvironmentBody{}, response: ""},
+	"GET /api/envs/:envName":      {id: "GetEnv", summary: "Get an environment", response: []apis.Environment{}},
+	"GET /api/envs":               {id: "ListEnv", summary: "List all environments", response: []apis.Environment{}},
+	"DELETE /api/envs/:envName":   {id: "DeleteEnv", summary: "Delete an environment", response: ""},
+	"PATCH /api/envs/:envName":    {id: "SetEnv", summary: "Set an environment as the current one", response: ""},
+	"GET /api/envs/:envName/apps": {id: "ListApps", summary: "List applications in an environment", response: []apis.ApplicationMeta{}},
+	"GET /api/envs/:envName/apps/:appName": {id: "GetApp", summary: "Get an application with status of its components",
+		response: apis.ApplicationMeta{}},
+	"GET /api/envs/:envName/apps/:appName/events": {id: "WatchApp", summary: "Stream status changes and Kubernetes Events of an application",
+		response: apis.AppEvent{}, stream: true},
+	"PUT /api/envs/:envName/apps/:appName": {id: "UpdateApp", summary: "Deploy an application with an Appfile in YAML or JSON",
+		body: appfile.AppFile{}, contentTypes: []string{util.ContentTypeJSON, util.ContentTypeYAML, util.ContentTypeTextYAML},
+		response: apis.ApplicationMeta{}},
+	"DELETE /api/envs/:envName/apps/:appName": {id: "DeleteApp", summary: "Delete an application", response: ""},
+	"GET /api/envs/:envName/apps/:appName/components": {id: "ListComponents", summary: "Get an application with its components",
+		response: apis.ApplicationMeta{}},
+	"GET /api/envs/:envName/apps/:appName/components/:compName": {id: "GetComponent", summary: "Get a component of an application",
+		response: apis.ComponentMeta{}},
+	"PUT /api/envs/:envName/apps/:appName/components/:compName": {id: "UpdateComponent", summary: "Update workload fields and traits of a component",
+		body: apis.ComponentBody{}, response: apis.ComponentMeta{},
+		errors: []int{http.StatusBadRequest, http.StatusNotFound, http.StatusConflict}},
+	"DELETE /api/envs/:envName/apps/:appName/components/:compName": {id: "DeleteComponent", summary: "Delete a component of an application",
+		response: ""},
+	"POST /api/envs/:envName/apps/:appName/components/:compName/traits": {id: "AttachTrait", summary: "Attach a trait to a component",
+		body: apis.TraitBody{}, response: ""},
+	"DELETE /api/envs/:envName/apps/:appName/components/:compName/traits/:traitName": {id: "DetachTrait", summary: "Detach a trait from a component",
+		response: ""},
+	"POST /api/envs/:envName/scopes":              {id: "CreateScope", summary: "Create a scope", body: apis.ScopeBody{}, response: ""},
+	"GET /api/envs/:envName/scopes/:scopeName":    {id: "GetScope", summary: "Get a scope", response: apis.ScopeMeta{}},
+	"PUT /api/envs/:envName/scopes/:scopeName":    {id: "UpdateScope", summary: "Update spec of a scope", body: apis.ScopeBody{}, response: ""},
+	"GET /api/envs/:envName/scopes":               {id: "ListScopes", summary: "List scopes in an environment", response: []apis.ScopeMeta{}},
+	"DELETE /api/envs/:envName/scopes/:scopeName": {id: "DeleteScope", summary: "Delete a scope", response: ""},
+	"POST /api/workloads":                         {id: "CreateWorkload", summary: "Run a workload", body: apis.WorkloadRunBody{}, response: ""},
+	"GET /api/workloads/:workloadName":            {id: "GetWorkload", summary: "Get an installed workload type", response: types.Capability{}},
+	"PUT /api/workloads/:workloadName":            {id: "UpdateWorkload", summary: "Not implemented yet"},
+	"GET /api/workloads":                          {id: "ListWorkloads", summary: "List installed workload types", response: []apis.WorkloadMeta{}},
+	"GET /api/traits/:traitName":                  {id: "GetTrait", summary: "Get an installed trait", response: types.Capability{}},
+	"GET /api/traits":                             {id: "ListTraits", summary: "List installed traits", response: []types.Capability{}},
+	"PUT /api/capability-centers": {id: "AddCapabilityCenter", summary: "Add a capability center and sync capabilities from it",
+		body: plugins.CapCenterConfig{}, response: ""},
+	"GET /api/capability-centers": {id: "ListCapabilityCenters", summary: "List capability centers",
+		response: []apis.CapabilityCenterMeta{}},
+	"DELETE /api/capability-centers/:capabilityCenterName": {id: "DeleteCapabilityCenter", summary: "Delete a capability center",
+		response: ""},
+	"PUT /api/capability-centers/:capabilityCenterName/capabilities": {id: "SyncCapabilityCenter",
+		summary: "Sync capabilities from a capability center", response: ""},
+	"PUT /api/capability-centers/:capabilityCenterName/capabilities/:capabilityName": {id: "AddCapabilityIntoCluster",
+		summary: "Install a capability from a capability center into cluster", response: ""},
+	"DELETE /api/capabilities/:capabilityName": {id: "RemoveCapabilityFromCluster", summary: "Uninstall a capability from cluster",
+		response: ""},
+	"DELETE /api/capabilities": {id: "RemoveCapabilityWithoutName", summary: "Same as RemoveCapabilityFromCluster with an empty name",
+		response: ""},
+	"GET /api/capabilities": {id: "ListCapabilities", summary: "List capabilities of all capability centers",
+		response: []types.Capability{}},
+	"GET /api/version":      {id: "GetVersion", summary: "Get version of the API server", response: map[string]string{}},
+	"GET /api/openapi.json": {id: "GetOpenAPI", summary: "Get this OpenAPI document", raw: true},
+}
+
+// Operations returns all operations of the API server sorted by path and method
+func Operations() []Operation {
+	s := &APIServer{}
+	s.setupRoute("")
+	return s.operations
+}
+
+// collectOperations maps registered routes to operations, routes only differ in the trailing slash are merged
+func collectOperations(routes gin.RoutesInfo) []Operation {
+	var ops []Operation
+	seen := make(map[string]bool)
+	for _, r := range routes {
+		key := operationKey(r.Method, r.Path)
+		doc, ok := operationDocs[key]
+		if !ok || seen[key] {
+			continue
+		}
+		seen[key] = true
+		op := Operation{
+			ID:            doc.id,
+			Method:        r.Method,
+			Path:          strings.TrimSuffix(r.Path, "/"),
+			Summary:       doc.summary,
+			Tag:           operationTag(r.Path),
+			Stream:        doc.stream,
+			Raw:           doc.raw,
+			ErrorStatuses: doc.errors,
+		}
+		if doc.body != nil {
+			op.Body = reflect.TypeOf(doc.body)
+			op.BodyContentTypes = doc.contentTypes
+			if len(op.BodyContentTypes) == 0 {
+				op.BodyContentTypes = []string{util.ContentTypeJSON}
+			}
+		}
+		if doc.response != nil {
+			op.Response = reflect.TypeOf(doc.response)
+		}
+		ops = append(ops, op)
+	}
+	sort.Slice(ops, func(i, j int) bool {
+		if ops[i].Path != ops[j].Path {
+			return ops[i].Path < ops[j].Path
+		}
+		return methodOrder(ops[i].Method) < methodOrder(ops[j].Method)
+	})
+	return ops
+}
+
+func operationKey(method, path string) string {
+	return method + " " + strings.TrimSuffix(path, "/")
+}
+
+// operationTag groups operations by the resource after `/api`, resources in an env are grouped by themselves, e.g. `apps`
+func operationTag(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(segments) > 3 && segments[1] == "envs" {
+		return segments[3]
+	}
+	return segments[1]
+}
+
+func methodOrder(method string) int {
+	for i, m := range []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete} {
+		if m == method {
+			return i
+		}
+	}
+	return len(method)
+}
@@ -0,0 +1,52 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOperationsDocumented(t *testing.T) {
+	s := &APIServer{}
+	router := s.setupRoute("")
+	registered := make(map[string]bool)
+	for _, r := range router.(interface{ Routes() gin.RoutesInfo }).Routes() {
+		key := operationKey(r.Method, r.Path)
+		registered[key] = true
+		_, ok := operationDocs[key]
+		assert.True(t, ok, "route %s is not documented in operationDocs", key)
+	}
+	ids := make(map[string]bool)
+	for key, doc := range operationDocs {
+		assert.True(t, registered[key], "operation %s is documented but not registered", key)
+		assert.False(t, ids[doc.id], "operation id %s is duplicated", doc.id)
+		ids[doc.id] = true
+	}
+}
+
+func TestBuildOpenAPI(t *testing.T) {
+	doc := BuildOpenAPI(Operations())
+	_, err := json.Marshal(doc)
+	assert.NoError(t, err)
+
+	op, ok := doc.Paths["/api/envs/{envName}/apps/{appName}/components/{compName}"]["put"]
+	assert.True(t, ok)
+	assert.Equal(t, "UpdateComponent", op.OperationID)
+	assert.Equal(t, []string{"apps"}, op.Tags)
+	assert.Len(t, op.Parameters, 3)
+	assert.Equal(t, "#/components/schemas/ComponentBody", op.RequestBody.Content["application/json"].Schema.Ref)
+
+	body := doc.Components.Schemas["ComponentBody"]
+	assert.Equal(t, "string", body.Properties["resourceVersion"].Type)
+	assert.Equal(t, "object", body.Properties["traits"].Type)
+
+	assert.Equal(t, "Conflict, data is the error message", op.Responses["409"].Description)
+
+	data := op.Responses["200"].Content["application/json"].Schema.Properties["data"]
+	assert.Equal(t, "#/components/schemas/ComponentMeta", data.Ref)
+	meta := doc.Components.Schemas["ComponentMeta"]
+	assert.Contains(t, meta.Required, "name")
+	assert.NotContains(t, meta.Properties, "AppConfig")
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/gin-contrib/static"
 	"github.com/gin-gonic/gin"
 
+	"github.com/oam-dev/kubevela/pkg/server/auth"
 	"github.com/oam-dev/kubevela/pkg/server/util"
 )
 
@@ -46,6 +47,9 @@ func (s *APIServer) setupRoute(staticPath string) http.Handler {
 	router.Use(util.ValidateHeaders())
 	// all requests start with /api
 	api := router.Group(util.RootPath)
+	if s.authn != nil {
+		api.Use(auth.Middleware(s.authn, s.policy), s.impersonate())
+	}
 	// env related operation
 	envs := api.Group(util.EnvironmentPath)
 	{
@@ -61,6 +65,7 @@ func (s *APIServer) setupRoute(staticPath string) http.Handler {
 		apps := envs.Group("/:envName/apps")
 		{
 			apps.GET("/:appName", s.GetApp)
+			apps.GET("/:appName/events", s.WatchApp)
 			apps.PUT("/:appName", s.UpdateApps)
 			apps.GET("/", s.ListApps)
 			apps.GET("", s.ListApps)
@@ -70,7 +75,7 @@ func (s *APIServer) setupRoute(staticPath string) http.Handler {
 			components := apps.Group("/:appName/components")
 			{
 				components.GET("/:compName", s.GetComponent)
-				components.PUT("/:compName", s.GetComponent)
+				components.PUT("/:compName", s.UpdateComponent)
 				components.GET("/", s.GetApp)
 				components.GET("", s.GetApp)
 				components.DELETE("/:compName", s.DeleteComponent)
@@ -82,6 +87,16 @@ func (s *APIServer) setupRoute(staticPath string) http.Handler {
 				}
 			}
 		}
+		// scope related operation
+		scopes := envs.Group("/:envName" + util.ScopeDefinitionPath)
+		{
+			scopes.POST("/", s.CreateScope)
+			scopes.GET("/:scopeName", s.GetScope)
+			scopes.PUT("/:scopeName", s.UpdateScope)
+			scopes.GET("/", s.ListScope)
+			scopes.GET("", s.ListScope)
+			scopes.DELETE("/:scopeName", s.DeleteScope)
+		}
 	}
 	// workload related api
 	workload := api.Group(util.WorkloadDefinitionPath)
@@ -99,16 +114,6 @@ func (s *APIServer) setupRoute(staticPath string) http.Handler {
 		trait.GET("/", s.ListTrait)
 		trait.GET("", s.ListTrait)
 	}
-	// scope related api
-	scopes := api.Group(util.ScopeDefinitionPath)
-	{
-		scopes.POST("/", s.CreateScope)
-		scopes.GET("/:scopeName", s.GetScope)
-		scopes.PUT("/:scopeName", s.UpdateScope)
-		scopes.GET("/", s.ListScope)
-		scopes.GET("", s.ListScope)
-		scopes.DELETE("/:scopeName", s.DeleteScope)
-	}
 
 	// capability center related api
 	capCenters := api.Group(util.CapabilityCenterPath)
@@ -136,8 +141,10 @@ func (s *APIServer) setupRoute(staticPath string) http.Handler {
 
 	// version
 	api.GET(util.VersionPath, s.GetVersion)
+	api.GET(util.OpenAPIPath, s.GetOpenAPI)
 	// default
 	router.NoRoute(util.NoRoute())
+	s.operations = collectOperations(router.Routes())
 
 	return router
 }
@@ -1,23 +1,94 @@
 package server
 
-import "github.com/gin-gonic/gin"
+import (
+	"github.com/gin-gonic/gin"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/server/apis"
+	"github.com/oam-dev/kubevela/pkg/server/util"
+	"github.com/oam-dev/kubevela/pkg/utils/env"
+)
 
 // CreateScope creates a scope
 func (s *APIServer) CreateScope(c *gin.Context) {
+	envMeta, err := env.GetEnvByName(c.Param("envName"))
+	if err != nil {
+		util.HandleError(c, util.StatusInternalServerError, err.Error())
+		return
+	}
+	var body apis.ScopeBody
+	if err := c.ShouldBindJSON(&body); err != nil || body.Name == "" || body.Type == "" {
+		util.HandleError(c, util.InvalidArgument, "the create scope request body is invalid")
+		return
+	}
+	ctrl.Log.Info("Get a create scope request", "scope", body.Name, "type", body.Type)
+	ctx := util.GetContext(c)
+	msg, err := oam.CreateScope(ctx, s.kubeClient(c), envMeta.Namespace, body)
+	util.AssembleResponse(c, msg, err)
 }
 
 // UpdateScope updates a scope
 func (s *APIServer) UpdateScope(c *gin.Context) {
+	envMeta, err := env.GetEnvByName(c.Param("envName"))
+	if err != nil {
+		util.HandleError(c, util.StatusInternalServerError, err.Error())
+		return
+	}
+	scopeName := c.Param("scopeName")
+	var body apis.ScopeBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		util.HandleError(c, util.InvalidArgument, "the update scope request body is invalid")
+		return
+	}
+	ctrl.Log.Info("Put a update scope request", "scope", scopeName)
+	ctx := util.GetContext(c)
+	msg, err := oam.UpdateScope(ctx, s.kubeClient(c), envMeta.Namespace, scopeName, body.Spec)
+	util.AssembleResponse(c, msg, err)
 }
 
 // GetScope gets a scope
 func (s *APIServer) GetScope(c *gin.Context) {
+	envMeta, err := env.GetEnvByName(c.Param("envName"))
+	if err != nil {
+		util.HandleError(c, util.StatusInternalServerError, err.Error())
+		return
+	}
+	ctx := util.GetContext(c)
+	scopeMeta, err := oam.GetScope(ctx, s.kubeClient(c), envMeta.Namespace, c.Param("scopeName"))
+	if err != nil {
+		util.HandleError(c, util.StatusInternalServerError, err.Error())
+		return
+	}
+	util.AssembleResponse(c, scopeMeta, nil)
 }
 
 // ListScope lists all scopes
 func (s *APIServer) ListScope(c *gin.Context) {
+	envMeta, err := env.GetEnvByName(c.Param("envName"))
+	if err != nil {
+		util.HandleError(c, util.StatusInternalServerError, err.Error())
+		return
+	}
+	ctx := util.GetContext(c)
+	scopeList, err := oam.ListScopes(ctx, s.kubeClient(c), envMeta.Namespace)
+	if err != nil {
+		util.HandleError(c, util.StatusInternalServerError, err.Error())
+		return
+	}
+	util.AssembleResponse(c, scopeList, nil)
 }
 
 // DeleteScope deletes a scope
 func (s *APIServer) DeleteScope(c *gin.Context) {
+	envMeta, err := env.GetEnvByName(c.Param("envName"))
+	if err != nil {
+		util.HandleError(c, util.StatusInternalServerError, err.Error())
+		return
+	}
+	scopeName := c.Param("scopeName")
+	ctrl.Log.Info("Delete a delete scope request", "scope", scopeName)
+	ctx := util.GetContext(c)
+	msg, err := oam.DeleteScope(ctx, s.kubeClient(c), envMeta.Namespace, scopeName)
+	util.AssembleResponse(c, msg, err)
 }
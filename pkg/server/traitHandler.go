@@ -1,7 +1,6 @@
 package server
 
 import (
-	"context"
 	"os"
 	"strconv"
 
@@ -89,7 +88,7 @@ func (s *APIServer) DetachTrait(c *gin.Context) {
 }
 
 // DoAttachTrait executes attaching trait operation
-func (s *APIServer) DoAttachTrait(c context.Context, body apis.TraitBody) (string, error) {
+func (s *APIServer) DoAttachTrait(c *gin.Context, body apis.TraitBody) (string, error) {
 	// Prepare
 	var appObj *application.Application
 	fs := pflag.NewFlagSet("trait", pflag.ContinueOnError)
@@ -119,12 +118,13 @@ func (s *APIServer) DoAttachTrait(c context.Context, body apis.TraitBody) (strin
 	if err != nil {
 		return "", err
 	}
+	appObj.DisallowLocalSources()
 	io := util2.IOStreams{In: os.Stdin, Out: os.Stdout, ErrOut: os.Stderr}
-	return oam.TraitOperationRun(c, s.KubeClient, env, appObj, staging, io)
+	return oam.TraitOperationRun(c, s.kubeClient(c), env, appObj, staging, io)
 }
 
 // DoDetachTrait executes detaching trait operation
-func (s *APIServer) DoDetachTrait(c context.Context, envName string, traitType string, componentName string, appName string, staging bool) (string, error) {
+func (s *APIServer) DoDetachTrait(c *gin.Context, envName string, traitType string, componentName string, appName string, staging bool) (string, error) {
 	var appObj *application.Application
 	var err error
 	if appName == "" {
@@ -138,6 +138,7 @@ func (s *APIServer) DoDetachTrait(c context.Context, envName string, traitType s
 	if err != nil {
 		return "", err
 	}
+	appObj.DisallowLocalSources()
 	io := util2.IOStreams{In: os.Stdin, Out: os.Stdout, ErrOut: os.Stderr}
-	return oam.TraitOperationRun(c, s.KubeClient, env, appObj, staging, io)
+	return oam.TraitOperationRun(c, s.kubeClient(c), env, appObj, staging, io)
 }
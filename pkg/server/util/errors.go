@@ -8,6 +8,8 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/pkg/errors"
 	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/oam-dev/kubevela/pkg/server/apis"
 )
 
 // Code defines the error code type.
@@ -20,6 +22,10 @@ const (
 	InvalidArgument
 	UnsupportedMediaType
 	StatusInternalServerError
+	Unauthorized
+	Forbidden
+	NotFound
+	Conflict
 )
 
 type errorDetail struct {
@@ -31,8 +37,12 @@ type errorDetail struct {
 var errorDetails = map[Code]errorDetail{
 	PathNotSupported:          {"PathNotSupported", http.StatusNotFound, "'%s' against '%s' is not supported"},
 	InvalidArgument:           {"InvalidArgument", http.StatusBadRequest, "%s"},
-	UnsupportedMediaType:      {"UnsupportedMediaType", http.StatusUnsupportedMediaType, "content type should be 'application/json' or 'application/octet-stream'"},
-	StatusInternalServerError: {"StatusInternalServerError", http.StatusInternalServerError, "%s"}}
+	UnsupportedMediaType:      {"UnsupportedMediaType", http.StatusUnsupportedMediaType, "content type should be 'application/json', 'application/x-yaml', 'text/yaml' or 'application/octet-stream'"},
+	StatusInternalServerError: {"StatusInternalServerError", http.StatusInternalServerError, "%s"},
+	Unauthorized:              {"Unauthorized", http.StatusUnauthorized, "%s"},
+	Forbidden:                 {"Forbidden", http.StatusForbidden, "user '%s' is not allowed to %s in env '%s'"},
+	NotFound:                  {"NotFound", http.StatusNotFound, "%s"},
+	Conflict:                  {"Conflict", http.StatusConflict, "%s"}}
 
 // ID returns the error ID.
 func (c Code) ID() string {
@@ -83,3 +93,12 @@ func HandleError(c *gin.Context, code Code, msg ...interface{}) {
 	err := ConstructError(code, msg...)
 	AssembleResponse(c, nil, err)
 }
+
+// HandleErrorWithStatus responds the error with the HTTP status code of the error code
+func HandleErrorWithStatus(c *gin.Context, code Code, msg ...interface{}) {
+	status := code.StatusCode()
+	c.JSON(status, apis.Response{
+		Code: status,
+		Data: ConstructError(code, msg...).Error(),
+	})
+}
@@ -20,6 +20,12 @@ const (
 	// ContentTypeOctetStream: octet stream
 	ContentTypeOctetStream = "application/octet-stream"
 
+	// ContentTypeYAML : yaml, used to post appfile
+	ContentTypeYAML = "application/x-yaml"
+
+	// ContentTypeTextYAML : yaml in text type
+	ContentTypeTextYAML = "text/yaml"
+
 	// HeaderTraceID is header name for trace id.
 	HeaderTraceID = "x-fc-trace-id"
 
@@ -53,6 +59,7 @@ const (
 	CapabilityPath         = "/capabilities"
 	CapabilityCenterPath   = "/capability-centers"
 	VersionPath            = "/version"
+	OpenAPIPath            = "/openapi.json"
 )
 
 // NoRoute is a handler which is invoked when there is no route matches.
@@ -121,7 +128,7 @@ func ValidateHeaders() gin.HandlerFunc {
 				return
 			}
 			switch mType {
-			case ContentTypeJSON, ContentTypeOctetStream:
+			case ContentTypeJSON, ContentTypeOctetStream, ContentTypeYAML, ContentTypeTextYAML:
 				// Passes.
 			default:
 				SetErrorAndAbort(c, UnsupportedMediaType)
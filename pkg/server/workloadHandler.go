@@ -38,8 +38,9 @@ func (s *APIServer) CreateWorkload(c *gin.Context) {
 		util.HandleError(c, util.StatusInternalServerError, err.Error())
 		return
 	}
+	appObj.DisallowLocalSources()
 	io := cmdutil.IOStreams{In: os.Stdin, Out: os.Stdout, ErrOut: os.Stderr}
-	msg, err := oam.BaseRun(body.Staging, appObj, s.KubeClient, env, io)
+	msg, err := oam.BaseRun(body.Staging, appObj, s.kubeClient(c), env, io)
 	if err != nil {
 		util.HandleError(c, util.StatusInternalServerError, err.Error())
 		return
@@ -79,18 +80,10 @@ func (s *APIServer) GetWorkload(c *gin.Context) {
 
 // ListWorkload lists all workloads in the cluster
 func (s *APIServer) ListWorkload(c *gin.Context) {
-	var workloadDefinitionList []apis.WorkloadMeta
-	workloads, err := plugins.LoadInstalledCapabilityWithType(types.TypeWorkload)
+	workloadDefinitionList, err := oam.ListWorkloads()
 	if err != nil {
 		util.HandleError(c, util.StatusInternalServerError, err)
 		return
 	}
-	for _, w := range workloads {
-		workloadDefinitionList = append(workloadDefinitionList, apis.WorkloadMeta{
-			Name:       w.Name,
-			Parameters: w.Parameters,
-			AppliesTo:  w.AppliesTo,
-		})
-	}
 	util.AssembleResponse(c, workloadDefinitionList, err)
 }
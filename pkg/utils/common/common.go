@@ -13,6 +13,7 @@ import (
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
 
+	velacore "github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
 	"github.com/oam-dev/kubevela/apis/standard.oam.dev/v1alpha1"
 	"github.com/oam-dev/kubevela/apis/types"
 )
@@ -27,6 +28,7 @@ func init() {
 	_ = certmanager.AddToScheme(Scheme)
 	_ = core.AddToScheme(Scheme)
 	_ = v1alpha1.AddToScheme(Scheme)
+	_ = velacore.AddToScheme(Scheme)
 	// +kubebuilder:scaffold:scheme
 }
 
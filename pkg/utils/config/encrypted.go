@@ -0,0 +1,113 @@
+package config
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	b64 "encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// PassphraseEnv is the environment variable of passphrase for the encrypted config store
+const PassphraseEnv = "VELA_CONFIG_PASSPHRASE"
+
+// encryptedHeader prefixes encrypted config files, files without it are read as plain configs,
+// so configs created before switching to the encrypted store still work and get encrypted when they're set again
+var encryptedHeader = []byte("vela-encrypted-config:v1:")
+
+// parameters of scrypt recommended for interactive logins, and sizes of AES-256-GCM
+const (
+	scryptN   = 1 << 15
+	scryptR   = 8
+	scryptP   = 1
+	keySize   = 32
+	saltSize  = 16
+	nonceSize = 12
+)
+
+// ErrWrongPassphrase is returned when an encrypted config can't be decrypted
+var ErrWrongPassphrase = errors.New("decrypt config failed, the passphrase may be wrong")
+
+// EncryptedStore stores configs as files like LocalStore, but the content is encrypted by AES-256-GCM
+// with a key derived from the passphrase by scrypt. Each file has its own random salt and nonce.
+type EncryptedStore struct {
+	LocalStore
+	Passphrase string
+}
+
+// Get decrypts a config file
+func (s *EncryptedStore) Get(name string) (map[string]string, error) {
+	b, err := ReadConfig(s.EnvName, name)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.HasPrefix(b, encryptedHeader) {
+		if b, err = s.decrypt(b[len(encryptedHeader):]); err != nil {
+			return nil, fmt.Errorf("read config %s err %w", name, err)
+		}
+	}
+	return decodeConfig(b)
+}
+
+// Set encrypts and writes a config file
+func (s *EncryptedStore) Set(name string, data map[string]string) error {
+	b, err := s.encrypt(encodeConfig(data))
+	if err != nil {
+		return err
+	}
+	return WriteConfig(s.EnvName, name, append(append([]byte{}, encryptedHeader...), b...))
+}
+
+func (s *EncryptedStore) aead(salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(s.Passphrase), salt, scryptN, scryptR, scryptP, keySize)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// encrypt returns base64(salt | nonce | ciphertext)
+func (s *EncryptedStore) encrypt(plain []byte) ([]byte, error) {
+	buf := make([]byte, saltSize+nonceSize)
+	if _, err := io.ReadFull(rand.Reader, buf); err != nil {
+		return nil, err
+	}
+	aead, err := s.aead(buf[:saltSize])
+	if err != nil {
+		return nil, err
+	}
+	sealed := aead.Seal(buf, buf[saltSize:], plain, nil)
+	out := make([]byte, b64.StdEncoding.EncodedLen(len(sealed)))
+	b64.StdEncoding.Encode(out, sealed)
+	return out, nil
+}
+
+func (s *EncryptedStore) decrypt(encoded []byte) ([]byte, error) {
+	sealed := make([]byte, b64.StdEncoding.DecodedLen(len(encoded)))
+	n, err := b64.StdEncoding.Decode(sealed, bytes.TrimSpace(encoded))
+	if err != nil {
+		return nil, err
+	}
+	sealed = sealed[:n]
+	if len(sealed) < saltSize+nonceSize {
+		return nil, ErrWrongPassphrase
+	}
+	aead, err := s.aead(sealed[:saltSize])
+	if err != nil {
+		return nil, err
+	}
+	plain, err := aead.Open(nil, sealed[saltSize:saltSize+nonceSize], sealed[saltSize+nonceSize:], nil)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	return plain, nil
+}
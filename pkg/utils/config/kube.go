@@ -0,0 +1,123 @@
+package config
+
+import (
+	"context"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlconfig "sigs.k8s.io/controller-runtime/pkg/client/config"
+
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/utils/env"
+)
+
+// Labels of Secrets holding configs
+const (
+	// LabelConfigName is the name of config in the Secret
+	LabelConfigName = "config.oam.dev/name"
+	// LabelConfigEnv is the env of config which is synced into the Secret when deploying apps
+	LabelConfigEnv = "config.oam.dev/env"
+	// LabelConfigStore marks Secrets of KubeStore
+	LabelConfigStore = "config.oam.dev/store"
+)
+
+// FormatStoreSecretName returns the name of Secret which KubeStore keeps a config in
+func FormatStoreSecretName(configName string) string {
+	return "vela-configstore-" + configName
+}
+
+// KubeStore stores each config as a Secret in the namespace of env
+type KubeStore struct {
+	Client    client.Client
+	Namespace string
+}
+
+// List returns names of configs in the namespace
+func (s *KubeStore) List() ([]string, error) {
+	var secrets corev1.SecretList
+	if err := s.Client.List(context.Background(), &secrets, client.InNamespace(s.Namespace),
+		client.MatchingLabels{LabelConfigStore: "true"}); err != nil {
+		return nil, err
+	}
+	l := []string{}
+	for _, secret := range secrets.Items {
+		l = append(l, secret.Labels[LabelConfigName])
+	}
+	sort.Strings(l)
+	return l, nil
+}
+
+// Get reads data of the config Secret
+func (s *KubeStore) Get(name string) (map[string]string, error) {
+	var secret corev1.Secret
+	if err := s.Client.Get(context.Background(), client.ObjectKey{Namespace: s.Namespace, Name: FormatStoreSecretName(name)}, &secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return map[string]string{}, nil
+		}
+		return nil, err
+	}
+	data := make(map[string]string, len(secret.Data))
+	for k, v := range secret.Data {
+		data[k] = string(v)
+	}
+	return data, nil
+}
+
+// Set creates or updates the config Secret
+func (s *KubeStore) Set(name string, data map[string]string) error {
+	ctx := context.Background()
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      FormatStoreSecretName(name),
+			Namespace: s.Namespace,
+			Labels: map[string]string{
+				LabelConfigStore: "true",
+				LabelConfigName:  name,
+			},
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: make(map[string][]byte, len(data)),
+	}
+	for k, v := range data {
+		secret.Data[k] = []byte(v)
+	}
+	var exist corev1.Secret
+	if err := s.Client.Get(ctx, client.ObjectKey{Namespace: s.Namespace, Name: secret.Name}, &exist); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		return s.Client.Create(ctx, secret)
+	}
+	secret.ResourceVersion = exist.ResourceVersion
+	return s.Client.Update(ctx, secret)
+}
+
+// Delete removes the config Secret
+func (s *KubeStore) Delete(name string) error {
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: FormatStoreSecretName(name), Namespace: s.Namespace}}
+	if err := s.Client.Delete(context.Background(), secret); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// newClient creates a client for the cluster of env
+func newClient(envMeta *types.EnvMeta) (client.Client, error) {
+	restConf, err := ctrlconfig.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	if restConf, err = env.RestConfig(envMeta, restConf); err != nil {
+		return nil, err
+	}
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	return client.New(restConf, client.Options{Scheme: scheme})
+}
@@ -0,0 +1,131 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	b64 "encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/types"
+)
+
+// Backends of config store, an env chooses one by `vela env init --config-store`
+const (
+	// StoreLocal stores configs as base64 encoded files under the env dir, it's the default
+	StoreLocal = "local"
+	// StoreEncrypted stores configs as files under the env dir encrypted with a key derived from a passphrase
+	StoreEncrypted = "encrypted"
+	// StoreKubernetes stores configs as Secrets in the namespace of env, so they're shared by everyone using the namespace
+	StoreKubernetes = "kubernetes"
+)
+
+// Store is the storage of configs in an env, `vela config` commands and Appfile rendering work on it
+type Store interface {
+	// List returns names of all configs
+	List() ([]string, error)
+	// Get returns data of a config, it's empty if the config doesn't exist
+	Get(name string) (map[string]string, error)
+	// Set replaces data of a config
+	Set(name string, data map[string]string) error
+	// Delete removes a config, it's not an error if the config doesn't exist
+	Delete(name string) error
+}
+
+// NewStore returns the config store of env. The client is only used by the kubernetes backend,
+// it's created for the cluster of env if nil.
+func NewStore(env *types.EnvMeta, c client.Client) (Store, error) {
+	switch env.ConfigStore {
+	case "", StoreLocal:
+		return &LocalStore{EnvName: env.Name}, nil
+	case StoreEncrypted:
+		passphrase := os.Getenv(PassphraseEnv)
+		if passphrase == "" {
+			return nil, fmt.Errorf("configs of env %s are encrypted, set the passphrase by environment variable %s",
+				env.Name, PassphraseEnv)
+		}
+		return &EncryptedStore{LocalStore: LocalStore{EnvName: env.Name}, Passphrase: passphrase}, nil
+	case StoreKubernetes:
+		if c == nil {
+			var err error
+			if c, err = newClient(env); err != nil {
+				return nil, err
+			}
+		}
+		return &KubeStore{Client: c, Namespace: env.Namespace}, nil
+	default:
+		return nil, fmt.Errorf("unknown config store %s of env %s, must be one of %s, %s and %s",
+			env.ConfigStore, env.Name, StoreLocal, StoreEncrypted, StoreKubernetes)
+	}
+}
+
+// LocalStore stores configs as files under `envs/<env>/configs`, each line of a file is `key: base64(value)`
+type LocalStore struct {
+	EnvName string
+}
+
+// List returns names of config files
+func (s *LocalStore) List() ([]string, error) {
+	d, err := GetConfigsDir(s.EnvName)
+	if err != nil {
+		return nil, err
+	}
+	files, err := ioutil.ReadDir(d)
+	if err != nil {
+		return nil, err
+	}
+	l := []string{}
+	for _, f := range files {
+		l = append(l, f.Name())
+	}
+	return l, nil
+}
+
+// Get reads a config file
+func (s *LocalStore) Get(name string) (map[string]string, error) {
+	b, err := ReadConfig(s.EnvName, name)
+	if err != nil {
+		return nil, err
+	}
+	return decodeConfig(b)
+}
+
+// Set writes a config file
+func (s *LocalStore) Set(name string, data map[string]string) error {
+	return WriteConfig(s.EnvName, name, encodeConfig(data))
+}
+
+// Delete removes a config file
+func (s *LocalStore) Delete(name string) error {
+	return DeleteConfig(s.EnvName, name)
+}
+
+func decodeConfig(b []byte) (map[string]string, error) {
+	data := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		k, v, err := ReadConfigLine(scanner.Text())
+		if err != nil {
+			return nil, err
+		}
+		data[k] = v
+	}
+	return data, scanner.Err()
+}
+
+func encodeConfig(data map[string]string) []byte {
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var out bytes.Buffer
+	for _, k := range keys {
+		out.WriteString(fmt.Sprintf("%s: %s\n", k, b64.StdEncoding.EncodeToString([]byte(data[k]))))
+	}
+	return out.Bytes()
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/utils/system"
+)
+
+func testStore(t *testing.T, store Store) {
+	data, err := store.Get("demo")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(data))
+
+	assert.NoError(t, store.Set("demo", map[string]string{"a": "b", "password": "s3cr3t: with colon"}))
+	assert.NoError(t, store.Set("demo2", map[string]string{"c": "d"}))
+	data, err = store.Get("demo")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"a": "b", "password": "s3cr3t: with colon"}, data)
+
+	names, err := store.List()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"demo", "demo2"}, names)
+
+	assert.NoError(t, store.Delete("demo"))
+	assert.NoError(t, store.Delete("not-exist"))
+	names, err = store.List()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"demo2"}, names)
+}
+
+func TestStores(t *testing.T) {
+	home, err := ioutil.TempDir("", "vela-config-store")
+	assert.NoError(t, err)
+	defer os.RemoveAll(home)
+	assert.NoError(t, os.Setenv(system.VelaHomeEnv, home))
+	defer os.Unsetenv(system.VelaHomeEnv)
+
+	store, err := NewStore(&types.EnvMeta{Name: "local"}, nil)
+	assert.NoError(t, err)
+	testStore(t, store)
+
+	_, err = NewStore(&types.EnvMeta{Name: "encrypted", ConfigStore: StoreEncrypted}, nil)
+	assert.Error(t, err)
+	assert.NoError(t, os.Setenv(PassphraseEnv, "my passphrase"))
+	defer os.Unsetenv(PassphraseEnv)
+	store, err = NewStore(&types.EnvMeta{Name: "encrypted", ConfigStore: StoreEncrypted}, nil)
+	assert.NoError(t, err)
+	testStore(t, store)
+
+	scheme := runtime.NewScheme()
+	assert.NoError(t, clientgoscheme.AddToScheme(scheme))
+	store, err = NewStore(&types.EnvMeta{Name: "kube", Namespace: "team", ConfigStore: StoreKubernetes},
+		fake.NewFakeClientWithScheme(scheme))
+	assert.NoError(t, err)
+	testStore(t, store)
+
+	_, err = NewStore(&types.EnvMeta{Name: "unknown", ConfigStore: "vault"}, nil)
+	assert.Error(t, err)
+}
+
+func TestEncryptedStore(t *testing.T) {
+	home, err := ioutil.TempDir("", "vela-config-store")
+	assert.NoError(t, err)
+	defer os.RemoveAll(home)
+	assert.NoError(t, os.Setenv(system.VelaHomeEnv, home))
+	defer os.Unsetenv(system.VelaHomeEnv)
+
+	// configs created by the local store are still readable after switching to the encrypted one
+	local := &LocalStore{EnvName: "default"}
+	assert.NoError(t, local.Set("demo", map[string]string{"a": "b"}))
+	store := &EncryptedStore{LocalStore: LocalStore{EnvName: "default"}, Passphrase: "my passphrase"}
+	data, err := store.Get("demo")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"a": "b"}, data)
+
+	assert.NoError(t, store.Set("demo", map[string]string{"password": "s3cr3t"}))
+	raw, err := ReadConfig("default", "demo")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(raw), string(encryptedHeader)))
+	assert.NotContains(t, string(raw), "s3cr3t")
+	// neither the value nor its base64 is in the file
+	assert.NotContains(t, string(raw), "czNjcjN0")
+
+	wrong := &EncryptedStore{LocalStore: LocalStore{EnvName: "default"}, Passphrase: "wrong"}
+	_, err = wrong.Get("demo")
+	assert.True(t, errors.Is(err, ErrWrongPassphrase))
+}
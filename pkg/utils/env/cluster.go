@@ -0,0 +1,195 @@
+package env
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlconfig "sigs.k8s.io/controller-runtime/pkg/client/config"
+
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/utils/system"
+)
+
+// Envs are stored as ConfigMaps in vela-system so they're shared by everyone using the cluster,
+// env dirs under the vela home are a cache of them, plus the pointer to the current env.
+
+// LabelEnvName labels ConfigMaps storing envs with the env name
+const LabelEnvName = "env.oam.dev/name"
+
+// FormatEnvConfigMapName returns the name of ConfigMap storing the env
+func FormatEnvConfigMapName(envName string) string {
+	return "vela-env-" + envName
+}
+
+func saveEnvToCluster(ctx context.Context, c client.Client, envMeta *types.EnvMeta) error {
+	meta := *envMeta
+	// current env is local to every user, so are the kubeconfig path and context which only make sense on the
+	// machine of the user and may leak its layout to everyone reading envs. The inline cluster is kept local too,
+	// otherwise anyone able to edit envs could redirect the credentials of others to another server.
+	meta.Current = ""
+	meta.KubeConfig = ""
+	meta.KubeContext = ""
+	meta.Cluster = nil
+	data, err := json.Marshal(&meta)
+	if err != nil {
+		return err
+	}
+	if err := c.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: types.DefaultKubeVelaNS}}); err != nil && !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      FormatEnvConfigMapName(meta.Name),
+			Namespace: types.DefaultKubeVelaNS,
+			Labels:    map[string]string{LabelEnvName: meta.Name},
+		},
+		Data: map[string]string{system.EnvConfigName: string(data)},
+	}
+	var exist corev1.ConfigMap
+	if err := c.Get(ctx, client.ObjectKey{Namespace: cm.Namespace, Name: cm.Name}, &exist); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		return c.Create(ctx, cm)
+	}
+	cm.ResourceVersion = exist.ResourceVersion
+	return c.Update(ctx, cm)
+}
+
+func deleteEnvFromCluster(ctx context.Context, c client.Client, envName string) (bool, error) {
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: FormatEnvConfigMapName(envName), Namespace: types.DefaultKubeVelaNS}}
+	if err := c.Delete(ctx, cm); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func listEnvsFromCluster(ctx context.Context, c client.Client) ([]*types.EnvMeta, error) {
+	var cms corev1.ConfigMapList
+	if err := c.List(ctx, &cms, client.InNamespace(types.DefaultKubeVelaNS), client.HasLabels{LabelEnvName}); err != nil {
+		return nil, err
+	}
+	var envs []*types.EnvMeta
+	for i := range cms.Items {
+		envMeta, err := envFromConfigMap(&cms.Items[i])
+		if err != nil {
+			return nil, err
+		}
+		envs = append(envs, envMeta)
+	}
+	return envs, nil
+}
+
+// GetEnvFromCluster reads the env from the ConfigMap storing it, it is used by controllers which have no local env cache
+func GetEnvFromCluster(ctx context.Context, c client.Client, envName string) (*types.EnvMeta, error) {
+	var cm corev1.ConfigMap
+	if err := c.Get(ctx, client.ObjectKey{Namespace: types.DefaultKubeVelaNS, Name: FormatEnvConfigMapName(envName)}, &cm); err != nil {
+		return nil, err
+	}
+	return envFromConfigMap(&cm)
+}
+
+func envFromConfigMap(cm *corev1.ConfigMap) (*types.EnvMeta, error) {
+	var envMeta types.EnvMeta
+	if err := json.Unmarshal([]byte(cm.Data[system.EnvConfigName]), &envMeta); err != nil {
+		return nil, fmt.Errorf("env in ConfigMap %s is malformed: %w", cm.Name, err)
+	}
+	return &envMeta, nil
+}
+
+// writeEnvCache saves the env into the local cache
+func writeEnvCache(envMeta *types.EnvMeta) error {
+	meta := *envMeta
+	meta.Current = ""
+	data, err := json.Marshal(&meta)
+	if err != nil {
+		return err
+	}
+	subEnvDir := GetEnvDirByName(meta.Name)
+	if _, err = system.CreateIfNotExist(subEnvDir); err != nil {
+		return err
+	}
+	// nolint:gosec
+	return ioutil.WriteFile(filepath.Join(subEnvDir, system.EnvConfigName), data, 0644)
+}
+
+// removeEnvCache removes the cached env but keeps other files in the env dir, e.g. Appfiles and configs
+func removeEnvCache(envName string) error {
+	err := os.Remove(filepath.Join(GetEnvDirByName(envName), system.EnvConfigName))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// SyncEnvs refreshes the local cache with envs in the cluster. If there's no env in the cluster yet,
+// envs in the local cache are uploaded, so envs created before envs were stored in the cluster are kept.
+func SyncEnvs(ctx context.Context, c client.Client) error {
+	clusterEnvs, err := listEnvsFromCluster(ctx, c)
+	if err != nil {
+		return err
+	}
+	localEnvs, err := listCachedEnvs()
+	if err != nil {
+		return err
+	}
+	if len(clusterEnvs) == 0 {
+		for _, e := range localEnvs {
+			if err := saveEnvToCluster(ctx, c, e); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	cached := make(map[string]*types.EnvMeta, len(localEnvs))
+	for _, e := range localEnvs {
+		cached[e.Name] = e
+	}
+	inCluster := make(map[string]bool, len(clusterEnvs))
+	for _, e := range clusterEnvs {
+		inCluster[e.Name] = true
+		// the kubeconfig path, context and inline cluster aren't stored in the cluster, keep the local ones
+		if old, ok := cached[e.Name]; ok {
+			e.KubeConfig = old.KubeConfig
+			e.KubeContext = old.KubeContext
+			e.Cluster = old.Cluster
+		}
+		if err := writeEnvCache(e); err != nil {
+			return err
+		}
+	}
+	for _, e := range localEnvs {
+		if !inCluster[e.Name] {
+			if err := removeEnvCache(e.Name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// newClusterClient creates a client from kubeconfig, it's used to look up envs missing in the local cache
+var newClusterClient = func() (client.Client, error) {
+	restConf, err := ctrlconfig.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	return client.New(restConf, client.Options{Scheme: scheme})
+}
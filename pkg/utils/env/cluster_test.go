@@ -0,0 +1,84 @@
+package env
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/utils/system"
+)
+
+func TestClusterEnvs(t *testing.T) {
+	ctx := context.Background()
+	home, err := ioutil.TempDir("", "vela-env")
+	assert.NoError(t, err)
+	defer os.RemoveAll(home)
+	assert.NoError(t, os.Setenv(system.VelaHomeEnv, home))
+	defer os.Unsetenv(system.VelaHomeEnv)
+	assert.NoError(t, system.InitDirs())
+	assert.NoError(t, system.InitDefaultEnv())
+
+	scheme := runtime.NewScheme()
+	assert.NoError(t, clientgoscheme.AddToScheme(scheme))
+	c := fake.NewFakeClientWithScheme(scheme)
+
+	// envs created before storing them in the cluster are uploaded
+	envs, err := ListEnvs(ctx, c, "")
+	assert.NoError(t, err)
+	assert.Equal(t, []*types.EnvMeta{{Name: "default", Namespace: "default", Current: "*"}}, envs)
+	var cm corev1.ConfigMap
+	assert.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: types.DefaultKubeVelaNS, Name: "vela-env-default"}, &cm))
+	assert.Equal(t, "default", cm.Labels[LabelEnvName])
+
+	_, err = CreateOrUpdateEnv(ctx, c, "prod", &types.EnvMeta{Namespace: "prod"})
+	assert.NoError(t, err)
+	assert.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: types.DefaultKubeVelaNS, Name: "vela-env-prod"}, &cm))
+	meta, err := envFromConfigMap(&cm)
+	assert.NoError(t, err)
+	assert.Equal(t, &types.EnvMeta{Name: "prod", Namespace: "prod"}, meta)
+
+	// an env created by a teammate is synced into the cache, and one deleted by a teammate is removed from the cache
+	assert.NoError(t, saveEnvToCluster(ctx, c, &types.EnvMeta{Name: "staging", Namespace: "staging"}))
+	_, err = deleteEnvFromCluster(ctx, c, "default")
+	assert.NoError(t, err)
+	envs, err = ListEnvs(ctx, c, "")
+	assert.NoError(t, err)
+	assert.Equal(t, []*types.EnvMeta{
+		{Name: "prod", Namespace: "prod", Current: "*"},
+		{Name: "staging", Namespace: "staging"},
+	}, envs)
+
+	// the kubeconfig path, context and inline cluster stay in the local cache
+	staging := &types.EnvMeta{Name: "staging", Namespace: "staging", KubeConfig: "/home/me/.kube/staging", KubeContext: "staging",
+		Cluster: &types.ClusterRef{Server: "https://staging.example.com"}}
+	assert.NoError(t, writeEnvCache(staging))
+	assert.NoError(t, saveEnvToCluster(ctx, c, staging))
+	assert.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: types.DefaultKubeVelaNS, Name: "vela-env-staging"}, &cm))
+	meta, err = envFromConfigMap(&cm)
+	assert.NoError(t, err)
+	assert.Equal(t, &types.EnvMeta{Name: "staging", Namespace: "staging"}, meta)
+	assert.NoError(t, SyncEnvs(ctx, c))
+	meta, err = GetEnvByName("staging")
+	assert.NoError(t, err)
+	assert.Equal(t, "/home/me/.kube/staging", meta.KubeConfig)
+	assert.Equal(t, "staging", meta.KubeContext)
+	assert.Equal(t, &types.ClusterRef{Server: "https://staging.example.com"}, meta.Cluster)
+
+	_, err = SetEnv("staging")
+	assert.NoError(t, err)
+	_, err = DeleteEnv(ctx, c, "prod")
+	assert.NoError(t, err)
+	envs, err = ListEnvs(ctx, c, "")
+	assert.NoError(t, err)
+	assert.Equal(t, []*types.EnvMeta{{Name: "staging", Namespace: "staging", Current: "*",
+		KubeConfig: "/home/me/.kube/staging", KubeContext: "staging", Cluster: &types.ClusterRef{Server: "https://staging.example.com"}}}, envs)
+}
@@ -9,16 +9,14 @@ import (
 	"os"
 	"path/filepath"
 
-	acmev1 "github.com/wonderflow/cert-manager-api/pkg/apis/acme/v1"
 	certmanager "github.com/wonderflow/cert-manager-api/pkg/apis/certmanager/v1"
-	v1 "github.com/wonderflow/cert-manager-api/pkg/apis/meta/v1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/server/apis"
 	"github.com/oam-dev/kubevela/pkg/utils/system"
 )
 
@@ -31,12 +29,12 @@ func GetEnvDirByName(name string) string {
 	return filepath.Join(envdir, name)
 }
 
-// GetEnvByName will get env info by name
+// GetEnvByName will get env info by name, it's read from the local cache and then the cluster if it's not cached
 func GetEnvByName(name string) (*types.EnvMeta, error) {
 	data, err := ioutil.ReadFile(filepath.Join(GetEnvDirByName(name), system.EnvConfigName))
 	if err != nil {
 		if os.IsNotExist(err) {
-			return nil, fmt.Errorf("env %s not exist", name)
+			return getAndCacheEnvFromCluster(name)
 		}
 		return nil, err
 	}
@@ -47,10 +45,31 @@ func GetEnvByName(name string) (*types.EnvMeta, error) {
 	return &meta, nil
 }
 
+// getAndCacheEnvFromCluster looks up envs created by others
+func getAndCacheEnvFromCluster(name string) (*types.EnvMeta, error) {
+	notExist := fmt.Errorf("env %s not exist", name)
+	c, err := newClusterClient()
+	if err != nil {
+		return nil, notExist
+	}
+	meta, err := GetEnvFromCluster(context.Background(), c, name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, notExist
+		}
+		return nil, fmt.Errorf("get env %s from cluster err %w", name, err)
+	}
+	if err = writeEnvCache(meta); err != nil {
+		return nil, err
+	}
+	return meta, nil
+}
+
 // CreateOrUpdateEnv will create or update env.
 // If it does not exist, create it and set to the new env.
 // If it exists, update it and set to the new env.
 func CreateOrUpdateEnv(ctx context.Context, c client.Client, envName string, envArgs *types.EnvMeta) (string, error) {
+	envArgs.Name = envName
 
 	createOrUpdated := "created"
 	old, err := GetEnvByName(envName)
@@ -62,12 +81,44 @@ func CreateOrUpdateEnv(ctx context.Context, c client.Client, envName string, env
 		if envArgs.Email == "" {
 			envArgs.Email = old.Email
 		}
+		if envArgs.HostPattern == "" {
+			envArgs.HostPattern = old.HostPattern
+		}
 		if envArgs.Issuer == "" {
 			envArgs.Issuer = old.Issuer
 		}
 		if envArgs.Namespace == "" {
 			envArgs.Namespace = old.Namespace
 		}
+		if envArgs.ConfigStore == "" {
+			envArgs.ConfigStore = old.ConfigStore
+		}
+		if envArgs.RouteProvider == "" {
+			envArgs.RouteProvider = old.RouteProvider
+		}
+		if envArgs.IssuerConfig == nil {
+			envArgs.IssuerConfig = old.IssuerConfig
+		}
+		if envArgs.IssuerType == "" {
+			envArgs.IssuerType = old.IssuerType
+		}
+		if envArgs.Quota == nil {
+			envArgs.Quota = old.Quota
+		}
+		if envArgs.Limits == nil {
+			envArgs.Limits = old.Limits
+		}
+		if envArgs.Isolation == "" {
+			envArgs.Isolation = old.Isolation
+		}
+		if envArgs.IngressNamespaceSelector == nil {
+			envArgs.IngressNamespaceSelector = old.IngressNamespaceSelector
+		}
+		if !IsBoundToCluster(envArgs) {
+			envArgs.KubeConfig = old.KubeConfig
+			envArgs.KubeContext = old.KubeContext
+			envArgs.Cluster = old.Cluster
+		}
 	}
 
 	if envArgs.Namespace == "" {
@@ -75,52 +126,41 @@ func CreateOrUpdateEnv(ctx context.Context, c client.Client, envName string, env
 	}
 
 	var message = ""
-	// Create Namespace
-	if err := c.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: envArgs.Namespace}}); err != nil && !apierrors.IsAlreadyExists(err) {
+	if err := ValidateIssuerConfig(envArgs); err != nil {
 		return message, err
 	}
-
-	// Create Issuer For SSL if both email and domain are all set.
-	if envArgs.Email != "" && envArgs.Domain != "" {
-		issuerName := "oam-env-" + envArgs.Name
-		if err := c.Create(ctx, &certmanager.Issuer{
-			ObjectMeta: metav1.ObjectMeta{Name: issuerName, Namespace: envArgs.Namespace},
-			Spec: certmanager.IssuerSpec{
-				IssuerConfig: certmanager.IssuerConfig{
-					ACME: &acmev1.ACMEIssuer{
-						Email:  envArgs.Email,
-						Server: ProductionACMEServer,
-						PrivateKey: v1.SecretKeySelector{
-							LocalObjectReference: v1.LocalObjectReference{Name: "oam-env-" + envArgs.Name + ".key"},
-						},
-						Solvers: []acmev1.ACMEChallengeSolver{{
-							HTTP01: &acmev1.ACMEChallengeSolverHTTP01{
-								Ingress: &acmev1.ACMEChallengeSolverHTTP01Ingress{Class: pointer.StringPtr("nginx")},
-							},
-						}},
-					},
-				},
-			},
-		}); err != nil && !apierrors.IsAlreadyExists(err) {
-			return message, err
-		}
-		envArgs.Issuer = issuerName
+	if err := ValidateGuardrails(envArgs); err != nil {
+		return message, err
 	}
-
-	data, err := json.Marshal(envArgs)
+	// Namespace and Issuer are created in the cluster of env, while env itself is stored in the cluster of c
+	envClient, err := newEnvClient(c, envArgs)
 	if err != nil {
 		return message, err
 	}
-	envdir, err := system.GetEnvDir()
-	if err != nil {
+	// Create Namespace
+	if err := envClient.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: envArgs.Namespace}}); err != nil && !apierrors.IsAlreadyExists(err) {
 		return message, err
 	}
-	subEnvDir := filepath.Join(envdir, envName)
-	if _, err = system.CreateIfNotExist(subEnvDir); err != nil {
+	if err := applyGuardrails(ctx, envClient, envArgs); err != nil {
 		return message, err
 	}
-	// nolint:gosec
-	if err = ioutil.WriteFile(filepath.Join(subEnvDir, system.EnvConfigName), data, 0644); err != nil {
+
+	// Create Issuer For SSL, an ACME issuer is only created if both email and domain are set.
+	if envArgs.IssuerConfig != nil && envArgs.IssuerConfig.ClusterIssuer != "" {
+		envArgs.Issuer = envArgs.IssuerConfig.ClusterIssuer
+		envArgs.IssuerType = issuerType(envArgs)
+	} else if issuer := buildIssuer(envArgs); issuer != nil {
+		if err := createOrUpdateIssuer(ctx, envClient, issuer); err != nil {
+			return message, err
+		}
+		envArgs.Issuer = issuer.Name
+		envArgs.IssuerType = issuerType(envArgs)
+	}
+
+	if err = saveEnvToCluster(ctx, c, envArgs); err != nil {
+		return message, err
+	}
+	if err = writeEnvCache(envArgs); err != nil {
 		return message, err
 	}
 	curEnvPath, err := system.GetCurrentEnvPath()
@@ -139,6 +179,18 @@ func CreateOrUpdateEnv(ctx context.Context, c client.Client, envName string, env
 	return message, nil
 }
 
+func createOrUpdateIssuer(ctx context.Context, c client.Client, issuer *certmanager.Issuer) error {
+	var exist certmanager.Issuer
+	if err := c.Get(ctx, client.ObjectKey{Namespace: issuer.Namespace, Name: issuer.Name}, &exist); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		return c.Create(ctx, issuer)
+	}
+	exist.Spec = issuer.Spec
+	return c.Update(ctx, &exist)
+}
+
 // CreateEnv will only create. If env already exists, return error
 func CreateEnv(ctx context.Context, c client.Client, envName string, envArgs *types.EnvMeta) (string, error) {
 	_, err := GetEnvByName(envName)
@@ -156,30 +208,41 @@ func UpdateEnv(ctx context.Context, c client.Client, envName string, namespace s
 	if err != nil {
 		return err.Error(), err
 	}
-	if err := c.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: envMeta.Namespace}}); err != nil && !apierrors.IsAlreadyExists(err) {
+	envClient, err := newEnvClient(c, envMeta)
+	if err != nil {
+		return message, err
+	}
+	if err := envClient.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}}); err != nil && !apierrors.IsAlreadyExists(err) {
 		return message, err
 	}
+	// move guardrails into the new namespace
+	if envMeta.Namespace != namespace {
+		if err := applyGuardrails(ctx, envClient, &types.EnvMeta{Name: envMeta.Name, Namespace: envMeta.Namespace}); err != nil {
+			return message, err
+		}
+	}
 	envMeta.Namespace = namespace
-	data, err := json.Marshal(envMeta)
-	if err != nil {
+	if err := applyGuardrails(ctx, envClient, envMeta); err != nil {
 		return message, err
 	}
-	envdir, err := system.GetEnvDir()
-	if err != nil {
+	if err = saveEnvToCluster(ctx, c, envMeta); err != nil {
 		return message, err
 	}
-	subEnvDir := filepath.Join(envdir, envName)
-	// nolint:gosec
-	if err = ioutil.WriteFile(filepath.Join(subEnvDir, system.EnvConfigName), data, 0644); err != nil {
+	if err = writeEnvCache(envMeta); err != nil {
 		return message, err
 	}
 	message = "Update env succeed"
 	return message, err
 }
 
-// ListEnvs will list all envs
-func ListEnvs(envName string) ([]*types.EnvMeta, error) {
+// ListEnvs will list all envs, the local cache is synced with the cluster first if c is not nil
+func ListEnvs(ctx context.Context, c client.Client, envName string) ([]*types.EnvMeta, error) {
 	var envList []*types.EnvMeta
+	if c != nil {
+		if err := SyncEnvs(ctx, c); err != nil {
+			return envList, err
+		}
+	}
 	if envName != "" {
 		env, err := GetEnvByName(envName)
 		if err != nil {
@@ -191,17 +254,49 @@ func ListEnvs(envName string) ([]*types.EnvMeta, error) {
 		envList = append(envList, env)
 		return envList, err
 	}
-	envDir, err := system.GetEnvDir()
+	envList, err := listCachedEnvs()
 	if err != nil {
 		return envList, err
 	}
-	files, err := ioutil.ReadDir(envDir)
+	curEnv, err := GetCurrentEnvName()
+	if err != nil {
+		curEnv = types.DefaultEnvName
+	}
+	for _, envMeta := range envList {
+		if curEnv == envMeta.Name {
+			envMeta.Current = "*"
+		}
+	}
+	return envList, nil
+}
+
+// DescribeEnv converts the env to the shape listed by `vela env ls` and the API server,
+// the quota usage is "unknown" if it can't be queried
+func DescribeEnv(ctx context.Context, c client.Client, envMeta *types.EnvMeta) apis.Environment {
+	quota, err := QuotaUsage(ctx, c, envMeta)
+	if err != nil {
+		quota = "unknown"
+	}
+	return apis.Environment{
+		EnvName:   envMeta.Name,
+		Namespace: envMeta.Namespace,
+		Email:     envMeta.Email,
+		Domain:    envMeta.Domain,
+		Current:   envMeta.Current,
+		Cluster:   ClusterName(envMeta),
+		Quota:     quota,
+	}
+}
+
+func listCachedEnvs() ([]*types.EnvMeta, error) {
+	var envList []*types.EnvMeta
+	envDir, err := system.GetEnvDir()
 	if err != nil {
 		return envList, err
 	}
-	curEnv, err := GetCurrentEnvName()
+	files, err := ioutil.ReadDir(envDir)
 	if err != nil {
-		curEnv = types.DefaultEnvName
+		return envList, err
 	}
 	for _, f := range files {
 		if !f.IsDir() {
@@ -215,9 +310,6 @@ func ListEnvs(envName string) ([]*types.EnvMeta, error) {
 		if err = json.Unmarshal(data, &envMeta); err != nil {
 			continue
 		}
-		if curEnv == f.Name() {
-			envMeta.Current = "*"
-		}
 		envList = append(envList, &envMeta)
 	}
 	return envList, nil
@@ -236,8 +328,8 @@ func GetCurrentEnvName() (string, error) {
 	return string(data), nil
 }
 
-// DeleteEnv will delete env locally
-func DeleteEnv(envName string) (string, error) {
+// DeleteEnv will delete env from the cluster and locally
+func DeleteEnv(ctx context.Context, c client.Client, envName string) (string, error) {
 	var message string
 	var err error
 	curEnv, err := GetCurrentEnvName()
@@ -248,13 +340,17 @@ func DeleteEnv(envName string) (string, error) {
 		err = fmt.Errorf("you can't delete current using environment %s", curEnv)
 		return message, err
 	}
+	deleted, err := deleteEnvFromCluster(ctx, c, envName)
+	if err != nil {
+		return message, err
+	}
 	envdir, err := system.GetEnvDir()
 	if err != nil {
 		return message, err
 	}
 	envPath := filepath.Join(envdir, envName)
 	if _, err := os.Stat(envPath); err != nil {
-		if os.IsNotExist(err) {
+		if os.IsNotExist(err) && !deleted {
 			err = fmt.Errorf("%s does not exist", envName)
 			return message, err
 		}
@@ -0,0 +1,206 @@
+package env
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/types"
+)
+
+// Network isolation modes of env namespace
+const (
+	// IsolationOpen doesn't restrict traffic, it's the default
+	IsolationOpen = "open"
+	// IsolationNamespaceOnly only allows ingress traffic from pods in the same namespace and the ingress controller
+	IsolationNamespaceOnly = "namespace-only"
+	// IsolationDenyAll denies all ingress traffic to pods in the namespace
+	IsolationDenyAll = "deny-all"
+)
+
+// DefaultIngressNamespaceSelector selects the namespace created by the manifests of ingress-nginx
+var DefaultIngressNamespaceSelector = map[string]string{"app.kubernetes.io/name": "ingress-nginx"}
+
+// FormatGuardrailName returns the name of ResourceQuota, LimitRange and NetworkPolicy created for env
+func FormatGuardrailName(envName string) string {
+	return "vela-env-" + envName
+}
+
+// ValidateGuardrails checks quota, limits and isolation of env before anything is created
+func ValidateGuardrails(envMeta *types.EnvMeta) error {
+	switch envMeta.Isolation {
+	case "", IsolationOpen, IsolationNamespaceOnly, IsolationDenyAll:
+	default:
+		return fmt.Errorf("unknown isolation %s, must be one of %s, %s and %s", envMeta.Isolation,
+			IsolationOpen, IsolationNamespaceOnly, IsolationDenyAll)
+	}
+	for k, v := range envMeta.IngressNamespaceSelector {
+		if errs := validation.IsQualifiedName(k); len(errs) != 0 {
+			return fmt.Errorf("invalid ingress namespace selector %s=%s: %s", k, v, strings.Join(errs, ", "))
+		}
+		if errs := validation.IsValidLabelValue(v); len(errs) != 0 {
+			return fmt.Errorf("invalid ingress namespace selector %s=%s: %s", k, v, strings.Join(errs, ", "))
+		}
+	}
+	if _, err := toResourceList(envMeta.Quota); err != nil {
+		return fmt.Errorf("invalid quota: %w", err)
+	}
+	if envMeta.Limits != nil {
+		for _, l := range []map[string]string{envMeta.Limits.Default, envMeta.Limits.DefaultRequest, envMeta.Limits.Max} {
+			if _, err := toResourceList(l); err != nil {
+				return fmt.Errorf("invalid limits: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+func toResourceList(m map[string]string) (corev1.ResourceList, error) {
+	if len(m) == 0 {
+		return nil, nil
+	}
+	list := make(corev1.ResourceList, len(m))
+	for k, v := range m {
+		q, err := resource.ParseQuantity(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s=%s: %w", k, v, err)
+		}
+		list[corev1.ResourceName(k)] = q
+	}
+	return list, nil
+}
+
+// applyGuardrails creates, updates or deletes ResourceQuota, LimitRange and NetworkPolicy in the env namespace,
+// so they always match the settings of env
+func applyGuardrails(ctx context.Context, c client.Client, envMeta *types.EnvMeta) error {
+	meta := metav1.ObjectMeta{
+		Name:      FormatGuardrailName(envMeta.Name),
+		Namespace: envMeta.Namespace,
+		Labels:    map[string]string{LabelEnvName: envMeta.Name},
+	}
+
+	hard, err := toResourceList(envMeta.Quota)
+	if err != nil {
+		return err
+	}
+	quota := &corev1.ResourceQuota{ObjectMeta: *meta.DeepCopy(), Spec: corev1.ResourceQuotaSpec{Hard: hard}}
+	if err = applyOrDelete(ctx, c, quota, len(hard) > 0, func(exist object) {
+		exist.(*corev1.ResourceQuota).Spec = quota.Spec
+	}); err != nil {
+		return err
+	}
+
+	limitRange := &corev1.LimitRange{ObjectMeta: *meta.DeepCopy()}
+	if envMeta.Limits != nil {
+		item := corev1.LimitRangeItem{Type: corev1.LimitTypeContainer}
+		if item.Default, err = toResourceList(envMeta.Limits.Default); err != nil {
+			return err
+		}
+		if item.DefaultRequest, err = toResourceList(envMeta.Limits.DefaultRequest); err != nil {
+			return err
+		}
+		if item.Max, err = toResourceList(envMeta.Limits.Max); err != nil {
+			return err
+		}
+		if len(item.Default)+len(item.DefaultRequest)+len(item.Max) > 0 {
+			limitRange.Spec.Limits = []corev1.LimitRangeItem{item}
+		}
+	}
+	if err = applyOrDelete(ctx, c, limitRange, len(limitRange.Spec.Limits) > 0, func(exist object) {
+		exist.(*corev1.LimitRange).Spec = limitRange.Spec
+	}); err != nil {
+		return err
+	}
+
+	policy := &networkingv1.NetworkPolicy{
+		ObjectMeta: *meta.DeepCopy(),
+		Spec: networkingv1.NetworkPolicySpec{
+			// select all pods in the namespace
+			PodSelector: metav1.LabelSelector{},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+		},
+	}
+	if envMeta.Isolation == IsolationNamespaceOnly {
+		// routes are served by the ingress controller, which runs in its own namespace
+		ingressNamespace := envMeta.IngressNamespaceSelector
+		if len(ingressNamespace) == 0 {
+			ingressNamespace = DefaultIngressNamespaceSelector
+		}
+		policy.Spec.Ingress = []networkingv1.NetworkPolicyIngressRule{{
+			From: []networkingv1.NetworkPolicyPeer{
+				{PodSelector: &metav1.LabelSelector{}},
+				{NamespaceSelector: &metav1.LabelSelector{MatchLabels: ingressNamespace}},
+			},
+		}}
+	}
+	isolated := envMeta.Isolation == IsolationNamespaceOnly || envMeta.Isolation == IsolationDenyAll
+	return applyOrDelete(ctx, c, policy, isolated, func(exist object) {
+		exist.(*networkingv1.NetworkPolicy).Spec = policy.Spec
+	})
+}
+
+// object is an API object with metadata
+type object interface {
+	runtime.Object
+	metav1.Object
+}
+
+// applyOrDelete creates or updates obj if it's wanted, otherwise deletes it, update copies the desired spec into the existing object
+func applyOrDelete(ctx context.Context, c client.Client, obj object, wanted bool, update func(exist object)) error {
+	if !wanted {
+		if err := c.Delete(ctx, obj); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		return nil
+	}
+	exist := obj.DeepCopyObject().(object)
+	if err := c.Get(ctx, client.ObjectKey{Namespace: obj.GetNamespace(), Name: obj.GetName()}, exist); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		return c.Create(ctx, obj)
+	}
+	update(exist)
+	exist.SetLabels(obj.GetLabels())
+	return c.Update(ctx, exist)
+}
+
+// QuotaUsage describes usage against the quota of env, e.g. `cpu: 1/4, pods: 3/20`, it's empty if the env has no quota
+func QuotaUsage(ctx context.Context, c client.Client, envMeta *types.EnvMeta) (string, error) {
+	if len(envMeta.Quota) == 0 {
+		return "", nil
+	}
+	envClient, err := newEnvClient(c, envMeta)
+	if err != nil {
+		return "", err
+	}
+	var quota corev1.ResourceQuota
+	if err := envClient.Get(ctx, client.ObjectKey{Namespace: envMeta.Namespace, Name: FormatGuardrailName(envMeta.Name)}, &quota); err != nil {
+		return "", err
+	}
+	names := make([]string, 0, len(quota.Spec.Hard))
+	for name := range quota.Spec.Hard {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+	usage := make([]string, 0, len(names))
+	for _, name := range names {
+		hard := quota.Spec.Hard[corev1.ResourceName(name)]
+		used := "0"
+		if q, ok := quota.Status.Used[corev1.ResourceName(name)]; ok {
+			used = q.String()
+		}
+		usage = append(usage, fmt.Sprintf("%s: %s/%s", name, used, hard.String()))
+	}
+	return strings.Join(usage, ", "), nil
+}
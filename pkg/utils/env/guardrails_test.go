@@ -0,0 +1,98 @@
+package env
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/apis/types"
+)
+
+func TestGuardrails(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	assert.NoError(t, clientgoscheme.AddToScheme(scheme))
+	c := fake.NewFakeClientWithScheme(scheme)
+	key := client.ObjectKey{Namespace: "prod", Name: "vela-env-prod"}
+
+	envMeta := &types.EnvMeta{
+		Name:      "prod",
+		Namespace: "prod",
+		Quota:     map[string]string{"cpu": "4", "pods": "20"},
+		Limits:    &types.LimitConfig{Default: map[string]string{"memory": "512Mi"}},
+		Isolation: IsolationNamespaceOnly,
+	}
+	assert.NoError(t, ValidateGuardrails(envMeta))
+	assert.NoError(t, applyGuardrails(ctx, c, envMeta))
+
+	var quota corev1.ResourceQuota
+	assert.NoError(t, c.Get(ctx, key, &quota))
+	assert.Equal(t, corev1.ResourceList{
+		corev1.ResourceCPU:  resource.MustParse("4"),
+		corev1.ResourcePods: resource.MustParse("20"),
+	}, quota.Spec.Hard)
+	var limitRange corev1.LimitRange
+	assert.NoError(t, c.Get(ctx, key, &limitRange))
+	assert.Equal(t, []corev1.LimitRangeItem{{
+		Type:    corev1.LimitTypeContainer,
+		Default: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("512Mi")},
+	}}, limitRange.Spec.Limits)
+	var policy networkingv1.NetworkPolicy
+	assert.NoError(t, c.Get(ctx, key, &policy))
+	// traffic from the same namespace and the ingress controller is allowed
+	assert.Equal(t, []networkingv1.NetworkPolicyIngressRule{{From: []networkingv1.NetworkPolicyPeer{
+		{PodSelector: &metav1.LabelSelector{}},
+		{NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app.kubernetes.io/name": "ingress-nginx"}}},
+	}}}, policy.Spec.Ingress)
+
+	quota.Status.Used = corev1.ResourceList{corev1.ResourcePods: resource.MustParse("3")}
+	assert.NoError(t, c.Update(ctx, &quota))
+	usage, err := QuotaUsage(ctx, c, envMeta)
+	assert.NoError(t, err)
+	assert.Equal(t, "cpu: 0/4, pods: 3/20", usage)
+
+	// the namespace of the ingress controller is configurable
+	envMeta.IngressNamespaceSelector = map[string]string{"name": "projectcontour"}
+	assert.NoError(t, ValidateGuardrails(envMeta))
+	assert.NoError(t, applyGuardrails(ctx, c, envMeta))
+	policy = networkingv1.NetworkPolicy{}
+	assert.NoError(t, c.Get(ctx, key, &policy))
+	assert.Equal(t, &metav1.LabelSelector{MatchLabels: map[string]string{"name": "projectcontour"}},
+		policy.Spec.Ingress[0].From[1].NamespaceSelector)
+
+	// the objects follow changes of env
+	envMeta.Quota = map[string]string{"pods": "10"}
+	envMeta.Limits = nil
+	envMeta.Isolation = IsolationDenyAll
+	assert.NoError(t, applyGuardrails(ctx, c, envMeta))
+	quota, limitRange, policy = corev1.ResourceQuota{}, corev1.LimitRange{}, networkingv1.NetworkPolicy{}
+	assert.NoError(t, c.Get(ctx, key, &quota))
+	assert.Equal(t, corev1.ResourceList{corev1.ResourcePods: resource.MustParse("10")}, quota.Spec.Hard)
+	assert.True(t, apierrors.IsNotFound(c.Get(ctx, key, &limitRange)))
+	assert.NoError(t, c.Get(ctx, key, &policy))
+	assert.Equal(t, 0, len(policy.Spec.Ingress))
+
+	envMeta.Quota = nil
+	envMeta.Isolation = IsolationOpen
+	assert.NoError(t, applyGuardrails(ctx, c, envMeta))
+	assert.True(t, apierrors.IsNotFound(c.Get(ctx, key, &quota)))
+	assert.True(t, apierrors.IsNotFound(c.Get(ctx, key, &policy)))
+	usage, err = QuotaUsage(ctx, c, envMeta)
+	assert.NoError(t, err)
+	assert.Equal(t, "", usage)
+
+	assert.Error(t, ValidateGuardrails(&types.EnvMeta{Isolation: "closed"}))
+	assert.Error(t, ValidateGuardrails(&types.EnvMeta{IngressNamespaceSelector: map[string]string{"name": "ingress nginx"}}))
+	assert.Error(t, ValidateGuardrails(&types.EnvMeta{Quota: map[string]string{"cpu": "four"}}))
+	assert.Error(t, ValidateGuardrails(&types.EnvMeta{Limits: &types.LimitConfig{Max: map[string]string{"memory": "1Gx"}}}))
+}
@@ -0,0 +1,29 @@
+package env
+
+import (
+	"strings"
+
+	"github.com/oam-dev/kubevela/apis/types"
+)
+
+// DefaultHostPattern generates hosts of routes if env doesn't specify a pattern
+const DefaultHostPattern = "{service}.{app}.{domain}"
+
+// RenderHost generates the host of a route which doesn't have an explicit host, by replacing {service}, {app}, {env}
+// and {domain} in the host pattern of env. It's empty if the env has no domain.
+func RenderHost(envMeta *types.EnvMeta, appName, serviceName string) string {
+	domain := strings.TrimPrefix(strings.TrimPrefix(envMeta.Domain, "https://"), "http://")
+	if domain == "" {
+		return ""
+	}
+	pattern := envMeta.HostPattern
+	if pattern == "" {
+		pattern = DefaultHostPattern
+	}
+	return strings.NewReplacer(
+		"{service}", serviceName,
+		"{app}", appName,
+		"{env}", envMeta.Name,
+		"{domain}", domain,
+	).Replace(pattern)
+}
@@ -0,0 +1,35 @@
+package env
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/oam-dev/kubevela/apis/types"
+)
+
+func TestRenderHost(t *testing.T) {
+	testCases := map[string]struct {
+		env    *types.EnvMeta
+		expect string
+	}{
+		"no domain": {
+			env: &types.EnvMeta{Name: "prod"},
+		},
+		"default pattern": {
+			env:    &types.EnvMeta{Name: "prod", Domain: "example.com"},
+			expect: "frontend.myapp.example.com",
+		},
+		"domain with scheme": {
+			env:    &types.EnvMeta{Name: "prod", Domain: "https://example.com"},
+			expect: "frontend.myapp.example.com",
+		},
+		"custom pattern": {
+			env:    &types.EnvMeta{Name: "prod", Domain: "example.com", HostPattern: "{app}-{service}.{env}.{domain}"},
+			expect: "myapp-frontend.prod.example.com",
+		},
+	}
+	for name, tc := range testCases {
+		assert.Equal(t, tc.expect, RenderHost(tc.env, "myapp", "frontend"), name)
+	}
+}
@@ -0,0 +1,157 @@
+package env
+
+import (
+	"fmt"
+
+	acmev1 "github.com/wonderflow/cert-manager-api/pkg/apis/acme/v1"
+	certmanager "github.com/wonderflow/cert-manager-api/pkg/apis/certmanager/v1"
+	v1 "github.com/wonderflow/cert-manager-api/pkg/apis/meta/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+
+	"github.com/oam-dev/kubevela/apis/standard.oam.dev/v1alpha1"
+	"github.com/oam-dev/kubevela/apis/types"
+)
+
+// StagingACMEServer is the staging ACME Server from let's encrypt, it has much higher rate limits for testing
+const StagingACMEServer = "https://acme-staging-v02.api.letsencrypt.org/directory"
+
+// Kinds of issuer created for env
+const (
+	// IssuerACME issues certificates from the production ACME server, it's the default
+	IssuerACME = "acme"
+	// IssuerACMEStaging issues certificates from the staging ACME server, they're not trusted by browsers
+	IssuerACMEStaging = "acme-staging"
+	// IssuerCA signs certificates by a CA key pair in a Secret
+	IssuerCA = "ca"
+	// IssuerSelfSigned signs certificates by their own private keys
+	IssuerSelfSigned = "selfsigned"
+)
+
+// DNS providers of ACME DNS01 challenges
+const (
+	DNS01ProviderCloudflare   = "cloudflare"
+	DNS01ProviderDigitalOcean = "digitalocean"
+)
+
+// DefaultRouteProvider serves routes if env doesn't specify one
+const DefaultRouteProvider = "nginx"
+
+// FormatIssuerName returns the name of issuer created for env
+func FormatIssuerName(envName string) string {
+	return "oam-env-" + envName
+}
+
+// ValidateIssuerConfig checks the issuer config of env before anything is created
+func ValidateIssuerConfig(envMeta *types.EnvMeta) error {
+	cfg := envMeta.IssuerConfig
+	if cfg == nil {
+		return nil
+	}
+	switch cfg.Kind {
+	case "", IssuerACME, IssuerACMEStaging:
+	case IssuerCA:
+		if cfg.CASecret == "" {
+			return fmt.Errorf("issuer %s requires the Secret of CA key pair", IssuerCA)
+		}
+	case IssuerSelfSigned:
+	default:
+		return fmt.Errorf("unknown issuer %s, must be one of %s, %s, %s and %s", cfg.Kind,
+			IssuerACME, IssuerACMEStaging, IssuerCA, IssuerSelfSigned)
+	}
+	if cfg.DNS01 != nil {
+		if !isACME(cfg.Kind) {
+			return fmt.Errorf("DNS01 solver only works with issuer %s and %s", IssuerACME, IssuerACMEStaging)
+		}
+		switch cfg.DNS01.Provider {
+		case DNS01ProviderCloudflare, DNS01ProviderDigitalOcean:
+		default:
+			return fmt.Errorf("unknown DNS01 provider %s, must be one of %s and %s", cfg.DNS01.Provider,
+				DNS01ProviderCloudflare, DNS01ProviderDigitalOcean)
+		}
+		if cfg.DNS01.SecretName == "" || cfg.DNS01.SecretKey == "" {
+			return fmt.Errorf("DNS01 solver requires the Secret and key of the API token of %s", cfg.DNS01.Provider)
+		}
+	}
+	return nil
+}
+
+func isACME(kind string) bool {
+	return kind == "" || kind == IssuerACME || kind == IssuerACMEStaging
+}
+
+// buildIssuer returns the issuer for routes of env, it's nil if the env doesn't need one,
+// e.g. an ACME issuer is only built if both email and domain are set.
+func buildIssuer(envMeta *types.EnvMeta) *certmanager.Issuer {
+	cfg := envMeta.IssuerConfig
+	if cfg == nil {
+		cfg = &types.IssuerConfig{}
+	}
+	if cfg.ClusterIssuer != "" {
+		return nil
+	}
+	var issuerConfig certmanager.IssuerConfig
+	switch cfg.Kind {
+	case IssuerCA:
+		issuerConfig.CA = &certmanager.CAIssuer{SecretName: cfg.CASecret}
+	case IssuerSelfSigned:
+		issuerConfig.SelfSigned = &certmanager.SelfSignedIssuer{}
+	default:
+		if envMeta.Email == "" || envMeta.Domain == "" {
+			return nil
+		}
+		server := ProductionACMEServer
+		if cfg.Kind == IssuerACMEStaging {
+			server = StagingACMEServer
+		}
+		issuerConfig.ACME = &acmev1.ACMEIssuer{
+			Email:  envMeta.Email,
+			Server: server,
+			PrivateKey: v1.SecretKeySelector{
+				LocalObjectReference: v1.LocalObjectReference{Name: FormatIssuerName(envMeta.Name) + ".key"},
+			},
+			Solvers: []acmev1.ACMEChallengeSolver{acmeSolver(envMeta, cfg)},
+		}
+	}
+	return &certmanager.Issuer{
+		ObjectMeta: metav1.ObjectMeta{Name: FormatIssuerName(envMeta.Name), Namespace: envMeta.Namespace},
+		Spec:       certmanager.IssuerSpec{IssuerConfig: issuerConfig},
+	}
+}
+
+func acmeSolver(envMeta *types.EnvMeta, cfg *types.IssuerConfig) acmev1.ACMEChallengeSolver {
+	if cfg.DNS01 != nil {
+		token := v1.SecretKeySelector{
+			LocalObjectReference: v1.LocalObjectReference{Name: cfg.DNS01.SecretName},
+			Key:                  cfg.DNS01.SecretKey,
+		}
+		dns01 := &acmev1.ACMEChallengeSolverDNS01{}
+		switch cfg.DNS01.Provider {
+		case DNS01ProviderCloudflare:
+			dns01.Cloudflare = &acmev1.ACMEIssuerDNS01ProviderCloudflare{APIToken: &token}
+		case DNS01ProviderDigitalOcean:
+			dns01.DigitalOcean = &acmev1.ACMEIssuerDNS01ProviderDigitalOcean{Token: token}
+		}
+		return acmev1.ACMEChallengeSolver{DNS01: dns01}
+	}
+	ingressClass := cfg.IngressClass
+	if ingressClass == "" {
+		ingressClass = envMeta.RouteProvider
+	}
+	if ingressClass == "" {
+		ingressClass = DefaultRouteProvider
+	}
+	return acmev1.ACMEChallengeSolver{
+		HTTP01: &acmev1.ACMEChallengeSolverHTTP01{
+			Ingress: &acmev1.ACMEChallengeSolverHTTP01Ingress{Class: pointer.StringPtr(ingressClass)},
+		},
+	}
+}
+
+// issuerType returns how routes refer to the issuer of env
+func issuerType(envMeta *types.EnvMeta) string {
+	if envMeta.IssuerConfig != nil && envMeta.IssuerConfig.ClusterIssuer != "" {
+		return string(v1alpha1.ClusterIssuer)
+	}
+	return string(v1alpha1.NamespaceIssuer)
+}
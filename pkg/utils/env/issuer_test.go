@@ -0,0 +1,86 @@
+package env
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	acmev1 "github.com/wonderflow/cert-manager-api/pkg/apis/acme/v1"
+	certmanager "github.com/wonderflow/cert-manager-api/pkg/apis/certmanager/v1"
+	v1 "github.com/wonderflow/cert-manager-api/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+
+	"github.com/oam-dev/kubevela/apis/types"
+)
+
+func TestBuildIssuer(t *testing.T) {
+	accountKey := v1.SecretKeySelector{LocalObjectReference: v1.LocalObjectReference{Name: "oam-env-prod.key"}}
+	testCases := map[string]struct {
+		env    *types.EnvMeta
+		expect *certmanager.IssuerConfig
+	}{
+		"acme without email": {
+			env: &types.EnvMeta{Name: "prod", Domain: "example.com"},
+		},
+		"acme by default": {
+			env: &types.EnvMeta{Name: "prod", Email: "a@example.com", Domain: "example.com"},
+			expect: &certmanager.IssuerConfig{ACME: &acmev1.ACMEIssuer{
+				Email: "a@example.com", Server: ProductionACMEServer, PrivateKey: accountKey,
+				Solvers: []acmev1.ACMEChallengeSolver{{HTTP01: &acmev1.ACMEChallengeSolverHTTP01{
+					Ingress: &acmev1.ACMEChallengeSolverHTTP01Ingress{Class: pointer.StringPtr("nginx")}}}},
+			}},
+		},
+		"acme staging with ingress class of route provider": {
+			env: &types.EnvMeta{Name: "prod", Email: "a@example.com", Domain: "example.com", RouteProvider: "contour",
+				IssuerConfig: &types.IssuerConfig{Kind: IssuerACMEStaging}},
+			expect: &certmanager.IssuerConfig{ACME: &acmev1.ACMEIssuer{
+				Email: "a@example.com", Server: StagingACMEServer, PrivateKey: accountKey,
+				Solvers: []acmev1.ACMEChallengeSolver{{HTTP01: &acmev1.ACMEChallengeSolverHTTP01{
+					Ingress: &acmev1.ACMEChallengeSolverHTTP01Ingress{Class: pointer.StringPtr("contour")}}}},
+			}},
+		},
+		"acme with DNS01": {
+			env: &types.EnvMeta{Name: "prod", Email: "a@example.com", Domain: "example.com",
+				IssuerConfig: &types.IssuerConfig{DNS01: &types.DNS01Solver{Provider: DNS01ProviderCloudflare, SecretName: "cf", SecretKey: "token"}}},
+			expect: &certmanager.IssuerConfig{ACME: &acmev1.ACMEIssuer{
+				Email: "a@example.com", Server: ProductionACMEServer, PrivateKey: accountKey,
+				Solvers: []acmev1.ACMEChallengeSolver{{DNS01: &acmev1.ACMEChallengeSolverDNS01{
+					Cloudflare: &acmev1.ACMEIssuerDNS01ProviderCloudflare{APIToken: &v1.SecretKeySelector{
+						LocalObjectReference: v1.LocalObjectReference{Name: "cf"}, Key: "token"}}}}},
+			}},
+		},
+		"ca": {
+			env:    &types.EnvMeta{Name: "prod", IssuerConfig: &types.IssuerConfig{Kind: IssuerCA, CASecret: "ca-key-pair"}},
+			expect: &certmanager.IssuerConfig{CA: &certmanager.CAIssuer{SecretName: "ca-key-pair"}},
+		},
+		"selfsigned": {
+			env:    &types.EnvMeta{Name: "prod", IssuerConfig: &types.IssuerConfig{Kind: IssuerSelfSigned}},
+			expect: &certmanager.IssuerConfig{SelfSigned: &certmanager.SelfSignedIssuer{}},
+		},
+		"cluster issuer": {
+			env: &types.EnvMeta{Name: "prod", Email: "a@example.com", Domain: "example.com",
+				IssuerConfig: &types.IssuerConfig{ClusterIssuer: "letsencrypt"}},
+		},
+	}
+	for name, tc := range testCases {
+		assert.NoError(t, ValidateIssuerConfig(tc.env), name)
+		issuer := buildIssuer(tc.env)
+		if tc.expect == nil {
+			assert.Nil(t, issuer, name)
+			continue
+		}
+		assert.Equal(t, "oam-env-prod", issuer.Name, name)
+		assert.Equal(t, *tc.expect, issuer.Spec.IssuerConfig, name)
+	}
+}
+
+func TestValidateIssuerConfig(t *testing.T) {
+	for name, cfg := range map[string]*types.IssuerConfig{
+		"unknown kind":           {Kind: "vault"},
+		"ca without key pair":    {Kind: IssuerCA},
+		"DNS01 with selfsigned":  {Kind: IssuerSelfSigned, DNS01: &types.DNS01Solver{Provider: DNS01ProviderCloudflare, SecretName: "cf", SecretKey: "token"}},
+		"unknown DNS01 provider": {DNS01: &types.DNS01Solver{Provider: "bind", SecretName: "cf", SecretKey: "token"}},
+		"DNS01 without Secret":   {DNS01: &types.DNS01Solver{Provider: DNS01ProviderDigitalOcean}},
+	} {
+		assert.Error(t, ValidateIssuerConfig(&types.EnvMeta{Name: "prod", IssuerConfig: cfg}), name)
+	}
+}
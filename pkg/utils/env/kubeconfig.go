@@ -0,0 +1,90 @@
+package env
+
+import (
+	"flag"
+	"fmt"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlconfig "sigs.k8s.io/controller-runtime/pkg/client/config"
+
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+// IsBoundToCluster tells whether the env targets a cluster other than the one of the kubeconfig vela runs with
+func IsBoundToCluster(envMeta *types.EnvMeta) bool {
+	return envMeta.KubeConfig != "" || envMeta.KubeContext != "" || (envMeta.Cluster != nil && envMeta.Cluster.Server != "")
+}
+
+// RestConfig returns the config to access the cluster of env, base is returned if the env isn't bound to a cluster.
+// The kubeconfig and context recorded in env are loaded the same way as kubectl does, the context is looked up in
+// the kubeconfig vela runs with if env doesn't record a kubeconfig. The inline cluster overrides the server of
+// the context, or of base if env records neither a kubeconfig nor a context.
+func RestConfig(envMeta *types.EnvMeta, base *rest.Config) (*rest.Config, error) {
+	if !IsBoundToCluster(envMeta) {
+		return base, nil
+	}
+	var restConf *rest.Config
+	if envMeta.KubeConfig == "" && envMeta.KubeContext == "" && base != nil {
+		restConf = rest.CopyConfig(base)
+	} else {
+		loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+		loadingRules.ExplicitPath = envMeta.KubeConfig
+		if loadingRules.ExplicitPath == "" {
+			loadingRules.ExplicitPath = kubeConfigFlag()
+		}
+		overrides := &clientcmd.ConfigOverrides{CurrentContext: envMeta.KubeContext}
+		var err error
+		restConf, err = clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides).ClientConfig()
+		if err != nil {
+			return nil, fmt.Errorf("load cluster of env %s err %w", envMeta.Name, err)
+		}
+	}
+	if envMeta.Cluster != nil && envMeta.Cluster.Server != "" {
+		restConf.Host = envMeta.Cluster.Server
+		restConf.TLSClientConfig.ServerName = ""
+		if len(envMeta.Cluster.CertificateAuthorityData) != 0 || envMeta.Cluster.InsecureSkipTLSVerify {
+			restConf.TLSClientConfig.CAFile = ""
+			restConf.TLSClientConfig.CAData = envMeta.Cluster.CertificateAuthorityData
+		}
+		restConf.TLSClientConfig.Insecure = envMeta.Cluster.InsecureSkipTLSVerify
+	}
+	return restConf, nil
+}
+
+// kubeConfigFlag returns --kubeconfig which vela runs with, the flag is registered by controller-runtime
+func kubeConfigFlag() string {
+	if f := flag.Lookup("kubeconfig"); f != nil {
+		return f.Value.String()
+	}
+	return ""
+}
+
+// ClusterName describes the cluster of env for display, it's empty if the env isn't bound to a cluster
+func ClusterName(envMeta *types.EnvMeta) string {
+	if envMeta.Cluster != nil && envMeta.Cluster.Server != "" {
+		return envMeta.Cluster.Server
+	}
+	if envMeta.KubeContext != "" {
+		return envMeta.KubeContext
+	}
+	return envMeta.KubeConfig
+}
+
+// newEnvClient creates a client for the cluster of env, c is returned if the env isn't bound to a cluster
+var newEnvClient = func(c client.Client, envMeta *types.EnvMeta) (client.Client, error) {
+	if !IsBoundToCluster(envMeta) {
+		return c, nil
+	}
+	base, err := ctrlconfig.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	restConf, err := RestConfig(envMeta, base)
+	if err != nil {
+		return nil, err
+	}
+	return client.New(restConf, client.Options{Scheme: common.Scheme})
+}
@@ -0,0 +1,120 @@
+package env
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/rest"
+
+	"github.com/oam-dev/kubevela/apis/types"
+)
+
+const testKubeConfig = `apiVersion: v1
+kind: Config
+current-context: dev
+clusters:
+- name: dev
+  cluster:
+    server: https://dev.example.com
+- name: prod
+  cluster:
+    server: https://prod.example.com
+users:
+- name: dev
+  user:
+    token: dev-token
+- name: prod
+  user:
+    token: prod-token
+contexts:
+- name: dev
+  context:
+    cluster: dev
+    user: dev
+- name: prod
+  context:
+    cluster: prod
+    user: prod
+`
+
+func TestRestConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vela-kubeconfig")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	kubeconfig := filepath.Join(dir, "config")
+	assert.NoError(t, ioutil.WriteFile(kubeconfig, []byte(testKubeConfig), 0600))
+
+	// vela runs with --kubeconfig
+	assert.NoError(t, flag.Set("kubeconfig", kubeconfig))
+	defer func() { _ = flag.Set("kubeconfig", "") }()
+
+	base := &rest.Config{Host: "https://base.example.com", BearerToken: "base-token",
+		TLSClientConfig: rest.TLSClientConfig{CAFile: "/etc/base/ca.crt"}}
+	testCases := map[string]struct {
+		env            *types.EnvMeta
+		expectHost     string
+		expectToken    string
+		expectInsecure bool
+		cluster        string
+	}{
+		"not bound": {
+			env:         &types.EnvMeta{Name: "default"},
+			expectHost:  "https://base.example.com",
+			expectToken: "base-token",
+		},
+		"current context of kubeconfig": {
+			env:         &types.EnvMeta{Name: "dev", KubeConfig: kubeconfig},
+			expectHost:  "https://dev.example.com",
+			expectToken: "dev-token",
+			cluster:     kubeconfig,
+		},
+		"context": {
+			env:         &types.EnvMeta{Name: "prod", KubeConfig: kubeconfig, KubeContext: "prod"},
+			expectHost:  "https://prod.example.com",
+			expectToken: "prod-token",
+			cluster:     "prod",
+		},
+		"context of the kubeconfig vela runs with": {
+			env:         &types.EnvMeta{Name: "prod", KubeContext: "prod"},
+			expectHost:  "https://prod.example.com",
+			expectToken: "prod-token",
+			cluster:     "prod",
+		},
+		"inline cluster": {
+			env: &types.EnvMeta{Name: "edge", KubeConfig: kubeconfig, KubeContext: "prod",
+				Cluster: &types.ClusterRef{Server: "https://edge.example.com", InsecureSkipTLSVerify: true}},
+			expectHost:     "https://edge.example.com",
+			expectToken:    "prod-token",
+			expectInsecure: true,
+			cluster:        "https://edge.example.com",
+		},
+		"inline cluster with credentials of base": {
+			env: &types.EnvMeta{Name: "edge",
+				Cluster: &types.ClusterRef{Server: "https://edge.example.com", InsecureSkipTLSVerify: true}},
+			expectHost:     "https://edge.example.com",
+			expectToken:    "base-token",
+			expectInsecure: true,
+			cluster:        "https://edge.example.com",
+		},
+	}
+	for name, tc := range testCases {
+		restConf, err := RestConfig(tc.env, base)
+		assert.NoError(t, err, name)
+		assert.Equal(t, tc.expectHost, restConf.Host, name)
+		assert.Equal(t, tc.expectToken, restConf.BearerToken, name)
+		assert.Equal(t, tc.expectInsecure, restConf.Insecure, name)
+		if tc.expectInsecure {
+			assert.Empty(t, restConf.CAFile, name)
+		}
+		assert.Equal(t, tc.cluster, ClusterName(tc.env), name)
+	}
+	// base isn't modified
+	assert.Equal(t, "https://base.example.com", base.Host)
+
+	_, err = RestConfig(&types.EnvMeta{Name: "test", KubeConfig: kubeconfig, KubeContext: "not-exist"}, base)
+	assert.Error(t, err)
+}
@@ -0,0 +1,82 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// DefaultBranch is synced if no branch is specified
+const DefaultBranch = "master"
+
+// allowedSchemes of repository URLs, local paths and scp-like addresses, e.g. git@github.com:foo/bar.git, are allowed too
+var allowedSchemes = map[string]bool{"https": true, "ssh": true, "file": true}
+
+// ValidateRepository checks the repository is a https, ssh or file URL, a scp-like ssh address or a local path,
+// so other transports of git, e.g. ext::, and options disguised as repositories are never run
+func ValidateRepository(repository string) error {
+	if repository == "" || strings.HasPrefix(repository, "-") {
+		return fmt.Errorf("invalid repository %q", repository)
+	}
+	if strings.Contains(repository, "://") {
+		u, err := url.Parse(repository)
+		if err != nil {
+			return fmt.Errorf("invalid repository %q: %w", repository, err)
+		}
+		if !allowedSchemes[u.Scheme] {
+			return fmt.Errorf("scheme %s of repository %s is not supported, must be one of https, ssh and file", u.Scheme, repository)
+		}
+		return nil
+	}
+	if strings.Contains(repository, "::") {
+		return fmt.Errorf("transport of repository %s is not supported, must be one of https, ssh and file", repository)
+	}
+	return nil
+}
+
+// run runs git with args in dir and returns its output, the error contains stderr of git
+func run(ctx context.Context, dir string, args ...string) (string, error) {
+	// #nosec
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	// never wait for credentials from the terminal, and never use other transports, e.g. for submodules
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_ALLOW_PROTOCOL=https:ssh:file")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s failed: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// HeadCommit returns the SHA of the latest commit of branch in repository without cloning it
+func HeadCommit(ctx context.Context, repository, branch string) (string, error) {
+	if err := ValidateRepository(repository); err != nil {
+		return "", err
+	}
+	out, err := run(ctx, "", "ls-remote", "--heads", "--", repository, "refs/heads/"+branch)
+	if err != nil {
+		return "", err
+	}
+	fields := strings.Fields(out)
+	if len(fields) == 0 {
+		return "", fmt.Errorf("branch %s not found in %s", branch, repository)
+	}
+	return fields[0], nil
+}
+
+// Clone clones the latest commit of branch in repository into dir, and returns the SHA of the commit
+func Clone(ctx context.Context, repository, branch, dir string) (string, error) {
+	if err := ValidateRepository(repository); err != nil {
+		return "", err
+	}
+	if _, err := run(ctx, "", "clone", "--quiet", "--depth", "1", "--branch", branch, "--", repository, dir); err != nil {
+		return "", err
+	}
+	return run(ctx, dir, "rev-parse", "HEAD")
+}
@@ -0,0 +1,77 @@
+package git
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newRepo creates a repository with a commit of files in it
+func newRepo(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "vela-git")
+	assert.NoError(t, err)
+	ctx := context.Background()
+	_, err = run(ctx, dir, "init", "--quiet")
+	assert.NoError(t, err)
+	for name, content := range files {
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600))
+	}
+	_, err = run(ctx, dir, "add", "-A")
+	assert.NoError(t, err)
+	_, err = run(ctx, dir, "-c", "user.name=vela", "-c", "user.email=vela@example.com", "commit", "--quiet", "-m", "init")
+	assert.NoError(t, err)
+	_, err = run(ctx, dir, "branch", "-M", DefaultBranch)
+	assert.NoError(t, err)
+	return dir
+}
+
+func TestClone(t *testing.T) {
+	ctx := context.Background()
+	repo := newRepo(t, map[string]string{"vela.yaml": "name: myapp\n"})
+	defer os.RemoveAll(repo)
+
+	head, err := HeadCommit(ctx, "file://"+repo, DefaultBranch)
+	assert.NoError(t, err)
+	assert.Len(t, head, 40)
+
+	dir, err := ioutil.TempDir("", "vela-git-clone")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	commit, err := Clone(ctx, "file://"+repo, DefaultBranch, filepath.Join(dir, "repo"))
+	assert.NoError(t, err)
+	assert.Equal(t, head, commit)
+	data, err := ioutil.ReadFile(filepath.Join(dir, "repo", "vela.yaml"))
+	assert.NoError(t, err)
+	assert.Equal(t, "name: myapp\n", string(data))
+
+	_, err = HeadCommit(ctx, "file://"+repo, "dev")
+	assert.EqualError(t, err, "branch dev not found in file://"+repo)
+	_, err = Clone(ctx, filepath.Join(repo, "not-exist"), DefaultBranch, filepath.Join(dir, "other"))
+	assert.Error(t, err)
+}
+
+func TestValidateRepository(t *testing.T) {
+	for _, repo := range []string{
+		"https://github.com/foo/apps.git",
+		"ssh://git@github.com/foo/apps.git",
+		"git@github.com:foo/apps.git",
+		"file:///srv/git/apps",
+		"/srv/git/apps",
+	} {
+		assert.NoError(t, ValidateRepository(repo), repo)
+	}
+	for repo, msg := range map[string]string{
+		"":                               `invalid repository ""`,
+		"--upload-pack=touch /tmp/pwned": `invalid repository "--upload-pack=touch /tmp/pwned"`,
+		"http://github.com/foo/apps.git": "scheme http of repository http://github.com/foo/apps.git is not supported, must be one of https, ssh and file",
+		"ext::sh -c touch% /tmp/pwned":   "transport of repository ext::sh -c touch% /tmp/pwned is not supported, must be one of https, ssh and file",
+	} {
+		assert.EqualError(t, ValidateRepository(repo), msg)
+	}
+	_, err := HeadCommit(context.Background(), "ext::sh -c true", DefaultBranch)
+	assert.Error(t, err)
+}
@@ -0,0 +1,184 @@
+package applicationconfiguration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+
+	"github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam/discoverymapper"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/appfile/template"
+)
+
+// ValidatingHandler checks that traits of ApplicationConfigurations apply to their workloads and don't conflict,
+// by the same rules as rendering Appfiles.
+type ValidatingHandler struct {
+	Client client.Client
+	Mapper discoverymapper.DiscoveryMapper
+
+	// Decoder decodes objects
+	Decoder *admission.Decoder
+}
+
+// log is for logging in this package.
+var validatelog = logf.Log.WithName("ApplicationConfiguration-validate")
+
+var _ admission.Handler = &ValidatingHandler{}
+
+// Handle handles admission requests.
+func (h *ValidatingHandler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	switch req.AdmissionRequest.Operation {
+	case admissionv1beta1.Create, admissionv1beta1.Update:
+	default:
+		// Do nothing for DELETE and CONNECT
+		return admission.ValidationResponse(true, "")
+	}
+
+	obj := &v1alpha2.ApplicationConfiguration{}
+	if err := h.Decoder.Decode(req, obj); err != nil {
+		validatelog.Error(err, "decoder failed", "req operation", req.AdmissionRequest.Operation, "req",
+			req.AdmissionRequest)
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	// AppConfigs being deleted are admitted, so finalizers could still be removed from ones which turned invalid
+	if obj.DeletionTimestamp != nil {
+		return admission.ValidationResponse(true, "")
+	}
+	// only changes of components are validated, so metadata updates, e.g. adding finalizers, aren't rejected
+	// for AppConfigs which turned invalid by changes of definitions or components
+	if req.AdmissionRequest.Operation == admissionv1beta1.Update {
+		old := &v1alpha2.ApplicationConfiguration{}
+		if err := h.Decoder.DecodeRaw(req.AdmissionRequest.OldObject, old); err != nil {
+			validatelog.Error(err, "decoder failed", "req operation", req.AdmissionRequest.Operation, "req",
+				req.AdmissionRequest)
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		if reflect.DeepEqual(old.Spec.Components, obj.Spec.Components) {
+			return admission.ValidationResponse(true, "")
+		}
+	}
+	// definitions which are not ready are not known by the template manager, their traits are not checked.
+	// Only AppliesTo, ConflictsWith and CrdInfo are needed, so templates are never downloaded on admission, and
+	// definitions are read from the informer cache of the manager's client.
+	tm, _, err := template.LoadDefinitionsFromCluster(ctx, h.Client, h.Mapper, obj.Namespace)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	allErrs, err := ValidateTraits(ctx, h.Client, tm, obj)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	if len(allErrs) > 0 {
+		validatelog.Info("validate failed", "name", obj.Name, "err", allErrs.ToAggregate().Error())
+		return admission.Errored(http.StatusUnprocessableEntity, allErrs.ToAggregate())
+	}
+	return admission.ValidationResponse(true, "")
+}
+
+// ValidateTraits checks traits of each component in the AppConfig with tm.ValidateTraits.
+// The workload type of a component is from the `workload.oam.dev/type` label of its workload, or the workload
+// definitions of the workload kind; the type of a trait is from its `trait.oam.dev/type` label, or the first trait
+// definition of the trait kind. Components not created yet and objects of unknown kinds are skipped.
+func ValidateTraits(ctx context.Context, c client.Reader, tm template.Manager, ac *v1alpha2.ApplicationConfiguration) (field.ErrorList, error) {
+	var allErrs field.ErrorList
+	for i, acc := range ac.Spec.Components {
+		fldPath := field.NewPath("spec", "components").Index(i).Child("traits")
+		var traits []string
+		for _, tr := range acc.Traits {
+			u, err := toUnstructured(tr.Trait)
+			if err != nil {
+				allErrs = append(allErrs, field.Invalid(fldPath, string(tr.Trait.Raw), err.Error()))
+				continue
+			}
+			if t := typeOf(tm, types.TypeTrait, u, oam.TraitTypeLabel); len(t) != 0 {
+				traits = append(traits, t[0])
+			}
+		}
+		if len(traits) == 0 {
+			continue
+		}
+
+		workloadTypes := []string{""}
+		var comp v1alpha2.Component
+		err := c.Get(ctx, client.ObjectKey{Namespace: ac.Namespace, Name: acc.ComponentName}, &comp)
+		switch {
+		case apierrors.IsNotFound(err):
+		case err != nil:
+			return nil, err
+		default:
+			u, err := toUnstructured(comp.Spec.Workload)
+			if err != nil {
+				return nil, err
+			}
+			if t := typeOf(tm, types.TypeWorkload, u, oam.WorkloadTypeLabel); len(t) != 0 {
+				workloadTypes = t
+			}
+		}
+		// traits are valid if they're valid for any of workload types which the workload could be
+		var verr error
+		for _, wt := range workloadTypes {
+			if verr = tm.ValidateTraits(wt, traits); verr == nil {
+				break
+			}
+		}
+		if verr != nil {
+			allErrs = append(allErrs, field.Forbidden(fldPath, verr.Error()))
+		}
+	}
+	return allErrs, nil
+}
+
+// typeOf returns the capability type of the object from the label, or types of capabilities of its kind
+func typeOf(tm template.Manager, captype types.CapType, u *unstructured.Unstructured, label string) []string {
+	if t, ok := u.GetLabels()[label]; ok && t != "" {
+		return []string{t}
+	}
+	return tm.MatchTypes(captype, u.GetAPIVersion(), u.GetKind())
+}
+
+func toUnstructured(raw runtime.RawExtension) (*unstructured.Unstructured, error) {
+	if u, ok := raw.Object.(*unstructured.Unstructured); ok {
+		return u, nil
+	}
+	data := raw.Raw
+	if raw.Object != nil {
+		var err error
+		if data, err = json.Marshal(raw.Object); err != nil {
+			return nil, err
+		}
+	}
+	u := &unstructured.Unstructured{}
+	if err := json.Unmarshal(data, &u.Object); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+var _ inject.Client = &ValidatingHandler{}
+
+// InjectClient injects the client into the ValidatingHandler
+func (h *ValidatingHandler) InjectClient(c client.Client) error {
+	h.Client = c
+	return nil
+}
+
+var _ admission.DecoderInjector = &ValidatingHandler{}
+
+// InjectDecoder injects the decoder into the ValidatingHandler
+func (h *ValidatingHandler) InjectDecoder(d *admission.Decoder) error {
+	h.Decoder = d
+	return nil
+}
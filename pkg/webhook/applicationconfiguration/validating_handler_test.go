@@ -0,0 +1,150 @@
+package applicationconfiguration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/crossplane/oam-kubernetes-runtime/apis/core/v1alpha2"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam"
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam/mock"
+	"github.com/stretchr/testify/assert"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+func TestHandle(t *testing.T) {
+	deployment := `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"labels":{"workload.oam.dev/type":"worker"}}}`
+	c := fake.NewFakeClientWithScheme(common.Scheme,
+		&v1alpha2.WorkloadDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: "webservice", Namespace: "vela-system"},
+			Spec:       v1alpha2.WorkloadDefinitionSpec{Reference: v1alpha2.DefinitionReference{Name: "deployments.apps"}},
+		},
+		&v1alpha2.WorkloadDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: "vela-system"},
+			Spec:       v1alpha2.WorkloadDefinitionSpec{Reference: v1alpha2.DefinitionReference{Name: "deployments.apps"}},
+		},
+		&v1alpha2.TraitDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: "route", Namespace: "vela-system"},
+			Spec: v1alpha2.TraitDefinitionSpec{
+				Reference:          v1alpha2.DefinitionReference{Name: "routes.standard.oam.dev"},
+				AppliesToWorkloads: []string{"webservice"},
+			},
+		},
+		&v1alpha2.TraitDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: "scaler", Namespace: "vela-system"},
+			Spec: v1alpha2.TraitDefinitionSpec{
+				Reference: v1alpha2.DefinitionReference{Name: "manualscalertraits.core.oam.dev"},
+				Extension: &runtime.RawExtension{Raw: []byte(`{"conflictsWith":["autoscale"]}`)},
+			},
+		},
+		&v1alpha2.TraitDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: "autoscale", Namespace: "vela-system"},
+			Spec:       v1alpha2.TraitDefinitionSpec{Reference: v1alpha2.DefinitionReference{Name: "autoscalers.standard.oam.dev"}},
+		},
+		&v1alpha2.Component{
+			ObjectMeta: metav1.ObjectMeta{Name: "backend", Namespace: "default"},
+			Spec:       v1alpha2.ComponentSpec{Workload: runtime.RawExtension{Raw: []byte(deployment)}},
+		},
+		&v1alpha2.Component{
+			ObjectMeta: metav1.ObjectMeta{Name: "frontend", Namespace: "default"},
+			Spec:       v1alpha2.ComponentSpec{Workload: runtime.RawExtension{Raw: []byte(`{"apiVersion":"apps/v1","kind":"Deployment"}`)}},
+		},
+	)
+	dm := mock.NewMockDiscoveryMapper()
+	dm.MockKindsFor = func(gvr schema.GroupVersionResource) ([]schema.GroupVersionKind, error) {
+		kinds := map[string]string{"deployments": "Deployment", "routes": "Route",
+			"manualscalertraits": "ManualScalerTrait", "autoscalers": "Autoscaler"}
+		return []schema.GroupVersionKind{{Group: gvr.Group, Version: "v1", Kind: kinds[gvr.Resource]}}, nil
+	}
+	decoder, err := admission.NewDecoder(common.Scheme)
+	assert.NoError(t, err)
+	h := &ValidatingHandler{Client: c, Mapper: dm, Decoder: decoder}
+
+	trait := func(data string) v1alpha2.ComponentTrait {
+		return v1alpha2.ComponentTrait{Trait: runtime.RawExtension{Raw: []byte(data)}}
+	}
+	route := trait(`{"apiVersion":"standard.oam.dev/v1","kind":"Route"}`)
+	scaler := trait(`{"apiVersion":"core.oam.dev/v1","kind":"ManualScalerTrait"}`)
+	autoscale := trait(`{"apiVersion":"standard.oam.dev/v1","kind":"Autoscaler","metadata":{"labels":{"` +
+		oam.TraitTypeLabel + `":"autoscale"}}}`)
+	appConfig := func(components ...v1alpha2.ApplicationConfigurationComponent) *v1alpha2.ApplicationConfiguration {
+		return &v1alpha2.ApplicationConfiguration{
+			TypeMeta:   metav1.TypeMeta{APIVersion: v1alpha2.SchemeGroupVersion.String(), Kind: v1alpha2.ApplicationConfigurationKind},
+			ObjectMeta: metav1.ObjectMeta{Name: "myapp", Namespace: "default"},
+			Spec:       v1alpha2.ApplicationConfigurationSpec{Components: components},
+		}
+	}
+	raw := func(ac *v1alpha2.ApplicationConfiguration) runtime.RawExtension {
+		if ac == nil {
+			return runtime.RawExtension{}
+		}
+		data, err := json.Marshal(ac)
+		assert.NoError(t, err)
+		return runtime.RawExtension{Raw: data}
+	}
+	handleUpdate := func(old, ac *v1alpha2.ApplicationConfiguration) admission.Response {
+		return h.Handle(context.Background(), admission.Request{AdmissionRequest: admissionv1beta1.AdmissionRequest{
+			Operation: admissionv1beta1.Update,
+			Object:    raw(ac),
+			OldObject: raw(old),
+		}})
+	}
+	handle := func(op admissionv1beta1.Operation, components ...v1alpha2.ApplicationConfigurationComponent) admission.Response {
+		var ac *v1alpha2.ApplicationConfiguration
+		if op != admissionv1beta1.Delete {
+			ac = appConfig(components...)
+		}
+		return h.Handle(context.Background(), admission.Request{AdmissionRequest: admissionv1beta1.AdmissionRequest{
+			Operation: op,
+			Object:    raw(ac),
+		}})
+	}
+
+	// the frontend could be a webservice, which a route applies to
+	resp := handle(admissionv1beta1.Create,
+		v1alpha2.ApplicationConfigurationComponent{ComponentName: "frontend", Traits: []v1alpha2.ComponentTrait{route, scaler}},
+		v1alpha2.ApplicationConfigurationComponent{ComponentName: "backend", Traits: []v1alpha2.ComponentTrait{scaler}},
+		// components not created yet are not checked against appliesTo
+		v1alpha2.ApplicationConfigurationComponent{ComponentName: "unknown", Traits: []v1alpha2.ComponentTrait{route}},
+	)
+	assert.True(t, resp.Allowed, resp.Result)
+
+	valid := appConfig(v1alpha2.ApplicationConfigurationComponent{ComponentName: "frontend", Traits: []v1alpha2.ComponentTrait{route}})
+	invalid := appConfig(
+		v1alpha2.ApplicationConfigurationComponent{ComponentName: "backend", Traits: []v1alpha2.ComponentTrait{route}},
+		v1alpha2.ApplicationConfigurationComponent{ComponentName: "frontend", Traits: []v1alpha2.ComponentTrait{scaler, autoscale}},
+	)
+	resp = handleUpdate(valid, invalid)
+	assert.False(t, resp.Allowed)
+	assert.Equal(t, int32(http.StatusUnprocessableEntity), resp.Result.Code)
+	assert.Equal(t, "[spec.components[0].traits: Forbidden: invalid traits: "+
+		"trait route doesn't apply to workload type worker, it applies to webservice, "+
+		"spec.components[1].traits: Forbidden: invalid traits: trait autoscale conflicts with trait scaler]", resp.Result.Message)
+
+	// updates not changing components are admitted, e.g. the OAM runtime adding its finalizer to an AppConfig which
+	// turned invalid
+	withFinalizer := invalid.DeepCopy()
+	withFinalizer.Finalizers = []string{"scope.finalizer.core.oam.dev"}
+	resp = handleUpdate(invalid, withFinalizer)
+	assert.True(t, resp.Allowed, resp.Result)
+
+	// AppConfigs being deleted are admitted, so their finalizers could be removed
+	deleting := withFinalizer.DeepCopy()
+	now := metav1.Now()
+	deleting.DeletionTimestamp = &now
+	deleting.Finalizers = nil
+	deleting.Spec.Components = append(deleting.Spec.Components, invalid.Spec.Components[0])
+	resp = handleUpdate(withFinalizer, deleting)
+	assert.True(t, resp.Allowed, resp.Result)
+
+	resp = handle(admissionv1beta1.Delete)
+	assert.True(t, resp.Allowed)
+}
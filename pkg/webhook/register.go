@@ -1,9 +1,11 @@
 package webhook
 
 import (
+	"github.com/crossplane/oam-kubernetes-runtime/pkg/oam/discoverymapper"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
+	"github.com/oam-dev/kubevela/pkg/webhook/applicationconfiguration"
 	"github.com/oam-dev/kubevela/pkg/webhook/metrics"
 	"github.com/oam-dev/kubevela/pkg/webhook/podspecworkload"
 )
@@ -12,9 +14,10 @@ import (
 // +kubebuilder:webhook:path=/mutate-standard-oam-dev-v1alpha1-metricstrait,mutating=true,failurePolicy=fail,groups=standard.oam.dev,resources=metricstraits,verbs=create;update,versions=v1alpha1,name=mmetricstrait.kb.io
 // +kubebuilder:webhook:verbs=create;update;delete,path=/validate-standard-oam-dev-v1alpha1-podspecworkload,mutating=false,failurePolicy=fail,groups=standard.oam.dev,resources=PodSpecWorkload,versions=v1alpha1,name=vpodspecworkload.kb.io
 // +kubebuilder:webhook:path=/mutate-standard-oam-dev-v1alpha1-podspecworkload,mutating=true,failurePolicy=fail,groups=standard.oam.dev,resources=PodSpecWorkload,verbs=create;update,versions=v1alpha1,name=mpodspecworkload.kb.io
+// +kubebuilder:webhook:verbs=create;update,path=/validate-core-oam-dev-v1alpha2-applicationconfiguration,mutating=false,failurePolicy=fail,groups=core.oam.dev,resources=applicationconfigurations,versions=v1alpha2,name=vapplicationconfiguration.kb.io
 
 // Register will register all the services to the webhook server
-func Register(mgr manager.Manager) {
+func Register(mgr manager.Manager) error {
 	server := mgr.GetWebhookServer()
 	// MetricsTrait
 	server.Register("/validate-standard-oam-dev-v1alpha1-metricstrait",
@@ -26,4 +29,12 @@ func Register(mgr manager.Manager) {
 		&webhook.Admission{Handler: &podspecworkload.ValidatingHandler{}})
 	server.Register("/mutate-standard-oam-dev-v1alpha1-podspecworkload",
 		&webhook.Admission{Handler: &podspecworkload.MutatingHandler{}})
+	// ApplicationConfiguration
+	dm, err := discoverymapper.New(mgr.GetConfig())
+	if err != nil {
+		return err
+	}
+	server.Register("/validate-core-oam-dev-v1alpha2-applicationconfiguration",
+		&webhook.Admission{Handler: &applicationconfiguration.ValidatingHandler{Mapper: dm}})
+	return nil
 }